@@ -4,29 +4,70 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cwj5/minted/internal/logging"
 )
 
 // Settings represents all application configuration
 type Settings struct {
-	Variables        map[string]string      `json:"variables"`
-	Tiers            []Tier                 `json:"tiers"`
-	Theme            string                 `json:"theme"`
-	Preferences      map[string]interface{} `json:"preferences"`
-	SubcategoryDepth int                    `json:"subcategoryDepth"`
+	Variables         map[string]string            `json:"variables"`
+	Tiers             []Tier                       `json:"tiers"`
+	Theme             string                       `json:"theme"`
+	Preferences       map[string]interface{}       `json:"preferences"`
+	SubcategoryDepth  int                          `json:"subcategoryDepth"`
+	TransferAccounts  []string                     `json:"transferAccounts"`
+	LiquidAccounts    []string                     `json:"liquidAccounts"`
+	Goals             []Goal                       `json:"goals"`
+	Budgets           map[string]float64           `json:"budgets"`
+	CommodityDecimals map[string]int               `json:"commodityDecimals"` // per-commodity rounding override
+	CustomThemes      map[string]map[string]string `json:"customThemes"`      // theme name -> color role -> #RRGGBB
+	TierKeywords      map[string]string            `json:"tierKeywords"`      // category keyword -> suggested tier
+	CreditLimits      map[string]float64           `json:"creditLimits"`      // liability account -> credit limit
+	ExcludeAccounts   []string                     `json:"excludeAccounts"`   // account prefixes left out of reports
+	IncludeAccounts   []string                     `json:"includeAccounts"`   // when set, only these account prefixes are reported
+	NetWorthAccounts  NetWorthAccounts             `json:"netWorthAccounts"`
+
+	// tierPatterns holds the compiled Tier.Patterns by source. It is built when settings are
+	// created, parsed or cloned and only read afterwards, so it is safe for concurrent readers.
+	tierPatterns map[string]*regexp.Regexp
+}
+
+// NetWorthAccounts selects by prefix the accounts whose balances make up net worth
+type NetWorthAccounts struct {
+	Include []string `json:"include"` // empty means assets: and liabilities:
+	Exclude []string `json:"exclude"`
 }
 
+// BuiltinThemes lists the themes shipped with the dashboard
+var BuiltinThemes = []string{"light", "dark"}
+
 // Tier represents a spending tier with assigned categories
 type Tier struct {
 	Name       string   `json:"name"`
 	Categories []string `json:"categories"`
+	Patterns   []string `json:"patterns,omitempty"` // regexes matched case-insensitively against category names
 	Color      string   `json:"color"`
+	Budget     float64  `json:"budget"` // monthly limit; 0 means no budget
+}
+
+// Goal represents a savings target tracked against an account balance
+type Goal struct {
+	Name     string  `json:"name"`
+	Account  string  `json:"account"`
+	Target   float64 `json:"target"`
+	Deadline string  `json:"deadline"`
 }
 
 // DefaultSettings returns settings with sensible defaults
 func DefaultSettings() *Settings {
-	return &Settings{
+	settings := &Settings{
 		Variables: map[string]string{
 			"HLEDGER_FILE": "$HOME/.local/share/hledger/journal.journal",
 			"PORT":         "9999",
@@ -50,18 +91,59 @@ func DefaultSettings() *Settings {
 		},
 		Theme: "light",
 		Preferences: map[string]interface{}{
-			"transactionLimit": 0,
-			"defaultDateRange": "6months",
+			"transactionLimit":      0,
+			"defaultDateRange":      "6months",
+			"minMonthsForAverage":   2,
+			"extremeMultiplier":     2.0,
+			"budgetMethod":          "mean",
+			"valuation":             "cost",
+			"weekStart":             "monday",
+			"cacheTTLMinutes":       0,
+			"staleWhileRevalidate":  false,
+			"clearedOnly":           false,
+			"currencySymbol":        "$",
+			"decimalPlaces":         2,
+			"allowWrite":            false,
+			"filterCacheSize":       32,
+			"fiscalYearStartMonth":  1,
+			"costBasis":             false,
+			"includeVirtual":        false,
+			"logLevel":              "info",
+			"alertThresholdPercent": 0,
+			"expenseSign":           "positive",
+			"incomeSign":            "negative",
+			"budgetLookbackMonths":  0,
+			"budgetMode":            "flat",
+			"anomalyZScore":         2.0,
+			"usePostingDates":       false,
 		},
 		SubcategoryDepth: 1,
+		TransferAccounts: []string{"assets:", "liabilities:"},
+		LiquidAccounts:   []string{"assets:"},
+	}
+	settings.compileTierPatterns()
+	return settings
+}
+
+// ConfigDir returns the directory holding settings.json: $MINTED_DIR when set, otherwise
+// "minted" under the user config directory ($XDG_CONFIG_HOME or ~/.config on Linux)
+func ConfigDir() (string, error) {
+	if mintedDir := os.Getenv("MINTED_DIR"); mintedDir != "" {
+		return mintedDir, nil
+	}
+
+	baseDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine config directory: %w", err)
 	}
+	return filepath.Join(baseDir, "minted"), nil
 }
 
-// LoadSettings loads settings from ${MINTED_DIR}/settings.json
+// LoadSettings loads settings from ConfigDir()/settings.json
 func LoadSettings() (*Settings, error) {
-	mintedDir := os.Getenv("MINTED_DIR")
-	if mintedDir == "" {
-		return nil, fmt.Errorf("MINTED_DIR environment variable not set")
+	mintedDir, err := ConfigDir()
+	if err != nil {
+		return nil, err
 	}
 
 	settingsPath := filepath.Join(mintedDir, "settings.json")
@@ -82,25 +164,54 @@ func LoadSettings() (*Settings, error) {
 		return nil, fmt.Errorf("failed to read settings file: %w", err)
 	}
 
-	// Parse JSON
+	return ParseSettings(data)
+}
+
+// ParseSettings decodes settings.json contents, filling in preferences added since the file
+// was written so clients see every key
+func ParseSettings(data []byte) (*Settings, error) {
 	var settings Settings
 	if err := json.Unmarshal(data, &settings); err != nil {
 		return nil, fmt.Errorf("failed to parse settings file: %w", err)
 	}
 
+	if settings.Preferences == nil {
+		settings.Preferences = make(map[string]interface{})
+	}
+	for key, value := range DefaultSettings().Preferences {
+		if _, ok := settings.Preferences[key]; !ok {
+			settings.Preferences[key] = value
+		}
+	}
+	settings.compileTierPatterns()
+
 	return &settings, nil
 }
 
-// SaveSettings saves settings to ${MINTED_DIR}/settings.json
+// ReadSettingsFile returns the raw contents of ConfigDir()/settings.json
+func ReadSettingsFile() ([]byte, error) {
+	mintedDir, err := ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(mintedDir, "settings.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings file: %w", err)
+	}
+	return data, nil
+}
+
+// SaveSettings atomically saves settings to ConfigDir()/settings.json, keeping the previous file as settings.json.bak
 func SaveSettings(settings *Settings) error {
-	mintedDir := os.Getenv("MINTED_DIR")
-	if mintedDir == "" {
-		return fmt.Errorf("MINTED_DIR environment variable not set")
+	mintedDir, err := ConfigDir()
+	if err != nil {
+		return err
 	}
 
 	// Create directory if it doesn't exist
 	if err := os.MkdirAll(mintedDir, 0755); err != nil {
-		return fmt.Errorf("failed to create MINTED_DIR: %w", err)
+		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
 	settingsPath := filepath.Join(mintedDir, "settings.json")
@@ -111,14 +222,182 @@ func SaveSettings(settings *Settings) error {
 		return fmt.Errorf("failed to marshal settings: %w", err)
 	}
 
+	// Keep one backup of the previous contents
+	if existing, err := ioutil.ReadFile(settingsPath); err == nil {
+		if err := ioutil.WriteFile(settingsPath+".bak", existing, 0644); err != nil {
+			return fmt.Errorf("failed to write settings backup: %w", err)
+		}
+	}
+
 	// Write to file
-	if err := ioutil.WriteFile(settingsPath, data, 0644); err != nil {
+	if err := writeFileAtomic(settingsPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write settings file: %w", err)
 	}
 
 	return nil
 }
 
+// writeFileAtomic writes data to a temp file in the target's directory and renames it over
+// the target, so a crash or full disk mid-write never leaves a truncated file behind
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Clone returns a deep copy of the settings, for changing them without affecting readers
+// of the original. The copy's tier patterns are compiled afresh.
+func (s *Settings) Clone() (*Settings, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy settings: %w", err)
+	}
+	var clone Settings
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, fmt.Errorf("failed to copy settings: %w", err)
+	}
+	clone.compileTierPatterns()
+	return &clone, nil
+}
+
+// GetJournalFiles returns the journal files listed in HLEDGER_FILE, which may hold several
+// paths separated by the OS path list separator (":" on Unix)
+func (s *Settings) GetJournalFiles() []string {
+	var files []string
+	for _, file := range filepath.SplitList(s.GetVariableValue("HLEDGER_FILE")) {
+		if file = strings.TrimSpace(file); file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// maxRoundingDecimals bounds the roundingDecimals preference; float64 amounts carry no more
+const maxRoundingDecimals = 10
+
+// hexColorPattern matches colors in #RRGGBB form
+var hexColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Validate checks settings for values that would break the dashboard if saved
+func (s *Settings) Validate() error {
+	if port, ok := s.Variables["PORT"]; ok {
+		n, err := strconv.Atoi(port)
+		if err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("PORT must be a number between 1 and 65535, got %q", port)
+		}
+	}
+
+	tierNames := make(map[string]bool)
+	categoryTiers := make(map[string]string)
+	for _, tier := range s.Tiers {
+		if strings.TrimSpace(tier.Name) == "" {
+			return fmt.Errorf("tier name must not be empty")
+		}
+		if tierNames[tier.Name] {
+			return fmt.Errorf("duplicate tier name %q", tier.Name)
+		}
+		tierNames[tier.Name] = true
+
+		if !hexColorPattern.MatchString(tier.Color) {
+			return fmt.Errorf("tier %q has invalid color %q, expected #RRGGBB", tier.Name, tier.Color)
+		}
+
+		// Categories match tiers case-insensitively, so compare them the same way
+		for _, category := range tier.Categories {
+			key := strings.ToLower(category)
+			if other, exists := categoryTiers[key]; exists && other != tier.Name {
+				return fmt.Errorf("category %q is assigned to both %q and %q", category, other, tier.Name)
+			}
+			categoryTiers[key] = tier.Name
+		}
+
+		for _, pattern := range tier.Patterns {
+			if _, err := compileTierPattern(pattern); err != nil {
+				return fmt.Errorf("tier %q has invalid pattern %q: %v", tier.Name, pattern, err)
+			}
+		}
+	}
+
+	if weekStart, ok := s.Preferences["weekStart"]; ok {
+		if name, _ := weekStart.(string); !strings.EqualFold(name, "monday") && !strings.EqualFold(name, "sunday") {
+			return fmt.Errorf("weekStart must be \"monday\" or \"sunday\", got %v", weekStart)
+		}
+	}
+
+	for _, key := range []string{"expenseSign", "incomeSign"} {
+		if sign, ok := s.Preferences[key]; ok {
+			if name, _ := sign.(string); !strings.EqualFold(name, "positive") && !strings.EqualFold(name, "negative") {
+				return fmt.Errorf("%s must be \"positive\" or \"negative\", got %v", key, sign)
+			}
+		}
+	}
+
+	if places, ok := s.Preferences["roundingDecimals"]; ok {
+		valid := false
+		switch n := places.(type) {
+		case float64: // decoded from JSON
+			valid = n == math.Trunc(n) && n >= 0 && n <= maxRoundingDecimals
+		case int:
+			valid = n >= 0 && n <= maxRoundingDecimals
+		}
+		if !valid {
+			return fmt.Errorf("roundingDecimals must be a whole number between 0 and %d, got %v", maxRoundingDecimals, places)
+		}
+	}
+
+	if logLevel, ok := s.Preferences["logLevel"]; ok {
+		name, isString := logLevel.(string)
+		if !isString {
+			return fmt.Errorf("logLevel must be a string, got %v", logLevel)
+		}
+		if _, err := logging.ParseLevel(name); err != nil {
+			return err
+		}
+	}
+
+	for account, limit := range s.CreditLimits {
+		if limit <= 0 {
+			return fmt.Errorf("credit limit for %q must be positive", account)
+		}
+	}
+
+	for name, colors := range s.CustomThemes {
+		if err := validateTheme(name, colors); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetVariableValue retrieves an environment variable value from settings
 func (s *Settings) GetVariableValue(key string) string {
 	if val, exists := s.Variables[key]; exists {
@@ -127,11 +406,91 @@ func (s *Settings) GetVariableValue(key string) string {
 	return ""
 }
 
-// GetTierForCategory finds which tier a category belongs to
+// GetPreferenceInt returns an integer preference, or def when it is unset or not a number
+func (s *Settings) GetPreferenceInt(key string, def int) int {
+	switch v := s.Preferences[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return def
+}
+
+// GetPreferenceFloat returns a numeric preference, or def when it is unset or not a number
+func (s *Settings) GetPreferenceFloat(key string, def float64) float64 {
+	switch v := s.Preferences[key].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return def
+}
+
+// GetPreferenceBool returns a boolean preference, or def when it is unset or not a boolean
+func (s *Settings) GetPreferenceBool(key string, def bool) bool {
+	switch v := s.Preferences[key].(type) {
+	case bool:
+		return v
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}
+
+// GetPreferenceString returns a string preference, or def when it is unset or empty
+func (s *Settings) GetPreferenceString(key, def string) string {
+	if v, ok := s.Preferences[key].(string); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// CurrencySymbol returns the currencySymbol preference used for display strings
+func (s *Settings) CurrencySymbol() string {
+	return s.GetPreferenceString("currencySymbol", "$")
+}
+
+// FormatAmount renders an amount for display using the currencySymbol and decimalPlaces
+// preferences, e.g. -$1234.50. JSON responses keep raw numbers; this is for text output.
+func (s *Settings) FormatAmount(amount float64) string {
+	places := s.GetPreferenceInt("decimalPlaces", 2)
+	if places < 0 {
+		places = 0
+	}
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return sign + s.CurrencySymbol() + strconv.FormatFloat(amount, 'f', places, 64)
+}
+
+// GetTierForCategory finds which tier a category belongs to.
+// Matching is case-insensitive since journal accounts are usually lowercase. A category listed
+// explicitly in a tier wins over any tier whose patterns match it.
 func (s *Settings) GetTierForCategory(category string) *Tier {
 	for i := range s.Tiers {
 		for _, cat := range s.Tiers[i].Categories {
-			if cat == category {
+			if strings.EqualFold(cat, category) {
+				return &s.Tiers[i]
+			}
+		}
+	}
+	for i := range s.Tiers {
+		for _, pattern := range s.Tiers[i].Patterns {
+			if re, err := s.tierPattern(pattern); err == nil && re.MatchString(category) {
 				return &s.Tiers[i]
 			}
 		}
@@ -139,6 +498,157 @@ func (s *Settings) GetTierForCategory(category string) *Tier {
 	return nil
 }
 
+// compileTierPatterns compiles every valid tier pattern into tierPatterns. Invalid ones are
+// left out; Validate reports them.
+func (s *Settings) compileTierPatterns() {
+	s.tierPatterns = make(map[string]*regexp.Regexp)
+	for _, tier := range s.Tiers {
+		for _, pattern := range tier.Patterns {
+			if re, err := compileTierPattern(pattern); err == nil {
+				s.tierPatterns[pattern] = re
+			}
+		}
+	}
+}
+
+// tierPattern returns the compiled form of a tier pattern, compiling it on the spot for
+// settings built without going through DefaultSettings, ParseSettings or Clone
+func (s *Settings) tierPattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := s.tierPatterns[pattern]; ok {
+		return re, nil
+	}
+	return compileTierPattern(pattern)
+}
+
+// compileTierPattern compiles a tier pattern case-insensitively
+func compileTierPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// IsTransferAccount reports whether an account is one of the user's own accounts
+// for transfer detection. Falls back to assets and liabilities when none are configured.
+func (s *Settings) IsTransferAccount(account string) bool {
+	prefixes := s.TransferAccounts
+	if len(prefixes) == 0 {
+		prefixes = []string{"assets:", "liabilities:"}
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(account, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLiquidAccount reports whether an account counts as cash on hand for runway
+// calculations. Falls back to all assets when no prefixes are configured.
+func (s *Settings) IsLiquidAccount(account string) bool {
+	prefixes := s.LiquidAccounts
+	if len(prefixes) == 0 {
+		prefixes = []string{"assets:"}
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(account, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNetWorthAccount reports whether an account's balance counts towards net worth: it must start
+// with one of the NetWorthAccounts include prefixes (assets and liabilities when none are
+// configured) and with none of the exclude prefixes.
+func (s *Settings) IsNetWorthAccount(account string) bool {
+	prefixes := s.NetWorthAccounts.Include
+	if len(prefixes) == 0 {
+		prefixes = []string{"assets:", "liabilities:"}
+	}
+	included := false
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(account, prefix) {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false
+	}
+	for _, prefix := range s.NetWorthAccounts.Exclude {
+		if strings.HasPrefix(account, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsReportedAccount reports whether postings to an account count towards spending, income and
+// net worth. With IncludeAccounts set the account must start with one of its prefixes, and it
+// must not start with any ExcludeAccounts prefix.
+func (s *Settings) IsReportedAccount(account string) bool {
+	if len(s.IncludeAccounts) > 0 {
+		included := false
+		for _, prefix := range s.IncludeAccounts {
+			if strings.HasPrefix(account, prefix) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	for _, prefix := range s.ExcludeAccounts {
+		if strings.HasPrefix(account, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetAvailableThemes returns the built-in theme names followed by the custom ones, sorted
+func (s *Settings) GetAvailableThemes() []string {
+	themes := append([]string{}, BuiltinThemes...)
+	custom := []string{}
+	for name := range s.CustomThemes {
+		custom = append(custom, name)
+	}
+	sort.Strings(custom)
+	return append(themes, custom...)
+}
+
+// validateTheme checks a custom theme has a usable name and only #RRGGBB colors
+func validateTheme(name string, colors map[string]string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("theme name must not be empty")
+	}
+	for _, builtin := range BuiltinThemes {
+		if strings.EqualFold(name, builtin) {
+			return fmt.Errorf("theme %q is built in and cannot be redefined", name)
+		}
+	}
+	if len(colors) == 0 {
+		return fmt.Errorf("theme %q must define at least one color", name)
+	}
+	for role, color := range colors {
+		if !hexColorPattern.MatchString(color) {
+			return fmt.Errorf("theme %q has invalid %s color %q, expected #RRGGBB", name, role, color)
+		}
+	}
+	return nil
+}
+
+// SetCustomTheme adds a custom theme or replaces the one with the same name
+func (s *Settings) SetCustomTheme(name string, colors map[string]string) error {
+	if err := validateTheme(name, colors); err != nil {
+		return err
+	}
+	if s.CustomThemes == nil {
+		s.CustomThemes = make(map[string]map[string]string)
+	}
+	s.CustomThemes[name] = colors
+	return nil
+}
+
 // AddCategory adds a category to a tier
 func (s *Settings) AddCategory(tierName, category string) error {
 	for i := range s.Tiers {
@@ -171,6 +681,46 @@ func (s *Settings) RemoveCategory(tierName, category string) error {
 	return fmt.Errorf("category not found in tier")
 }
 
+// RenameCategory renames a category in every tier that lists it, matching case-insensitively
+// and collapsing entries that differ only in case. It changes nothing and returns an error if
+// the category is in no tier or newName is already assigned to any tier. That includes tiers
+// that don't list oldName: the rename would leave newName in two tiers, which Validate rejects.
+func (s *Settings) RenameCategory(oldName, newName string) error {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return fmt.Errorf("new category name must not be empty")
+	}
+
+	found := false
+	for _, tier := range s.Tiers {
+		for _, cat := range tier.Categories {
+			if strings.EqualFold(cat, oldName) {
+				found = true
+			} else if strings.EqualFold(cat, newName) {
+				return fmt.Errorf("category %q already exists in tier %q", cat, tier.Name)
+			}
+		}
+	}
+	if !found {
+		return fmt.Errorf("category not found in any tier")
+	}
+
+	for i := range s.Tiers {
+		renamed := []string{}
+		replaced := false
+		for _, cat := range s.Tiers[i].Categories {
+			if !strings.EqualFold(cat, oldName) {
+				renamed = append(renamed, cat)
+			} else if !replaced {
+				renamed = append(renamed, newName)
+				replaced = true
+			}
+		}
+		s.Tiers[i].Categories = renamed
+	}
+	return nil
+}
+
 // CreateTier creates a new spending tier
 func (s *Settings) CreateTier(name, color string) error {
 	for _, tier := range s.Tiers {
@@ -196,3 +746,36 @@ func (s *Settings) DeleteTier(name string) error {
 	}
 	return fmt.Errorf("tier not found")
 }
+
+// CreateGoal adds a new savings goal
+func (s *Settings) CreateGoal(goal Goal) error {
+	for _, existing := range s.Goals {
+		if existing.Name == goal.Name {
+			return fmt.Errorf("goal already exists")
+		}
+	}
+	s.Goals = append(s.Goals, goal)
+	return nil
+}
+
+// UpdateGoal replaces the savings goal with the same name
+func (s *Settings) UpdateGoal(goal Goal) error {
+	for i := range s.Goals {
+		if s.Goals[i].Name == goal.Name {
+			s.Goals[i] = goal
+			return nil
+		}
+	}
+	return fmt.Errorf("goal not found")
+}
+
+// DeleteGoal deletes a savings goal
+func (s *Settings) DeleteGoal(name string) error {
+	for i, goal := range s.Goals {
+		if goal.Name == name {
+			s.Goals = append(s.Goals[:i], s.Goals[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("goal not found")
+}
@@ -0,0 +1,354 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestIsReportedAccount(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		account string
+		want    bool
+	}{
+		{"no lists", nil, nil, "expenses:food", true},
+		{"excluded prefix", nil, []string{"expenses:reimbursable:"}, "expenses:reimbursable:travel", false},
+		{"outside excluded prefix", nil, []string{"expenses:reimbursable:"}, "expenses:food", true},
+		{"included prefix", []string{"expenses:food"}, nil, "expenses:food:groceries", true},
+		{"outside included prefix", []string{"expenses:food"}, nil, "expenses:rent", false},
+		{"exclusion applies on top of inclusion", []string{"expenses:"}, []string{"expenses:reimbursable:"}, "expenses:reimbursable:travel", false},
+		{"included and not excluded", []string{"expenses:"}, []string{"expenses:reimbursable:"}, "expenses:food", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := DefaultSettings()
+			s.IncludeAccounts = tt.include
+			s.ExcludeAccounts = tt.exclude
+			if got := s.IsReportedAccount(tt.account); got != tt.want {
+				t.Errorf("IsReportedAccount(%q) = %v, want %v", tt.account, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNetWorthAccount(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		account string
+		want    bool
+	}{
+		{"asset by default", nil, nil, "assets:checking", true},
+		{"liability by default", nil, nil, "liabilities:card", true},
+		{"equity not by default", nil, nil, "equity:opening", false},
+		{"expense not by default", nil, nil, "expenses:food", false},
+		{"equity when included", []string{"assets:", "liabilities:", "equity:"}, nil, "equity:opening", true},
+		{"include replaces the defaults", []string{"equity:"}, nil, "assets:checking", false},
+		{"excluded illiquid asset", nil, []string{"assets:house"}, "assets:house", false},
+		{"exclusion leaves other assets", nil, []string{"assets:house"}, "assets:checking", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := DefaultSettings()
+			s.NetWorthAccounts = NetWorthAccounts{Include: tt.include, Exclude: tt.exclude}
+			if got := s.IsNetWorthAccount(tt.account); got != tt.want {
+				t.Errorf("IsNetWorthAccount(%q) = %v, want %v", tt.account, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRoundingDecimals(t *testing.T) {
+	tests := []struct {
+		value   any
+		wantErr bool
+	}{
+		{0, false},
+		{4, false},
+		{float64(2), false}, // as decoded from JSON
+		{-1, true},
+		{11, true},
+		{2.5, true},
+		{"2", true},
+	}
+	for _, tt := range tests {
+		s := DefaultSettings()
+		s.Preferences["roundingDecimals"] = tt.value
+		if err := s.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("roundingDecimals %v: Validate() error = %v, want error %v", tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateSignConventions(t *testing.T) {
+	tests := []struct {
+		value   any
+		wantErr bool
+	}{
+		{"positive", false},
+		{"Negative", false},
+		{"abs", true},
+		{-1, true},
+	}
+	for _, key := range []string{"expenseSign", "incomeSign"} {
+		for _, tt := range tests {
+			s := DefaultSettings()
+			s.Preferences[key] = tt.value
+			if err := s.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("%s %v: Validate() error = %v, want error %v", key, tt.value, err, tt.wantErr)
+			}
+		}
+	}
+}
+
+func TestGetTierForCategory(t *testing.T) {
+	data := []byte(`{"tiers": [
+		{"name": "Essentials", "categories": ["Groceries", "Rent"], "patterns": ["^utilities"]},
+		{"name": "Fun", "categories": ["Utilities:Streaming"], "patterns": ["^grocer", "dining"]}
+	]}`)
+	parsed, err := ParseSettings(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cloned, err := parsed.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Settings built by hand have no compiled patterns and must still match
+	literal := &Settings{Tiers: append([]Tier(nil), parsed.Tiers...)}
+
+	tests := []struct {
+		category string
+		want     string
+	}{
+		{"Groceries", "Essentials"},          // explicit beats Fun's ^grocer pattern
+		{"groceries", "Essentials"},          // explicit membership ignores case
+		{"Utilities:Streaming", "Fun"},       // explicit beats Essentials' ^utilities pattern
+		{"Utilities:Electric", "Essentials"}, // pattern match
+		{"UTILITIES:WATER", "Essentials"},    // patterns ignore case
+		{"Grocery Delivery", "Fun"},          // pattern match
+		{"Food:Dining Out", "Fun"},           // unanchored pattern
+		{"Travel", ""},                       // no tier
+		{"Home Utilities", ""},               // anchored pattern doesn't match mid-name
+	}
+	for name, s := range map[string]*Settings{"parsed": parsed, "cloned": cloned, "literal": literal} {
+		for _, tt := range tests {
+			got := ""
+			if tier := s.GetTierForCategory(tt.category); tier != nil {
+				got = tier.Name
+			}
+			if got != tt.want {
+				t.Errorf("%s: GetTierForCategory(%q) = %q, want %q", name, tt.category, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestClonedPatternsFollowTheClone(t *testing.T) {
+	s, err := ParseSettings([]byte(`{"tiers": [{"name": "Fun", "categories": [], "patterns": ["^games"]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone, err := s.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone.Tiers[0].Patterns = []string{"^movies"}
+	clone, err = clone.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.GetTierForCategory("Games") == nil || s.GetTierForCategory("Movies") != nil {
+		t.Error("changing a clone's patterns affected the original")
+	}
+	if clone.GetTierForCategory("Movies") == nil || clone.GetTierForCategory("Games") != nil {
+		t.Error("recloned settings didn't pick up the new patterns")
+	}
+}
+
+func TestRenameCategory(t *testing.T) {
+	// Hand-edited settings can list a category in more than one tier; the rename covers them all
+	tiers := func() []Tier {
+		return []Tier{
+			{Name: "Essentials", Categories: []string{"Dining", "Rent"}},
+			{Name: "Fun", Categories: []string{"dining", "DINING", "Games"}},
+			{Name: "Other", Categories: []string{"Travel"}},
+		}
+	}
+	tests := []struct {
+		name    string
+		oldName string
+		newName string
+		want    [][]string // categories per tier, or nil when the rename fails
+	}{
+		{"in two tiers", "Dining", "Restaurants", [][]string{{"Restaurants", "Rent"}, {"Restaurants", "Games"}, {"Travel"}}},
+		{"case change only", "dining", "Dining Out", [][]string{{"Dining Out", "Rent"}, {"Dining Out", "Games"}, {"Travel"}}},
+		{"trims new name", "Travel", "  Trips ", [][]string{{"Dining", "Rent"}, {"dining", "DINING", "Games"}, {"Trips"}}},
+		{"collides within a tier", "Dining", "rent", nil},
+		{"collides with another tier", "Dining", "Travel", nil},
+		{"unknown category", "Books", "Reading", nil},
+		{"empty new name", "Dining", " ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Settings{Tiers: tiers()}
+			err := s.RenameCategory(tt.oldName, tt.newName)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatal("RenameCategory succeeded, want an error")
+				}
+				if !reflect.DeepEqual(s.Tiers, tiers()) {
+					t.Errorf("failed rename changed tiers to %v", s.Tiers)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, tier := range s.Tiers {
+				if !reflect.DeepEqual(tier.Categories, tt.want[i]) {
+					t.Errorf("tier %q categories = %v, want %v", tier.Name, tier.Categories, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// tempFiles returns the leftover temp files writeFileAtomic creates in dir
+func tempFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestSaveSettingsKeepsOriginalOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MINTED_DIR", dir)
+	path := filepath.Join(dir, "settings.json")
+	original := []byte(`{"theme": "dark"}`)
+	if err := os.WriteFile(path, original, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A directory where the backup goes makes the save fail before the settings are replaced
+	if err := os.Mkdir(path+".bak", 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveSettings(DefaultSettings()); err == nil {
+		t.Fatal("SaveSettings succeeded, want an error")
+	}
+	if got, err := os.ReadFile(path); err != nil || string(got) != string(original) {
+		t.Errorf("settings.json = %q (%v) after a failed save, want the original %q", got, err, original)
+	}
+	if leftover := tempFiles(t, dir); len(leftover) != 0 {
+		t.Errorf("temp files left behind: %v", leftover)
+	}
+}
+
+func TestSaveSettingsBacksUpPreviousContents(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MINTED_DIR", dir)
+	path := filepath.Join(dir, "settings.json")
+	original := []byte(`{"theme": "dark"}`)
+	if err := os.WriteFile(path, original, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveSettings(DefaultSettings()); err != nil {
+		t.Fatal(err)
+	}
+	if backup, err := os.ReadFile(path + ".bak"); err != nil || string(backup) != string(original) {
+		t.Errorf("settings.json.bak = %q (%v), want the previous contents %q", backup, err, original)
+	}
+	saved, err := LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Theme != "light" {
+		t.Errorf("saved theme %q, want light", saved.Theme)
+	}
+	if leftover := tempFiles(t, dir); len(leftover) != 0 {
+		t.Errorf("temp files left behind: %v", leftover)
+	}
+}
+
+func TestWriteFileAtomicFailedRename(t *testing.T) {
+	dir := t.TempDir()
+	// A non-empty directory can't be replaced by a file
+	target := filepath.Join(dir, "settings.json")
+	if err := os.MkdirAll(filepath.Join(target, "keep"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(target, []byte("{}"), 0o644); err == nil {
+		t.Fatal("writeFileAtomic succeeded, want a rename error")
+	}
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		t.Errorf("target changed after a failed rename: %v", err)
+	}
+	if leftover := tempFiles(t, dir); len(leftover) != 0 {
+		t.Errorf("temp files left behind: %v", leftover)
+	}
+}
+
+// skipWithoutXDG skips tests of the fallback directory where os.UserConfigDir ignores XDG
+func skipWithoutXDG(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" || runtime.GOOS == "ios" || runtime.GOOS == "plan9" {
+		t.Skipf("os.UserConfigDir doesn't follow XDG_CONFIG_HOME on %s", runtime.GOOS)
+	}
+}
+
+func TestConfigDir(t *testing.T) {
+	home := t.TempDir()
+	tests := []struct {
+		name      string
+		mintedDir string
+		xdgConfig string
+		want      string
+	}{
+		{"MINTED_DIR set", "/srv/minted", home + "/xdg", "/srv/minted"},
+		{"XDG_CONFIG_HOME fallback", "", home + "/xdg", home + "/xdg/minted"},
+		{"home fallback", "", "", home + "/.config/minted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mintedDir == "" {
+				skipWithoutXDG(t)
+			}
+			t.Setenv("MINTED_DIR", tt.mintedDir)
+			t.Setenv("XDG_CONFIG_HOME", tt.xdgConfig)
+			t.Setenv("HOME", home)
+			got, err := ConfigDir()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ConfigDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSettingsCreatesConfigDir(t *testing.T) {
+	skipWithoutXDG(t)
+	xdg := t.TempDir()
+	t.Setenv("MINTED_DIR", "")
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	if _, err := LoadSettings(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(xdg, "minted", "settings.json")); err != nil {
+		t.Errorf("default settings not written under the user config dir: %v", err)
+	}
+}
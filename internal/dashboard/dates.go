@@ -0,0 +1,73 @@
+package dashboard
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// daysAgoPattern matches relative dates such as "7 days ago"
+var daysAgoPattern = regexp.MustCompile(`^(\d+) days? ago$`)
+
+// resolveDate converts a date filter value into a concrete YYYY-MM-DD date.
+// Besides literal dates it accepts today, yesterday, thismonth, lastmonth,
+// thisyear, lastyear and "N days ago". Period keywords resolve to the first
+// day of the period for a start date and the last day when isEnd is set.
+// Keywords are relative to the parser's current date.
+//
+// Both bounds are inclusive: endDate=thismonth covers the month's last day and
+// endDate=today covers today. getDateFilter turns the resolved end into
+// hledger's exclusive -e bound by adding a day, for literal dates as well.
+func (s *Service) resolveDate(value string, isEnd bool) (string, error) {
+	return resolveDateAt(value, isEnd, s.parser.Now())
+}
+
+// resolveDateAt resolves a date filter value relative to now
+func resolveDateAt(value string, isEnd bool, now time.Time) (string, error) {
+	keyword := strings.ToLower(strings.TrimSpace(value))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	// period returns the start or end of the span [first, next)
+	period := func(first, next time.Time) string {
+		if isEnd {
+			return next.AddDate(0, 0, -1).Format(dateLayout)
+		}
+		return first.Format(dateLayout)
+	}
+
+	thisMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+	thisYear := time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, today.Location())
+
+	switch keyword {
+	case "today":
+		return today.Format(dateLayout), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1).Format(dateLayout), nil
+	case "thismonth":
+		return period(thisMonth, thisMonth.AddDate(0, 1, 0)), nil
+	case "lastmonth":
+		return period(thisMonth.AddDate(0, -1, 0), thisMonth), nil
+	case "thisyear":
+		return period(thisYear, thisYear.AddDate(1, 0, 0)), nil
+	case "lastyear":
+		return period(thisYear.AddDate(-1, 0, 0), thisYear), nil
+	}
+
+	if m := daysAgoPattern.FindStringSubmatch(keyword); m != nil {
+		days, err := strconv.Atoi(m[1])
+		if err != nil {
+			return "", fmt.Errorf("invalid day count in %q", value)
+		}
+		return today.AddDate(0, 0, -days).Format(dateLayout), nil
+	}
+
+	if _, err := time.Parse(dateLayout, value); err != nil {
+		return "", errors.New("expected YYYY-MM-DD or a relative keyword")
+	}
+	return value, nil
+}
@@ -0,0 +1,117 @@
+package dashboard
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cwj5/minted/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestResolveDateAt(t *testing.T) {
+	now := time.Date(2026, time.October, 14, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		isEnd bool
+		want  string
+	}{
+		{"today", false, "2026-10-14"},
+		{"today", true, "2026-10-14"},
+		{"yesterday", true, "2026-10-13"},
+		{"thismonth", false, "2026-10-01"},
+		{"thismonth", true, "2026-10-31"},
+		{"lastmonth", false, "2026-09-01"},
+		{"lastmonth", true, "2026-09-30"},
+		{"thisyear", true, "2026-12-31"},
+		{"lastyear", false, "2025-01-01"},
+		{"7 days ago", false, "2026-10-07"},
+		{"1 day ago", true, "2026-10-13"},
+		{"2024-02-29", true, "2024-02-29"},
+	}
+	for _, tt := range tests {
+		got, err := resolveDateAt(tt.value, tt.isEnd, now)
+		if err != nil {
+			t.Errorf("resolveDateAt(%q, %v): %v", tt.value, tt.isEnd, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resolveDateAt(%q, %v) = %s, want %s", tt.value, tt.isEnd, got, tt.want)
+		}
+	}
+}
+
+// TestDateFilterEndIsInclusive checks that the last requested day reaches hledger, whose -e
+// bound is exclusive
+func TestDateFilterEndIsInclusive(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantEnd string // the -e value hledger receives
+	}{
+		{"startDate=thismonth&endDate=thismonth", "2026-11-01"},
+		{"startDate=2026-10-01&endDate=today", "2026-10-15"},
+		{"startDate=2026-10-01&endDate=2026-10-10", "2026-10-11"},
+		{"startDate=today&endDate=today", "2026-10-15"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			dir := fakeHledger(t, map[string]string{"print": "[]"})
+			s := newTestService(t, config.DefaultSettings())
+			s.parser.SetNow(func() time.Time { return time.Date(2026, time.October, 14, 9, 30, 0, 0, time.UTC) })
+			os.Remove(filepath.Join(dir, "args.log"))
+
+			w := serve(s.HandleTransactions, http.MethodGet, "/api/transactions?"+tt.query, nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", w.Code, w.Body.String())
+			}
+			args, err := os.ReadFile(filepath.Join(dir, "args.log"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(args), "-e "+tt.wantEnd) {
+				t.Errorf("hledger args %q, want -e %s", args, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestMalformedDateRangeIsBadRequest(t *testing.T) {
+	fakeHledger(t, map[string]string{"print": "[]", "balance": "[[],[]]"})
+	s := newTestService(t, config.DefaultSettings())
+	handlers := map[string]gin.HandlerFunc{
+		"accounts":          s.HandleAccounts,
+		"transactions":      s.HandleTransactions,
+		"summary":           s.HandleSummary,
+		"monthly-metrics":   s.HandleMonthlyMetrics,
+		"category-spending": s.HandleCategorySpending,
+	}
+	tests := []struct {
+		query     string
+		wantInMsg string
+	}{
+		{"startDate=lastweek&endDate=2024-05-31", "startDate"},
+		{"startDate=2024-05-01&endDate=2024-13-01", "endDate"},
+		{"startDate=2024-02-30&endDate=2024-05-31", "startDate"},
+		{"startDate=2024-05-01&endDate=05/31/2024", "endDate"},
+		{"startDate=2024-06-01&endDate=2024-05-31", "after endDate"},
+	}
+	for name, handler := range handlers {
+		for _, tt := range tests {
+			w := serve(handler, http.MethodGet, "/api/"+name+"?"+tt.query, nil)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s?%s: status %d, want 400", name, tt.query, w.Code)
+				continue
+			}
+			var body struct {
+				Error string `json:"error"`
+			}
+			decodeBody(t, w, &body)
+			if !strings.Contains(body.Error, tt.wantInMsg) {
+				t.Errorf("%s?%s: error %q doesn't mention %q", name, tt.query, body.Error, tt.wantInMsg)
+			}
+		}
+	}
+}
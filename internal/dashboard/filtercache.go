@@ -0,0 +1,109 @@
+package dashboard
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// filterCacheTTL bounds how long a filtered result is reused, as a backstop for journal
+// edits made while no rebuild runs
+const filterCacheTTL = 5 * time.Minute
+
+// defaultFilterCacheSize is the number of filtered results kept when the filterCacheSize
+// preference is not set
+const defaultFilterCacheSize = 32
+
+// filterKey identifies a filtered result by endpoint and date range
+type filterKey struct {
+	endpoint  string
+	startDate string
+	endDate   string
+}
+
+// filterEntry is one cached filtered result
+type filterEntry struct {
+	key    filterKey
+	value  interface{}
+	stored time.Time
+}
+
+// filterCache is a small LRU of recent date-filtered results, so flipping between the same
+// windows doesn't re-run hledger every time. It is safe for concurrent use.
+type filterCache struct {
+	mu      sync.Mutex
+	entries map[filterKey]*list.Element
+	order   *list.List // front is the most recently used
+}
+
+// newFilterCache creates an empty filter cache
+func newFilterCache() *filterCache {
+	return &filterCache{
+		entries: make(map[filterKey]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// get returns the value stored for key if it is younger than filterCacheTTL
+func (fc *filterCache) get(key filterKey) (interface{}, bool) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	element, ok := fc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*filterEntry)
+	if time.Since(entry.stored) > filterCacheTTL {
+		fc.order.Remove(element)
+		delete(fc.entries, key)
+		return nil, false
+	}
+	fc.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// put stores value under key, evicting the least recently used entries beyond size.
+// A size of 0 or less disables caching.
+func (fc *filterCache) put(key filterKey, value interface{}, size int) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if element, ok := fc.entries[key]; ok {
+		element.Value = &filterEntry{key: key, value: value, stored: time.Now()}
+		fc.order.MoveToFront(element)
+	} else {
+		fc.entries[key] = fc.order.PushFront(&filterEntry{key: key, value: value, stored: time.Now()})
+	}
+
+	for fc.order.Len() > size && fc.order.Len() > 0 {
+		oldest := fc.order.Back()
+		fc.order.Remove(oldest)
+		delete(fc.entries, oldest.Value.(*filterEntry).key)
+	}
+}
+
+// clear drops every cached result
+func (fc *filterCache) clear() {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.entries = make(map[filterKey]*list.Element)
+	fc.order.Init()
+}
+
+// cachedFiltered returns compute's result for the filter's date range, reusing a recent result
+// for the same endpoint and range. Errors are not cached.
+func cachedFiltered[T any](s *Service, endpoint string, filter *DateFilter, compute func(startDate, endDate string) (T, error)) (T, error) {
+	key := filterKey{endpoint: endpoint, startDate: filter.StartDate, endDate: filter.EndDate}
+	if value, ok := s.filtered.get(key); ok {
+		return value.(T), nil
+	}
+
+	result, err := compute(filter.StartDate, filter.EndDate)
+	if err != nil {
+		return result, err
+	}
+	s.filtered.put(key, result, s.currentSettings().GetPreferenceInt("filterCacheSize", defaultFilterCacheSize))
+	return result, nil
+}
@@ -0,0 +1,108 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cwj5/minted/internal/config"
+	"github.com/cwj5/minted/internal/hledger"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeHledgerScript prints <dir>/<arg>.out for the first argument that has such a file, or
+// fails with <dir>/<arg>.fail as stderr, and logs each invocation's arguments to args.log
+const fakeHledgerScript = `#!/bin/sh
+dir=$(dirname "$0")
+echo "$*" >> "$dir/args.log"
+for arg in "$@"; do
+	if [ -f "$dir/$arg.fail" ]; then
+		cat "$dir/$arg.fail" >&2
+		exit 1
+	fi
+	if [ -f "$dir/$arg.out" ]; then
+		cat "$dir/$arg.out"
+		exit 0
+	fi
+done
+`
+
+// fakeHledger puts an hledger stub first on PATH for the rest of the test and returns its
+// directory. outputs maps a subcommand such as print or balance to what the stub prints.
+func fakeHledger(t *testing.T, outputs map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hledger"), []byte(fakeHledgerScript), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for command, output := range outputs {
+		if err := os.WriteFile(filepath.Join(dir, command+".out"), []byte(output), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+// printJSON encodes transactions the way hledger print -O json does, numbering them in order
+func printJSON(t *testing.T, transactions ...hledger.Transaction) string {
+	t.Helper()
+	for i := range transactions {
+		transactions[i].Index = i + 1
+	}
+	data, err := json.Marshal(transactions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// usd builds a dollar amount with two decimal places
+func usd(amount float64) []hledger.Amount {
+	mantissa := int64(math.Round(amount * 100))
+	return []hledger.Amount{{Commodity: "$", Quantity: hledger.Quantity{DecimalMantissa: mantissa, DecimalPlaces: 2}}}
+}
+
+// posting builds a regular dollar posting
+func posting(account string, amount float64) hledger.Posting {
+	return hledger.Posting{Account: account, Amount: usd(amount), Type: hledger.PostingRegular}
+}
+
+// txn builds a cleared transaction
+func txn(date, description string, postings ...hledger.Posting) hledger.Transaction {
+	return hledger.Transaction{Date: date, Description: description, Status: "Cleared", Postings: postings}
+}
+
+// newTestService creates a service over a stub journal, with settings saved under a temporary
+// MINTED_DIR. Call fakeHledger first to give the stub something to report.
+func newTestService(t *testing.T, settings *config.Settings) *Service {
+	t.Helper()
+	t.Setenv("MINTED_DIR", t.TempDir())
+	gin.SetMode(gin.TestMode)
+	return NewService("test.journal", settings)
+}
+
+// serve runs a handler against a request and returns the recorded response
+func serve(handler gin.HandlerFunc, method, target string, body io.Reader) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, body)
+	if body != nil {
+		c.Request.Header.Set("Content-Type", "application/json")
+	}
+	handler(c)
+	return w
+}
+
+// decodeBody unmarshals a JSON response body into v
+func decodeBody(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.NewDecoder(strings.NewReader(w.Body.String())).Decode(v); err != nil {
+		t.Fatalf("decoding %q: %v", w.Body.String(), err)
+	}
+}
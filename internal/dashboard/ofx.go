@@ -0,0 +1,235 @@
+package dashboard
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cwj5/minted/internal/hledger"
+)
+
+// ofxHeader is the OFX 1.x SGML header that precedes the document body
+const ofxHeader = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+SECURITY:NONE
+ENCODING:UTF-8
+CHARSET:NONE
+COMPRESSION:NONE
+OLDFILEUID:NONE
+NEWFILEUID:NONE
+
+`
+
+// OFX field limits from the 1.0.2 specification
+const (
+	ofxNameLength = 32
+	ofxMemoLength = 255
+)
+
+// ofxCurrencies maps common commodity symbols to ISO 4217 codes
+var ofxCurrencies = map[string]string{
+	"$": "USD",
+	"€": "EUR",
+	"£": "GBP",
+	"¥": "JPY",
+}
+
+// ofxStatement is one account's postings in an OFX export
+type ofxStatement struct {
+	account  string
+	currency string
+	entries  []ofxEntry
+}
+
+// ofxEntry is a single STMTTRN record
+type ofxEntry struct {
+	fitID  string
+	date   string // YYYYMMDD
+	amount string // signed, as a plain decimal
+	credit bool
+	name   string
+	memo   string
+}
+
+// formatOFX renders transactions as an OFX 1.x SGML document with one statement per asset and
+// liability account. Postings to other accounts appear only as the counterparts of those
+// statements. startDate and endDate bound each statement's transaction list; when empty, the
+// statement's own first and last dates are used.
+func formatOFX(transactions []hledger.Transaction, startDate, endDate string, generated time.Time) string {
+	statements := ofxStatements(transactions)
+
+	var b strings.Builder
+	b.WriteString(ofxHeader)
+	b.WriteString("<OFX>\n")
+	b.WriteString("<SIGNONMSGSRSV1>\n<SONRS>\n")
+	b.WriteString("<STATUS>\n<CODE>0\n<SEVERITY>INFO\n</STATUS>\n")
+	fmt.Fprintf(&b, "<DTSERVER>%s\n", generated.Format("20060102150405"))
+	b.WriteString("<LANGUAGE>ENG\n</SONRS>\n</SIGNONMSGSRSV1>\n")
+	b.WriteString("<BANKMSGSRSV1>\n")
+
+	for i, statement := range statements {
+		start, end := ofxDate(startDate), ofxDate(endDate)
+		if start == "" {
+			start = statement.entries[0].date
+		}
+		if end == "" {
+			end = statement.entries[len(statement.entries)-1].date
+		}
+
+		b.WriteString("<STMTTRNRS>\n")
+		fmt.Fprintf(&b, "<TRNUID>%d\n", i+1)
+		b.WriteString("<STATUS>\n<CODE>0\n<SEVERITY>INFO\n</STATUS>\n")
+		b.WriteString("<STMTRS>\n")
+		fmt.Fprintf(&b, "<CURDEF>%s\n", statement.currency)
+		b.WriteString("<BANKACCTFROM>\n<BANKID>MINTED\n")
+		fmt.Fprintf(&b, "<ACCTID>%s\n", ofxEscape(statement.account))
+		fmt.Fprintf(&b, "<ACCTTYPE>%s\n", ofxAccountType(statement.account))
+		b.WriteString("</BANKACCTFROM>\n")
+		b.WriteString("<BANKTRANLIST>\n")
+		fmt.Fprintf(&b, "<DTSTART>%s\n<DTEND>%s\n", start, end)
+		for _, entry := range statement.entries {
+			trnType := "DEBIT"
+			if entry.credit {
+				trnType = "CREDIT"
+			}
+			b.WriteString("<STMTTRN>\n")
+			fmt.Fprintf(&b, "<TRNTYPE>%s\n", trnType)
+			fmt.Fprintf(&b, "<DTPOSTED>%s\n", entry.date)
+			fmt.Fprintf(&b, "<TRNAMT>%s\n", entry.amount)
+			fmt.Fprintf(&b, "<FITID>%s\n", entry.fitID)
+			fmt.Fprintf(&b, "<NAME>%s\n", ofxEscape(truncateRunes(entry.name, ofxNameLength)))
+			if entry.memo != "" {
+				fmt.Fprintf(&b, "<MEMO>%s\n", ofxEscape(truncateRunes(entry.memo, ofxMemoLength)))
+			}
+			b.WriteString("</STMTTRN>\n")
+		}
+		b.WriteString("</BANKTRANLIST>\n")
+		b.WriteString("</STMTRS>\n</STMTTRNRS>\n")
+	}
+
+	b.WriteString("</BANKMSGSRSV1>\n</OFX>\n")
+	return b.String()
+}
+
+// ofxStatements groups the asset and liability postings by account, sorted by account name
+// with each account's postings in date order. Postings without an amount are skipped. A
+// statement's currency is that of its first posting.
+func ofxStatements(transactions []hledger.Transaction) []ofxStatement {
+	byAccount := make(map[string]*ofxStatement)
+	for _, tx := range transactions {
+		for i, posting := range tx.Postings {
+			if len(posting.Amount) == 0 {
+				continue
+			}
+			if !isStatementAccount(posting.Account) {
+				continue
+			}
+
+			statement, ok := byAccount[posting.Account]
+			if !ok {
+				statement = &ofxStatement{
+					account:  posting.Account,
+					currency: ofxCurrency(posting.Amount[0].Commodity),
+				}
+				byAccount[posting.Account] = statement
+			}
+
+			memo := posting.Comment
+			if strings.TrimSpace(memo) == "" {
+				memo = tx.Comment
+			}
+			quantity := posting.Amount[0].Quantity
+			statement.entries = append(statement.entries, ofxEntry{
+				fitID:  fmt.Sprintf("%d-%d", tx.Index, i+1),
+				date:   ofxDate(tx.Date),
+				amount: formatQuantity(quantity),
+				credit: quantity.DecimalMantissa > 0,
+				name:   strings.Join(strings.Fields(tx.Description), " "),
+				memo:   strings.Join(strings.Fields(memo), " "),
+			})
+		}
+	}
+
+	statements := make([]ofxStatement, 0, len(byAccount))
+	for _, statement := range byAccount {
+		sort.SliceStable(statement.entries, func(i, j int) bool {
+			return statement.entries[i].date < statement.entries[j].date
+		})
+		statements = append(statements, *statement)
+	}
+	sort.Slice(statements, func(i, j int) bool {
+		return statements[i].account < statements[j].account
+	})
+	return statements
+}
+
+// isStatementAccount reports whether an account is one of the user's own asset or liability
+// accounts, which get a statement in OFX and QIF exports
+func isStatementAccount(account string) bool {
+	return strings.HasPrefix(account, "assets:") || strings.HasPrefix(account, "liabilities:")
+}
+
+// ofxDate converts a YYYY-MM-DD date to OFX's YYYYMMDD form
+func ofxDate(date string) string {
+	return strings.ReplaceAll(date, "-", "")
+}
+
+// ofxCurrency returns the ISO 4217 code for a commodity, falling back to USD for commodities
+// that are neither a known symbol nor already a three-letter code
+func ofxCurrency(commodity string) string {
+	if code, ok := ofxCurrencies[commodity]; ok {
+		return code
+	}
+	if len(commodity) == 3 && strings.ToUpper(commodity) == commodity && strings.Trim(commodity, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == "" {
+		return commodity
+	}
+	return "USD"
+}
+
+// ofxAccountType picks the OFX account type for a hledger account name
+func ofxAccountType(account string) string {
+	if strings.HasPrefix(account, "liabilities:") {
+		return "CREDITLINE"
+	}
+	if strings.Contains(strings.ToLower(account), "saving") {
+		return "SAVINGS"
+	}
+	return "CHECKING"
+}
+
+// formatQuantity writes an hledger quantity as a signed decimal without going through a float,
+// so amounts keep their exact journal digits
+func formatQuantity(quantity hledger.Quantity) string {
+	mantissa := quantity.DecimalMantissa
+	sign := ""
+	if mantissa < 0 {
+		sign = "-"
+		mantissa = -mantissa
+	}
+	digits := strconv.FormatInt(mantissa, 10)
+	if quantity.DecimalPlaces <= 0 {
+		return sign + digits
+	}
+	if len(digits) <= quantity.DecimalPlaces {
+		digits = strings.Repeat("0", quantity.DecimalPlaces-len(digits)+1) + digits
+	}
+	split := len(digits) - quantity.DecimalPlaces
+	return sign + digits[:split] + "." + digits[split:]
+}
+
+// ofxEscape replaces the characters SGML treats as markup
+func ofxEscape(value string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(value)
+}
+
+// truncateRunes shortens value to at most n characters without splitting a multi-byte one
+func truncateRunes(value string, n int) string {
+	runes := []rune(value)
+	if len(runes) <= n {
+		return value
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,99 @@
+package dashboard
+
+import (
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cwj5/minted/internal/config"
+	"github.com/cwj5/minted/internal/hledger"
+)
+
+// update rewrites the golden files in testdata from the current output
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// exportTransactions is the fixture the OFX and QIF golden files are generated from: a
+// paycheck, a split purchase, a card payment between own accounts and a posting without an amount
+func exportTransactions() []hledger.Transaction {
+	transactions := []hledger.Transaction{
+		txn("2024-01-15", "Grocery & Co <Main St>",
+			posting("expenses:food:groceries", 42.5),
+			posting("assets:bank:checking", -42.5)),
+		txn("2024-01-01", "Paycheck",
+			posting("assets:bank:checking", 2500),
+			posting("income:salary", -2500)),
+		txn("2024-01-20", "Card payment",
+			posting("liabilities:credit card", 300),
+			posting("assets:bank:checking", -300)),
+		txn("2024-01-25", "Hardware   store",
+			posting("expenses:home", 80),
+			posting("expenses:garden", 19.99),
+			posting("liabilities:credit card", -99.99),
+			hledger.Posting{Account: "expenses:unknown"}),
+	}
+	transactions[0].Comment = "weekly shop"
+	for i := range transactions {
+		transactions[i].Index = i + 1
+	}
+	return transactions
+}
+
+// checkGolden compares got against testdata/name, rewriting the file instead under -update
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s does not match the golden file (rerun with -update to regenerate)\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func TestFormatOFXGolden(t *testing.T) {
+	generated := time.Date(2024, time.February, 1, 9, 30, 0, 0, time.UTC)
+	checkGolden(t, "transactions.ofx", formatOFX(exportTransactions(), "", "", generated))
+}
+
+func TestFormatOFXDateRange(t *testing.T) {
+	got := formatOFX(exportTransactions(), "2023-12-01", "2024-03-01", time.Time{})
+	if n := strings.Count(got, "<DTSTART>20231201\n<DTEND>20240301\n"); n != 2 {
+		t.Errorf("statements bounded by the requested range = %d, want 2:\n%s", n, got)
+	}
+}
+
+func TestHandleTransactionsOFX(t *testing.T) {
+	fakeHledger(t, map[string]string{"print": printJSON(t, exportTransactions()...)})
+	s := newTestService(t, config.DefaultSettings())
+
+	w := serve(s.HandleTransactionsOFX, http.MethodGet, "/api/transactions.ofx", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/x-ofx" {
+		t.Errorf("Content-Type = %q, want application/x-ofx", got)
+	}
+	want := `attachment; filename="minted-transactions-` + s.parser.Now().Format("2006-01-02") + `.ofx"`
+	if got := w.Header().Get("Content-Disposition"); got != want {
+		t.Errorf("Content-Disposition = %q, want %q", got, want)
+	}
+
+	// The end date names the last day to include, which is what DTEND reports
+	w = serve(s.HandleTransactionsOFX, http.MethodGet, "/api/transactions.ofx?startDate=2024-01-01&endDate=2024-02-29", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	if body := w.Body.String(); !strings.Contains(body, "<DTSTART>20240101\n<DTEND>20240229\n") {
+		t.Errorf("statement range for 2024-01-01 to 2024-02-29 is wrong:\n%s", body)
+	}
+}
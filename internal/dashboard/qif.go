@@ -0,0 +1,124 @@
+package dashboard
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cwj5/minted/internal/hledger"
+)
+
+// qifAccountTypes are the QIF account types accepted for the type header
+var qifAccountTypes = []string{"Bank", "Cash", "CCard", "Oth A", "Oth L"}
+
+// qifAccountType returns the canonical spelling of a QIF account type, ignoring case
+func qifAccountType(name string) (string, bool) {
+	for _, accountType := range qifAccountTypes {
+		if strings.EqualFold(name, accountType) {
+			return accountType, true
+		}
+	}
+	return "", false
+}
+
+// qifRecord is one transaction as seen from a single account
+type qifRecord struct {
+	date   string            // YYYY-MM-DD, converted when written
+	splits []hledger.Posting // the other postings of the transaction
+	amount hledger.Quantity
+	payee  string
+	memo   string
+}
+
+// formatQIF renders transactions as QIF with an !Account block and a !Type section of the given
+// account type for each asset and liability account, sorted by name. A posting's counter-account
+// becomes the L category, in brackets when it is another own account so importers record a
+// transfer. Transactions with several counter-postings are written as S/$ splits instead.
+func formatQIF(transactions []hledger.Transaction, accountType string) string {
+	byAccount := make(map[string][]qifRecord)
+	for _, tx := range transactions {
+		for i, posting := range tx.Postings {
+			if len(posting.Amount) == 0 || !isStatementAccount(posting.Account) {
+				continue
+			}
+
+			var splits []hledger.Posting
+			for j, other := range tx.Postings {
+				if j != i && len(other.Amount) > 0 {
+					splits = append(splits, other)
+				}
+			}
+			memo := posting.Comment
+			if strings.TrimSpace(memo) == "" {
+				memo = tx.Comment
+			}
+			byAccount[posting.Account] = append(byAccount[posting.Account], qifRecord{
+				date:   tx.Date,
+				splits: splits,
+				amount: posting.Amount[0].Quantity,
+				payee:  strings.Join(strings.Fields(tx.Description), " "),
+				memo:   strings.Join(strings.Fields(memo), " "),
+			})
+		}
+	}
+
+	accounts := make([]string, 0, len(byAccount))
+	for account := range byAccount {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+
+	var b strings.Builder
+	for _, account := range accounts {
+		b.WriteString("!Account\n")
+		b.WriteString("N" + account + "\n")
+		b.WriteString("T" + accountType + "\n")
+		b.WriteString("^\n")
+		b.WriteString("!Type:" + accountType + "\n")
+
+		records := byAccount[account]
+		sort.SliceStable(records, func(i, j int) bool {
+			return records[i].date < records[j].date
+		})
+		for _, record := range records {
+			b.WriteString("D" + qifDate(record.date) + "\n")
+			b.WriteString("T" + formatQuantity(record.amount) + "\n")
+			b.WriteString("P" + record.payee + "\n")
+			if record.memo != "" {
+				b.WriteString("M" + record.memo + "\n")
+			}
+			if len(record.splits) == 1 {
+				b.WriteString("L" + qifCategory(record.splits[0].Account) + "\n")
+			} else {
+				for _, split := range record.splits {
+					// Split amounts are from this account's side, so they sum to T
+					quantity := split.Amount[0].Quantity
+					quantity.DecimalMantissa = -quantity.DecimalMantissa
+					b.WriteString("S" + qifCategory(split.Account) + "\n")
+					b.WriteString("$" + formatQuantity(quantity) + "\n")
+				}
+			}
+			b.WriteString("^\n")
+		}
+	}
+	return b.String()
+}
+
+// qifCategory returns the category field for a counter-account, bracketing own accounts to
+// mark the posting as a transfer
+func qifCategory(account string) string {
+	if isStatementAccount(account) {
+		return "[" + account + "]"
+	}
+	return account
+}
+
+// qifDate converts a YYYY-MM-DD date to the MM/DD/YYYY form QIF importers expect, with a
+// four-digit year so there is no century guessing
+func qifDate(date string) string {
+	parsed, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return date
+	}
+	return parsed.Format("01/02/2006")
+}
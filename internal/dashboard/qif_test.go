@@ -0,0 +1,67 @@
+package dashboard
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cwj5/minted/internal/config"
+)
+
+func TestFormatQIFGolden(t *testing.T) {
+	checkGolden(t, "transactions.qif", formatQIF(exportTransactions(), "Bank"))
+}
+
+func TestQIFDate(t *testing.T) {
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"2024-01-05", "01/05/2024"},
+		{"1999-12-31", "12/31/1999"},
+		{"not a date", "not a date"},
+	}
+	for _, tt := range tests {
+		if got := qifDate(tt.date); got != tt.want {
+			t.Errorf("qifDate(%q) = %q, want %q", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestHandleTransactionsQIFType(t *testing.T) {
+	fakeHledger(t, map[string]string{"print": printJSON(t, exportTransactions()...)})
+	s := newTestService(t, config.DefaultSettings())
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantType   string
+	}{
+		{"", http.StatusOK, "Bank"},
+		{"?type=ccard", http.StatusOK, "CCard"},
+		{"?type=oth%20a", http.StatusOK, "Oth A"},
+		{"?type=invest", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := serve(s.HandleTransactionsQIF, http.MethodGet, "/api/transactions.qif"+tt.query, nil)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			body := w.Body.String()
+			if n := strings.Count(body, "!Type:"+tt.wantType+"\n"); n != 2 {
+				t.Errorf("!Type:%s headers = %d, want one per account:\n%s", tt.wantType, n, body)
+			}
+			if !strings.HasSuffix(body, "^\n") {
+				t.Errorf("body does not end with a ^ terminator:\n%s", body)
+			}
+			want := `attachment; filename="minted-transactions-` + s.parser.Now().Format("2006-01-02") + `.qif"`
+			if got := w.Header().Get("Content-Disposition"); got != want {
+				t.Errorf("Content-Disposition = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -2,29 +2,53 @@ package dashboard
 
 import (
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cwj5/minted/internal/config"
 	"github.com/cwj5/minted/internal/hledger"
+	"github.com/cwj5/minted/internal/logging"
 	"github.com/gin-gonic/gin"
 )
 
-// DateFilter holds start and end dates for filtering
+// DateFilter holds start and end dates for filtering. StartDate is the first day included;
+// EndDate is hledger's exclusive -e bound, the day after the last day the client asked for.
 type DateFilter struct {
 	StartDate string
 	EndDate   string
 }
 
+// LastDay returns the last day the filter includes, the day before the exclusive EndDate
+func (f *DateFilter) LastDay() string {
+	end, err := time.Parse(dateLayout, f.EndDate)
+	if err != nil {
+		return f.EndDate
+	}
+	return end.AddDate(0, 0, -1).Format(dateLayout)
+}
+
 // Service handles dashboard operations
 type Service struct {
-	parser          *hledger.Parser
-	settings        *config.Settings
+	parser   *hledger.Parser
+	settings *config.Settings
+	// settingsMu guards settings, which is replaced as a whole rather than modified in place
+	settingsMu      sync.RWMutex
 	cacheMu         sync.RWMutex
 	cache           *CachedData
 	cacheRefreshing bool
+	filtered        *filterCache
+	// journalFromSettings is set when no journal was passed in, so the parser follows the
+	// HLEDGER_FILE variable whenever settings are replaced
+	journalFromSettings bool
+	// logger may be swapped with SetLogger while requests are served
+	logger atomic.Pointer[slog.Logger]
 }
 
 // SummaryData represents the summary response payload
@@ -48,93 +72,249 @@ type CachedData struct {
 	Summary          SummaryData
 	LastRefresh      time.Time
 	Stale            bool
+	Warnings         []string // one "section: error" entry per section that failed to compute
+
+	// failed maps each section that failed in the rebuild to its error; its data is left empty
+	failed map[string]error
+}
+
+// Sections of CachedData, computed independently so one failure doesn't blank the dashboard.
+// The summary is derived from the accounts and shares their section.
+const (
+	sectionAccounts         = "accounts"
+	sectionTransactions     = "transactions"
+	sectionBudget           = "budget"
+	sectionBudgetHistory    = "budgetHistory"
+	sectionMonthlyMetrics   = "monthlyMetrics"
+	sectionCategorySpending = "categorySpending"
+	sectionNetWorth         = "netWorthOverTime"
+	sectionCategoryTrends   = "categoryTrends"
+	sectionYearOverYear     = "yearOverYear"
+)
+
+// cacheSections lists every section in the order RebuildCache computes them
+var cacheSections = []string{
+	sectionAccounts, sectionTransactions, sectionBudget, sectionBudgetHistory, sectionMonthlyMetrics,
+	sectionCategorySpending, sectionNetWorth, sectionCategoryTrends, sectionYearOverYear,
+}
+
+// computeSection runs one cache computation, recording its error under name instead of
+// aborting the rebuild
+func computeSection[T any](failed map[string]error, name string, compute func() (T, error)) T {
+	result, err := compute()
+	if err != nil {
+		failed[name] = err
+	}
+	return result
 }
 
-// NewService creates a new dashboard service
+// NewService creates a new dashboard service for a single journal file
 func NewService(journalFile string, settings *config.Settings) *Service {
+	return NewServiceWithFiles([]string{journalFile}, settings)
+}
+
+// NewServiceWithFiles creates a new dashboard service reading several journal files as one
+// dataset. With no journal files (or only empty paths), they are taken from the HLEDGER_FILE
+// settings variable instead.
+func NewServiceWithFiles(journalFiles []string, settings *config.Settings) *Service {
+	fromSettings := !hasJournalFile(journalFiles)
+	if fromSettings {
+		journalFiles = settings.GetJournalFiles()
+	}
+
 	s := &Service{
-		parser:   hledger.NewParser(journalFile, settings),
-		settings: settings,
+		parser:              hledger.NewParserWithFiles(journalFiles, settings),
+		settings:            settings,
+		filtered:            newFilterCache(),
+		journalFromSettings: fromSettings,
 	}
+	s.logger.Store(logging.Default())
+	applyLogLevel(settings)
 
 	// Warm the cache at startup (best effort)
 	if err := s.RebuildCache(); err != nil {
 		// Keep running; handlers will return a refresh-needed message until cache succeeds
 	}
 
+	go s.refreshLoop()
+
 	return s
 }
 
-// RebuildCache refreshes all dashboard data in a single pass.
-func (s *Service) RebuildCache() error {
-	s.cacheMu.Lock()
-	if s.cacheRefreshing {
-		s.cacheMu.Unlock()
-		return errors.New("refresh already in progress")
+// hasJournalFile reports whether any of the given journal paths is non-empty
+func hasJournalFile(journalFiles []string) bool {
+	for _, file := range journalFiles {
+		if strings.TrimSpace(file) != "" {
+			return true
+		}
 	}
-	s.cacheRefreshing = true
-	s.cacheMu.Unlock()
+	return false
+}
 
-	defer func() {
-		s.cacheMu.Lock()
-		s.cacheRefreshing = false
-		s.cacheMu.Unlock()
-	}()
+// SetLogger replaces the logger used by the service and its parser, e.g. to capture output in tests
+func (s *Service) SetLogger(logger *slog.Logger) {
+	s.logger.Store(logger)
+	s.parser.SetLogger(logger)
+}
 
-	accounts, err := s.parser.GetAccounts()
-	if err != nil {
-		return err
-	}
+// log returns the logger in effect
+func (s *Service) log() *slog.Logger {
+	return s.logger.Load()
+}
 
-	summary := SummaryData{}
-	for _, account := range accounts {
-		if len(account.Name) >= 7 && account.Name[:7] == "assets:" {
-			summary.TotalAssets += account.Balance
-		} else if len(account.Name) >= 12 && account.Name[:12] == "liabilities:" {
-			// Liabilities in hledger are negative; convert to positive
-			summary.TotalLiabilities += -account.Balance
-		}
-	}
-	summary.NetWorth = summary.TotalAssets - summary.TotalLiabilities
+// applyLogLevel sets the level of the shared logger from the logLevel preference. Settings are
+// validated before they are installed, so an unknown level only comes from a hand-edited file
+// loaded at startup and is treated as info.
+func applyLogLevel(settings *config.Settings) {
+	level, _ := logging.ParseLevel(settings.GetPreferenceString("logLevel", "info"))
+	logging.SetLevel(level)
+}
 
-	transactions, err := s.parser.GetTransactions()
-	if err != nil {
-		return err
+// currentSettings returns the settings in effect, safe against concurrent updates
+func (s *Service) currentSettings() *config.Settings {
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
+	return s.settings
+}
+
+// replaceSettings installs new settings in the service and its parser. Both are swapped under
+// the lock so concurrent updates can't leave them holding different settings.
+func (s *Service) replaceSettings(settings *config.Settings) {
+	s.settingsMu.Lock()
+	defer s.settingsMu.Unlock()
+	s.installSettingsLocked(settings)
+}
+
+// installSettingsLocked swaps settings into the service and its parser, re-resolving the journal
+// files when they come from HLEDGER_FILE. The caller must hold settingsMu.
+func (s *Service) installSettingsLocked(settings *config.Settings) {
+	s.settings = settings
+	s.parser.UpdateSettings(settings)
+	if s.journalFromSettings {
+		s.parser.SetJournalFiles(settings.GetJournalFiles())
 	}
+	s.filtered.clear()
+	applyLogLevel(settings)
+}
+
+// errSettingsNotSaved marks a modifySettings failure that happened while writing to disk
+var errSettingsNotSaved = errors.New("failed to save settings")
 
-	budgetItems, err := s.parser.GetBudgetData()
+// modifySettings applies change to a copy of the current settings, saves the copy and swaps it
+// in. The lock is held throughout so concurrent modifications can't overwrite each other.
+func (s *Service) modifySettings(change func(*config.Settings) error) error {
+	s.settingsMu.Lock()
+	defer s.settingsMu.Unlock()
+
+	updated, err := s.settings.Clone()
 	if err != nil {
 		return err
 	}
-
-	budgetHistory, err := s.parser.GetBudgetHistory()
-	if err != nil {
+	if err := change(updated); err != nil {
 		return err
 	}
+	if err := config.SaveSettings(updated); err != nil {
+		return fmt.Errorf("%w: %v", errSettingsNotSaved, err)
+	}
 
-	monthlyMetrics, err := s.parser.GetMonthlyMetrics()
-	if err != nil {
-		return err
+	s.installSettingsLocked(updated)
+	return nil
+}
+
+// markCacheStale flags the cached data as outdated and drops filtered results, after a change
+// that the next rebuild should pick up
+func (s *Service) markCacheStale() {
+	s.filtered.clear()
+
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	if s.cache != nil {
+		s.cache.Stale = true
 	}
+}
 
-	categorySpending, err := s.parser.GetCategorySpending()
-	if err != nil {
-		return err
+// cacheTTL returns the configured background refresh interval; 0 disables it
+func (s *Service) cacheTTL() time.Duration {
+	return time.Duration(s.currentSettings().GetPreferenceInt("cacheTTLMinutes", 0)) * time.Minute
+}
+
+// refreshLoop rebuilds the cache in the background once it is older than the TTL.
+// The TTL is re-read every cycle so settings changes apply without a restart.
+func (s *Service) refreshLoop() {
+	for {
+		ttl := s.cacheTTL()
+		if ttl <= 0 {
+			time.Sleep(time.Minute)
+			continue
+		}
+
+		s.cacheMu.Lock()
+		wait := time.Duration(0)
+		if s.cache != nil {
+			wait = ttl - time.Since(s.cache.LastRefresh)
+			if wait <= 0 {
+				// Flag the data as stale right away, even though the rebuild takes a while
+				s.cache.Stale = true
+			}
+		}
+		s.cacheMu.Unlock()
+
+		if wait > 0 {
+			// Wake up at least once a minute to pick up TTL changes
+			if wait > time.Minute {
+				wait = time.Minute
+			}
+			time.Sleep(wait)
+			continue
+		}
+
+		if err := s.RebuildCache(); err != nil {
+			// Covers a refresh already in progress as well as hledger failures
+			s.log().Warn("Background cache refresh skipped", "error", err)
+			time.Sleep(time.Minute)
+		}
 	}
+}
 
-	netWorth, err := s.parser.GetNetWorthOverTime()
-	if err != nil {
-		return err
+// RebuildCache refreshes all dashboard data in a single pass.
+func (s *Service) RebuildCache() error {
+	s.cacheMu.Lock()
+	if s.cacheRefreshing {
+		s.cacheMu.Unlock()
+		return errors.New("refresh already in progress")
 	}
+	s.cacheRefreshing = true
+	s.cacheMu.Unlock()
 
-	categoryTrends, err := s.parser.GetCategoryTrends()
-	if err != nil {
-		return err
+	defer func() {
+		s.cacheMu.Lock()
+		s.cacheRefreshing = false
+		s.cacheMu.Unlock()
+	}()
+
+	failed := make(map[string]error)
+	accounts := computeSection(failed, sectionAccounts, s.parser.GetAccounts)
+	summary := summarizeAccounts(accounts, s.currentSettings())
+	transactions := computeSection(failed, sectionTransactions, s.parser.GetTransactions)
+	budgetItems := computeSection(failed, sectionBudget, s.parser.GetBudgetData)
+	budgetHistory := computeSection(failed, sectionBudgetHistory, s.parser.GetBudgetHistory)
+	monthlyMetrics := computeSection(failed, sectionMonthlyMetrics, s.parser.GetMonthlyMetrics)
+	categorySpending := computeSection(failed, sectionCategorySpending, s.parser.GetCategorySpending)
+	netWorth := computeSection(failed, sectionNetWorth, s.parser.GetNetWorthOverTime)
+	categoryTrends := computeSection(failed, sectionCategoryTrends, s.parser.GetCategoryTrends)
+	yearOverYear := computeSection(failed, sectionYearOverYear, s.parser.GetYearOverYearComparison)
+
+	// With nothing computed (e.g. hledger missing) keep the previous cache and report the first error
+	if len(failed) == len(cacheSections) {
+		return failed[cacheSections[0]]
 	}
 
-	yearOverYear, err := s.parser.GetYearOverYearComparison()
-	if err != nil {
-		return err
+	warnings := []string{}
+	for _, section := range cacheSections {
+		if err, ok := failed[section]; ok {
+			warnings = append(warnings, fmt.Sprintf("%s: %v", section, err))
+			s.log().Warn("Cache section failed to compute", "section", section, "error", err)
+		}
 	}
 
 	newCache := &CachedData{
@@ -150,44 +330,173 @@ func (s *Service) RebuildCache() error {
 		Summary:          summary,
 		LastRefresh:      time.Now(),
 		Stale:            false,
+		Warnings:         warnings,
+		failed:           failed,
 	}
 
 	s.cacheMu.Lock()
 	s.cache = newCache
 	s.cacheMu.Unlock()
 
+	// Filtered results may predate the journal contents just read
+	s.filtered.clear()
+
 	return nil
 }
 
-// getCache safely returns the cached data
-func (s *Service) getCache() (*CachedData, bool) {
+// summarizeAccounts totals asset and liability balances into a summary, leaving out accounts
+// excluded from reports or from net worth
+func summarizeAccounts(accounts []hledger.Account, settings *config.Settings) SummaryData {
+	summary := SummaryData{}
+	for _, account := range accounts {
+		if !settings.IsReportedAccount(account.Name) || !settings.IsNetWorthAccount(account.Name) {
+			continue
+		}
+		if len(account.Name) >= 7 && account.Name[:7] == "assets:" {
+			summary.TotalAssets += account.Balance
+		} else if len(account.Name) >= 12 && account.Name[:12] == "liabilities:" {
+			// Liabilities in hledger are negative; convert to positive
+			summary.TotalLiabilities += -account.Balance
+		}
+		// Balances carry hledger's signs, so liabilities subtract and other included
+		// accounts such as equity: count as recorded
+		summary.NetWorth += account.Balance
+	}
+	return summary
+}
+
+// respondError writes a 500 with message for a failed hledger query, or a 503 naming the
+// problem when hledger itself is not installed
+func respondError(c *gin.Context, err error, message string) {
+	if errors.Is(err, hledger.ErrHledgerNotFound) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": hledger.ErrHledgerNotFound.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
+
+// requireCache returns the cached data for a read handler, writing a refresh-needed
+// response when the cache is empty. Stale data is still served but flagged with an
+// X-Cache-Stale header, and with the staleWhileRevalidate preference enabled a
+// background rebuild is started. Responses carry an ETag for the cache generation;
+// when the client already has it a 304 is written and ok is false.
+func (s *Service) requireCache(c *gin.Context) (*CachedData, bool) {
 	s.cacheMu.RLock()
-	defer s.cacheMu.RUnlock()
-	if s.cache == nil {
+	cache := s.cache
+	stale := cache != nil && cache.Stale
+	refreshing := s.cacheRefreshing
+	s.cacheMu.RUnlock()
+
+	if cache == nil {
+		c.JSON(http.StatusAccepted, gin.H{"message": "cache empty; refresh required", "needsRefresh": true})
 		return nil, false
 	}
-	return s.cache, true
+
+	if stale {
+		c.Header("X-Cache-Stale", "true")
+		if !refreshing && s.staleWhileRevalidate() {
+			go func() {
+				// RebuildCache guards against concurrent refreshes itself
+				if err := s.RebuildCache(); err != nil {
+					s.log().Warn("Background revalidation skipped", "error", err)
+				}
+			}()
+		}
+	}
+
+	etag := fmt.Sprintf("\"%d\"", cache.LastRefresh.UnixNano())
+	c.Header("ETag", etag)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return nil, false
+	}
+
+	return cache, true
+}
+
+// requireSection writes an error response naming the failure when section could not be
+// computed in the last cache rebuild, so the handler serving it doesn't return empty data
+func requireSection(c *gin.Context, cache *CachedData, section string) bool {
+	err, failed := cache.failed[section]
+	if !failed {
+		return true
+	}
+	respondError(c, err, fmt.Sprintf("%s could not be computed: %v", section, err))
+	return false
+}
+
+// etagMatches reports whether an If-None-Match header value lists the given ETag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// staleWhileRevalidate reports whether stale reads should trigger a background rebuild
+func (s *Service) staleWhileRevalidate() bool {
+	return s.currentSettings().GetPreferenceBool("staleWhileRevalidate", false)
 }
 
-// getDateFilter extracts and validates date filter parameters from request
-func (s *Service) getDateFilter(c *gin.Context) *DateFilter {
+// getDateFilter extracts and validates date filter parameters from request.
+// Dates may be literal YYYY-MM-DD values or relative keywords (see resolveDate).
+// It returns nil when the range is not fully specified, and an error naming the
+// offending parameter when a date is malformed or the range is inverted.
+func (s *Service) getDateFilter(c *gin.Context) (*DateFilter, error) {
 	startDate := c.Query("startDate")
 	endDate := c.Query("endDate")
 
-	// Only return a filter if both dates are provided
-	if startDate != "" && endDate != "" {
-		return &DateFilter{
-			StartDate: startDate,
-			EndDate:   endDate,
+	var err error
+	if startDate != "" {
+		if startDate, err = s.resolveDate(startDate, false); err != nil {
+			return nil, fmt.Errorf("invalid startDate %q: %v", c.Query("startDate"), err)
+		}
+	}
+	if endDate != "" {
+		if endDate, err = s.resolveDate(endDate, true); err != nil {
+			return nil, fmt.Errorf("invalid endDate %q: %v", c.Query("endDate"), err)
 		}
 	}
 
-	return nil
+	// Only return a filter if both dates are provided
+	if startDate == "" || endDate == "" {
+		return nil, nil
+	}
+	// Resolved dates share one layout, so they compare correctly as strings
+	if startDate > endDate {
+		return nil, fmt.Errorf("startDate %s is after endDate %s", startDate, endDate)
+	}
+
+	// Clients name the last day to include, while hledger stops before its end date
+	lastDay, err := time.Parse(dateLayout, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endDate %q: %v", c.Query("endDate"), err)
+	}
+	return &DateFilter{
+		StartDate: startDate,
+		EndDate:   lastDay.AddDate(0, 0, 1).Format(dateLayout),
+	}, nil
 }
 
-// hasDateFilter checks if date filtering is active
-func (s *Service) hasDateFilter(c *gin.Context) bool {
-	return c.Query("startDate") != "" && c.Query("endDate") != ""
+// getAsOf parses the optional asOf query param, a date or relative keyword that stands in for
+// today. It returns nil when the param is absent.
+func (s *Service) getAsOf(c *gin.Context) (*time.Time, error) {
+	raw := c.Query("asOf")
+	if raw == "" {
+		return nil, nil
+	}
+	resolved, err := s.resolveDate(raw, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asOf %q: %v", raw, err)
+	}
+	asOf, err := time.Parse(dateLayout, resolved)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asOf %q: %v", raw, err)
+	}
+	return &asOf, nil
 }
 
 // HandleIndex serves the main dashboard page
@@ -213,16 +522,42 @@ func (s *Service) HandleSettings(c *gin.Context) {
 	})
 }
 
-// HandleAccounts returns account data as JSON
-// HandleAccounts returns account data as JSON
+// HandleAccounts returns account data as JSON.
+// An optional depth param rolls subaccounts up to that many levels; 0 means no rollup.
 func (s *Service) HandleAccounts(c *gin.Context) {
+	depth := 0
+	if raw := c.Query("depth"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "depth must be a non-negative integer"})
+			return
+		}
+		depth = parsed
+	}
+
 	// Check if date filtering is requested
-	if s.hasDateFilter(c) {
-		filter := s.getDateFilter(c)
-		accounts, err := s.parser.GetAccountsFiltered(filter.StartDate, filter.EndDate)
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		accounts, err := s.parser.GetAccountsFiltered(filter.StartDate, filter.EndDate, depth)
+		if err != nil {
+			s.log().Error("Error getting filtered accounts", "error", err)
+			respondError(c, err, "Failed to get accounts")
+			return
+		}
+		c.JSON(http.StatusOK, accounts)
+		return
+	}
+
+	// The cache holds unrolled accounts, so rollups are fetched live
+	if depth > 0 {
+		accounts, err := s.parser.GetAccountsAtDepth(depth)
 		if err != nil {
-			log.Printf("Error getting filtered accounts: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get accounts"})
+			s.log().Error("Error getting accounts at depth", "depth", depth, "error", err)
+			respondError(c, err, "Failed to get accounts")
 			return
 		}
 		c.JSON(http.StatusOK, accounts)
@@ -230,316 +565,1777 @@ func (s *Service) HandleAccounts(c *gin.Context) {
 	}
 
 	// Use cache for unfiltered requests
-	cache, ok := s.getCache()
+	cache, ok := s.requireCache(c)
 	if !ok {
-		c.JSON(http.StatusAccepted, gin.H{"message": "cache empty; refresh required", "needsRefresh": true})
+		return
+	}
+	if !requireSection(c, cache, sectionAccounts) {
 		return
 	}
 	c.JSON(http.StatusOK, cache.Accounts)
 }
 
+// HandleAccountTree returns accounts as a nested hierarchy with balances summed up the tree
+func (s *Service) HandleAccountTree(c *gin.Context) {
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	tree, err := s.parser.GetAccountTree(startDate, endDate)
+	if err != nil {
+		s.log().Error("Error getting account tree", "error", err)
+		respondError(c, err, "Failed to get account tree")
+		return
+	}
+	c.JSON(http.StatusOK, tree)
+}
+
+// HandleInactiveAccounts returns accounts without postings in the last since months (default 12)
+func (s *Service) HandleInactiveAccounts(c *gin.Context) {
+	since := 12
+	if raw := c.Query("since"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be a positive integer"})
+			return
+		}
+		since = parsed
+	}
+
+	inactive, err := s.parser.GetInactiveAccounts(since)
+	if err != nil {
+		s.log().Error("Error getting inactive accounts", "error", err)
+		respondError(c, err, "Failed to get inactive accounts")
+		return
+	}
+	c.JSON(http.StatusOK, inactive)
+}
+
 // HandleTransactions returns transaction data as JSON
 func (s *Service) HandleTransactions(c *gin.Context) {
+	transactions, _, ok := s.queryTransactions(c)
+	if !ok {
+		return
+	}
+
+	sortBy := c.DefaultQuery("sort", "date")
+	if sortBy != "date" && sortBy != "amount" && sortBy != "description" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be date, amount or description"})
+		return
+	}
+	order := c.DefaultQuery("order", "desc")
+	if order != "asc" && order != "desc" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order must be asc or desc"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sortTransactions(transactions, sortBy, order == "desc"))
+}
+
+// HandleTransactionsOFX serves the transactions as an OFX 1.x download with one statement per
+// asset and liability account. It takes the same filters as HandleTransactions.
+func (s *Service) HandleTransactionsOFX(c *gin.Context) {
+	transactions, filter, ok := s.queryTransactions(c)
+	if !ok {
+		return
+	}
+
+	// OFX statement ranges name their last day, not hledger's exclusive end
+	var startDate, endDate string
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.LastDay()
+	}
+	now := s.parser.Now()
+	body := formatOFX(transactions, startDate, endDate, now)
+
+	filename := fmt.Sprintf("minted-transactions-%s.ofx", now.Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/x-ofx", []byte(body))
+}
+
+// HandleTransactionsQIF serves the transactions as a QIF download with one section per asset and
+// liability account. The type param picks the QIF account type (Bank by default). It takes the
+// same filters as HandleTransactions.
+func (s *Service) HandleTransactionsQIF(c *gin.Context) {
+	accountType, ok := qifAccountType(c.DefaultQuery("type", "Bank"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of " + strings.Join(qifAccountTypes, ", ")})
+		return
+	}
+
+	transactions, _, ok := s.queryTransactions(c)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("minted-transactions-%s.qif", s.parser.Now().Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/qif", []byte(formatQIF(transactions, accountType)))
+}
+
+// queryTransactions returns the transactions selected by the request's date range, status, tag
+// and amount filters, along with the date filter (nil when no range was given). It writes the
+// error response itself and reports false when the request is invalid or the data unavailable.
+func (s *Service) queryTransactions(c *gin.Context) ([]hledger.Transaction, *DateFilter, bool) {
+	var transactions []hledger.Transaction
+
 	// Check if date filtering is requested
-	if s.hasDateFilter(c) {
-		filter := s.getDateFilter(c)
-		transactions, err := s.parser.GetTransactionsFiltered(filter.StartDate, filter.EndDate)
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+	if filter != nil {
+		filtered, err := s.parser.GetTransactionsFiltered(filter.StartDate, filter.EndDate)
 		if err != nil {
-			log.Printf("Error getting filtered transactions: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get transactions"})
-			return
+			s.log().Error("Error getting filtered transactions", "error", err)
+			respondError(c, err, "Failed to get transactions")
+			return nil, nil, false
 		}
-		c.JSON(http.StatusOK, transactions)
-		return
+		transactions = filtered
+	} else {
+		// Use cache for unfiltered requests
+		cache, ok := s.requireCache(c)
+		if !ok {
+			return nil, nil, false
+		}
+		if !requireSection(c, cache, sectionTransactions) {
+			return nil, nil, false
+		}
+		transactions = cache.Transactions
 	}
 
-	// Use cache for unfiltered requests
-	cache, ok := s.getCache()
-	if !ok {
-		c.JSON(http.StatusAccepted, gin.H{"message": "cache empty; refresh required", "needsRefresh": true})
+	// Optional status filter: cleared, pending or unmarked; all (or none) keeps everything
+	if status := c.Query("status"); status != "" && !strings.EqualFold(status, "all") {
+		if !strings.EqualFold(status, hledger.StatusCleared) && !strings.EqualFold(status, hledger.StatusPending) &&
+			!strings.EqualFold(status, hledger.StatusUnmarked) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be cleared, pending, unmarked or all"})
+			return nil, nil, false
+		}
+		transactions = filterTransactionsByStatus(transactions, status)
+	}
+
+	// Optional tag filter: "name" matches any value, "name=value" matches exactly
+	if tag := c.Query("tag"); tag != "" {
+		name, value, _ := strings.Cut(tag, "=")
+		transactions = filterTransactionsByTag(transactions, name, value)
+	}
+
+	// Optional amount bounds, compared against the largest posting or, with amountBy=total,
+	// the total the transaction moves
+	minAmount, err := queryFloat(c, "minAmount")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+	maxAmount, err := queryFloat(c, "maxAmount")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+	amountBy := c.DefaultQuery("amountBy", "largest")
+	if amountBy != "largest" && amountBy != "total" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amountBy must be largest or total"})
+		return nil, nil, false
+	}
+	if minAmount != nil || maxAmount != nil {
+		transactions = filterTransactionsByAmount(transactions, minAmount, maxAmount, amountBy == "total")
+	}
+
+	return transactions, filter, true
+}
+
+// sortTransactions returns a sorted copy of transactions, leaving the input (which may be the
+// cache) untouched. Amounts compare by income and expense magnitude. Ties keep journal order
+// in either direction, so paging through equal keys is deterministic.
+func sortTransactions(transactions []hledger.Transaction, sortBy string, descending bool) []hledger.Transaction {
+	sorted := make([]hledger.Transaction, len(transactions))
+	copy(sorted, transactions)
+
+	compare := func(a, b hledger.Transaction) int {
+		switch sortBy {
+		case "amount":
+			x, y := a.IncomeExpenseAmount(), b.IncomeExpenseAmount()
+			if x < y {
+				return -1
+			} else if x > y {
+				return 1
+			}
+			return 0
+		case "description":
+			return strings.Compare(strings.ToLower(a.Description), strings.ToLower(b.Description))
+		default:
+			return strings.Compare(a.Date, b.Date)
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		cmp := compare(sorted[i], sorted[j])
+		if cmp == 0 {
+			return sorted[i].Index < sorted[j].Index
+		}
+		if descending {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+	return sorted
+}
+
+// filterTransactionsByAmount returns the transactions whose amount lies within the optional
+// bounds, using the total moved when byTotal is set and the largest posting otherwise
+func filterTransactionsByAmount(transactions []hledger.Transaction, minAmount, maxAmount *float64, byTotal bool) []hledger.Transaction {
+	filtered := []hledger.Transaction{}
+	for _, tx := range transactions {
+		amount := tx.LargestPostingAmount()
+		if byTotal {
+			amount = tx.TotalAmount()
+		}
+		if (minAmount != nil && amount < *minAmount) || (maxAmount != nil && amount > *maxAmount) {
+			continue
+		}
+		filtered = append(filtered, tx)
+	}
+	return filtered
+}
+
+// filterTransactionsByStatus returns the transactions with the given status, ignoring case
+func filterTransactionsByStatus(transactions []hledger.Transaction, status string) []hledger.Transaction {
+	filtered := []hledger.Transaction{}
+	for _, tx := range transactions {
+		if strings.EqualFold(tx.Status, status) {
+			filtered = append(filtered, tx)
+		}
+	}
+	return filtered
+}
+
+// filterTransactionsByTag returns the transactions carrying the given tag without modifying the input
+func filterTransactionsByTag(transactions []hledger.Transaction, name, value string) []hledger.Transaction {
+	filtered := []hledger.Transaction{}
+	for _, tx := range transactions {
+		if tx.HasTag(name, value) {
+			filtered = append(filtered, tx)
+		}
+	}
+	return filtered
+}
+
+// HandleTransactionSearch returns transactions matching a text query, newest first
+func (s *Service) HandleTransactionSearch(c *gin.Context) {
+	search := hledger.TransactionSearch{
+		Query:   c.Query("q"),
+		Account: c.Query("account"),
+	}
+
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		search.StartDate, search.EndDate = filter.StartDate, filter.EndDate
+	}
+
+	if search.MinAmount, err = queryFloat(c, "minAmount"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if search.MaxAmount, err = queryFloat(c, "maxAmount"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := s.parser.SearchTransactions(search)
+	if err != nil {
+		s.log().Error("Error searching transactions", "error", err)
+		respondError(c, err, "Failed to search transactions")
+		return
+	}
+
+	if limit := s.currentSettings().GetPreferenceInt("transactionLimit", 0); limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// queryFloat parses an optional numeric query parameter; nil means it was not provided
+func queryFloat(c *gin.Context, param string) (*float64, error) {
+	raw := c.Query(param)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a number", param)
+	}
+	return &value, nil
+}
+
+// HandleTransfers returns transactions classified as transfers between own accounts
+func (s *Service) HandleTransfers(c *gin.Context) {
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	transfers, err := s.parser.GetTransfers(startDate, endDate)
+	if err != nil {
+		s.log().Error("Error getting transfers", "error", err)
+		respondError(c, err, "Failed to get transfers")
+		return
+	}
+	c.JSON(http.StatusOK, transfers)
+}
+
+// HandleSummary returns financial summary
+func (s *Service) HandleSummary(c *gin.Context) {
+	// Check if date filtering is requested
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		// Get cumulative balances up to end date for accurate net worth
+		accounts, err := s.parser.GetAccountsUpToDate(filter.EndDate)
+		if err != nil {
+			s.log().Error("Error getting accounts up to date", "error", err)
+			respondError(c, err, "Failed to get summary")
+			return
+		}
+
+		summary := summarizeAccounts(accounts, s.currentSettings())
+
+		c.JSON(http.StatusOK, gin.H{
+			"totalAssets":      summary.TotalAssets,
+			"totalLiabilities": summary.TotalLiabilities,
+			"netWorth":         summary.NetWorth,
+		})
+		return
+	}
+
+	// Use cache for unfiltered requests
+	cache, ok := s.requireCache(c)
+	if !ok {
+		return
+	}
+	if !requireSection(c, cache, sectionAccounts) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"totalAssets":      cache.Summary.TotalAssets,
+		"totalLiabilities": cache.Summary.TotalLiabilities,
+		"netWorth":         cache.Summary.NetWorth,
+	})
+}
+
+// HandleBudgetComparison returns budget data with historical averages.
+// With prorate=true each item also carries averages scaled to the elapsed part of the month.
+// An asOf date computes the budget as it looked on that day instead of serving the cache.
+func (s *Service) HandleBudgetComparison(c *gin.Context) {
+	asOf, err := s.getAsOf(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var budget []hledger.BudgetItem
+	now := s.parser.Now()
+	if asOf != nil {
+		budget, err = s.parser.GetBudgetDataAsOf(*asOf)
+		if err != nil {
+			s.log().Error("Error getting budget as of date", "asOf", asOf.Format(dateLayout), "error", err)
+			respondError(c, err, "Failed to get budget")
+			return
+		}
+		now = *asOf
+	} else {
+		cache, ok := s.requireCache(c)
+		if !ok {
+			return
+		}
+		if !requireSection(c, cache, sectionBudget) {
+			return
+		}
+		budget = cache.Budget
+	}
+
+	if c.Query("prorate") == "true" {
+		c.JSON(http.StatusOK, s.parser.ProrateBudget(budget, now))
+		return
+	}
+	c.JSON(http.StatusOK, budget)
+}
+
+// HandleSpendingForecast returns projected end-of-month spending per category
+func (s *Service) HandleSpendingForecast(c *gin.Context) {
+	forecast, err := s.parser.GetSpendingForecast()
+	if err != nil {
+		s.log().Error("Error getting spending forecast", "error", err)
+		respondError(c, err, "Failed to get spending forecast")
+		return
+	}
+	c.JSON(http.StatusOK, forecast)
+}
+
+// HandleSpendingAnomalies returns the categories whose latest complete month stands out from
+// their history
+func (s *Service) HandleSpendingAnomalies(c *gin.Context) {
+	anomalies, err := s.parser.GetSpendingAnomalies()
+	if err != nil {
+		s.log().Error("Error getting spending anomalies", "error", err)
+		respondError(c, err, "Failed to get spending anomalies")
+		return
+	}
+	c.JSON(http.StatusOK, anomalies)
+}
+
+// HandleBudgetAlerts returns the categories over budget by more than the alert threshold
+func (s *Service) HandleBudgetAlerts(c *gin.Context) {
+	alerts, err := s.parser.GetBudgetAlerts()
+	if err != nil {
+		s.log().Error("Error getting budget alerts", "error", err)
+		respondError(c, err, "Failed to get budget alerts")
+		return
+	}
+	c.JSON(http.StatusOK, alerts)
+}
+
+// HandleTierBudgetStatus returns spending against budget for each tier that has a budget set
+func (s *Service) HandleTierBudgetStatus(c *gin.Context) {
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	statuses, err := s.parser.GetTierBudgetStatus(startDate, endDate)
+	if err != nil {
+		s.log().Error("Error getting tier budget status", "error", err)
+		respondError(c, err, "Failed to get tier budget status")
+		return
+	}
+
+	// Skip tiers without a budget
+	budgeted := []hledger.TierBudgetStatus{}
+	for _, status := range statuses {
+		if status.Budget > 0 {
+			budgeted = append(budgeted, status)
+		}
+	}
+	c.JSON(http.StatusOK, budgeted)
+}
+
+// HandleUntieredCategories returns expense categories not assigned to any tier
+func (s *Service) HandleUntieredCategories(c *gin.Context) {
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	untiered, err := s.parser.GetUntieredCategories(startDate, endDate)
+	if err != nil {
+		s.log().Error("Error getting untiered categories", "error", err)
+		respondError(c, err, "Failed to get untiered categories")
+		return
+	}
+	c.JSON(http.StatusOK, untiered)
+}
+
+// HandleSuggestTiers suggests tiers for expense categories that aren't in any tier. The
+// suggestions are not applied.
+func (s *Service) HandleSuggestTiers(c *gin.Context) {
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	suggestions, err := s.parser.SuggestTierAssignments(startDate, endDate)
+	if err != nil {
+		s.log().Error("Error suggesting tier assignments", "error", err)
+		respondError(c, err, "Failed to suggest tier assignments")
+		return
+	}
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// HandleBudgetHistory returns historical budget vs actuals
+func (s *Service) HandleBudgetHistory(c *gin.Context) {
+	// Check if date filtering is requested
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		budgetHistory, err := cachedFiltered(s, "budget-history", filter, s.parser.GetBudgetHistoryFiltered)
+		if err != nil {
+			s.log().Error("Error getting filtered budget history", "error", err)
+			respondError(c, err, "Failed to get budget history")
+			return
+		}
+		c.JSON(http.StatusOK, budgetHistory)
+		return
+	}
+
+	asOf, err := s.getAsOf(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if asOf != nil {
+		budgetHistory, err := s.parser.GetBudgetHistoryAsOf(*asOf)
+		if err != nil {
+			s.log().Error("Error getting budget history as of date", "asOf", asOf.Format(dateLayout), "error", err)
+			respondError(c, err, "Failed to get budget history")
+			return
+		}
+		c.JSON(http.StatusOK, budgetHistory)
+		return
+	}
+
+	// Use cache for unfiltered requests
+	cache, ok := s.requireCache(c)
+	if !ok {
+		return
+	}
+	if !requireSection(c, cache, sectionBudgetHistory) {
+		return
+	}
+	c.JSON(http.StatusOK, cache.BudgetHistory)
+}
+
+// HandleMonthlyMetrics returns monthly income, expenses, and savings
+func (s *Service) HandleMonthlyMetrics(c *gin.Context) {
+	// Check if date filtering is requested
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		monthlyMetrics, err := cachedFiltered(s, "monthly-metrics", filter, s.parser.GetMonthlyMetricsFiltered)
+		if err != nil {
+			s.log().Error("Error getting filtered monthly metrics", "error", err)
+			respondError(c, err, "Failed to get monthly metrics")
+			return
+		}
+		c.JSON(http.StatusOK, monthlyMetrics)
+		return
+	}
+
+	// Use cache for unfiltered requests
+	cache, ok := s.requireCache(c)
+	if !ok {
+		return
+	}
+	if !requireSection(c, cache, sectionMonthlyMetrics) {
+		return
+	}
+	c.JSON(http.StatusOK, cache.MonthlyMetrics)
+}
+
+// HandleWeeklyMetrics returns income, expenses, and savings rate per ISO week
+func (s *Service) HandleWeeklyMetrics(c *gin.Context) {
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	weekly, err := s.parser.GetWeeklyMetrics(startDate, endDate)
+	if err != nil {
+		s.log().Error("Error getting weekly metrics", "error", err)
+		respondError(c, err, "Failed to get weekly metrics")
+		return
+	}
+	c.JSON(http.StatusOK, weekly)
+}
+
+// HandleCategorySpending returns spending by category over time
+func (s *Service) HandleCategorySpending(c *gin.Context) {
+	period := c.DefaultQuery("period", hledger.PeriodMonth)
+	if period != hledger.PeriodMonth && period != hledger.PeriodQuarter && period != hledger.PeriodYear {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period must be month, quarter or year"})
+		return
+	}
+
+	// Check if date filtering is requested
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var categorySpending []hledger.CategorySpending
+	if filter != nil {
+		categorySpending, err = cachedFiltered(s, "category-spending", filter, s.parser.GetCategorySpendingFiltered)
+		if err != nil {
+			s.log().Error("Error getting filtered category spending", "error", err)
+			respondError(c, err, "Failed to get category spending")
+			return
+		}
+	} else {
+		// Use cache for unfiltered requests
+		cache, ok := s.requireCache(c)
+		if !ok {
+			return
+		}
+		if !requireSection(c, cache, sectionCategorySpending) {
+			return
+		}
+		categorySpending = cache.CategorySpending
+	}
+
+	rolled, err := s.parser.RollupCategorySpending(categorySpending, period)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rolled)
+}
+
+// HandleCategoryShares returns each expense category's share of total spending in the range
+func (s *Service) HandleCategoryShares(c *gin.Context) {
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	shares, err := s.parser.GetCategoryShares(startDate, endDate)
+	if err != nil {
+		s.log().Error("Error getting category shares", "error", err)
+		respondError(c, err, "Failed to get category shares")
+		return
+	}
+	c.JSON(http.StatusOK, shares)
+}
+
+// HandleCategoryBreakdown returns expense or income shares with categories below minPercent
+// grouped into "Other"
+func (s *Service) HandleCategoryBreakdown(c *gin.Context) {
+	kind := c.DefaultQuery("type", hledger.BreakdownExpenses)
+	if kind != hledger.BreakdownExpenses && kind != hledger.BreakdownIncome {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be expenses or income"})
+		return
+	}
+	minPercent, err := queryFloat(c, "minPercent")
+	if err != nil || (minPercent != nil && (*minPercent < 0 || *minPercent > 100)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "minPercent must be a number between 0 and 100"})
+		return
+	}
+	if minPercent == nil {
+		noGrouping := 0.0
+		minPercent = &noGrouping
+	}
+
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	breakdown, err := s.parser.GetCategoryBreakdownGrouped(kind, startDate, endDate, *minPercent)
+	if err != nil {
+		s.log().Error("Error getting category breakdown", "error", err)
+		respondError(c, err, "Failed to get category breakdown")
+		return
+	}
+	c.JSON(http.StatusOK, breakdown)
+}
+
+// HandleSpendingByWeekday returns expense totals and averages for each day of the week
+func (s *Service) HandleSpendingByWeekday(c *gin.Context) {
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	weekdays, err := s.parser.GetSpendingByWeekday(startDate, endDate)
+	if err != nil {
+		s.log().Error("Error getting spending by weekday", "error", err)
+		respondError(c, err, "Failed to get spending by weekday")
+		return
+	}
+	c.JSON(http.StatusOK, weekdays)
+}
+
+// HandleSpendingByDayOfMonth returns expense totals and averages for each day of the month
+func (s *Service) HandleSpendingByDayOfMonth(c *gin.Context) {
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	days, err := s.parser.GetSpendingByDayOfMonth(startDate, endDate)
+	if err != nil {
+		s.log().Error("Error getting spending by day of month", "error", err)
+		respondError(c, err, "Failed to get spending by day of month")
+		return
+	}
+	c.JSON(http.StatusOK, days)
+}
+
+// HandleIncomeBreakdown returns income categories aggregated across all months
+func (s *Service) HandleIncomeBreakdown(c *gin.Context) {
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		incomeBreakdown, err := cachedFiltered(s, "income-breakdown", filter, s.parser.GetIncomeBreakdownFiltered)
+		if err != nil {
+			s.log().Error("Error getting filtered income breakdown", "error", err)
+			respondError(c, err, "Failed to get income breakdown")
+			return
+		}
+		c.JSON(http.StatusOK, incomeBreakdown)
+		return
+	}
+
+	incomeBreakdown, err := s.parser.GetIncomeBreakdown()
+	if err != nil {
+		s.log().Error("Error getting income breakdown", "error", err)
+		respondError(c, err, "Failed to get income breakdown")
+		return
+	}
+	c.JSON(http.StatusOK, incomeBreakdown)
+}
+
+// HandleIncomeHistory returns income history by category and month
+func (s *Service) HandleIncomeHistory(c *gin.Context) {
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		incomeHistory, err := cachedFiltered(s, "income-history", filter, s.parser.GetIncomeHistoryFiltered)
+		if err != nil {
+			s.log().Error("Error getting filtered income history", "error", err)
+			respondError(c, err, "Failed to get income history")
+			return
+		}
+		c.JSON(http.StatusOK, incomeHistory)
+		return
+	}
+
+	incomeHistory, err := s.parser.GetIncomeHistory()
+	if err != nil {
+		s.log().Error("Error getting income history", "error", err)
+		respondError(c, err, "Failed to get income history")
+		return
+	}
+	c.JSON(http.StatusOK, incomeHistory)
+}
+
+// HandleTopExpenses returns the largest individual expense postings
+func (s *Service) HandleTopExpenses(c *gin.Context) {
+	n := 10
+	if raw := c.Query("n"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "n must be a positive integer"})
+			return
+		}
+		n = parsed
+	}
+
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	topExpenses, err := s.parser.GetTopExpenses(n, startDate, endDate)
+	if err != nil {
+		s.log().Error("Error getting top expenses", "error", err)
+		respondError(c, err, "Failed to get top expenses")
+		return
+	}
+	c.JSON(http.StatusOK, topExpenses)
+}
+
+// HandleNetWorthOverTime returns net worth for each month
+func (s *Service) HandleNetWorthOverTime(c *gin.Context) {
+	// Check if date filtering is requested
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		netWorth, err := cachedFiltered(s, "net-worth", filter, s.parser.GetNetWorthOverTimeFiltered)
+		if err != nil {
+			s.log().Error("Error getting filtered net worth", "error", err)
+			respondError(c, err, "Failed to get net worth")
+			return
+		}
+		c.JSON(http.StatusOK, netWorth)
+		return
+	}
+
+	// Use cache for unfiltered requests
+	cache, ok := s.requireCache(c)
+	if !ok {
+		return
+	}
+	if !requireSection(c, cache, sectionNetWorth) {
+		return
+	}
+	c.JSON(http.StatusOK, cache.NetWorthOverTime)
+}
+
+// HandleNetWorthProjection returns monthly net worth with a linear projection of the next months
+func (s *Service) HandleNetWorthProjection(c *gin.Context) {
+	months := 12
+	if raw := c.Query("months"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "months must be a non-negative integer"})
+			return
+		}
+		months = parsed
+	}
+
+	projection, err := s.parser.GetNetWorthProjection(months)
+	if err != nil {
+		s.log().Error("Error getting net worth projection", "error", err)
+		respondError(c, err, "Failed to get net worth projection")
+		return
+	}
+	c.JSON(http.StatusOK, projection)
+}
+
+// HandleNetWorthMilestones returns when net worth first reached each multiple of step
+// (default 10000)
+func (s *Service) HandleNetWorthMilestones(c *gin.Context) {
+	step, err := queryFloat(c, "step")
+	if err != nil || (step != nil && *step <= 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "step must be a positive number"})
+		return
+	}
+	if step == nil {
+		defaultStep := 10000.0
+		step = &defaultStep
+	}
+
+	milestones, err := s.parser.GetNetWorthMilestones(*step)
+	if errors.Is(err, hledger.ErrTooManyMilestones) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		s.log().Error("Error getting net worth milestones", "error", err)
+		respondError(c, err, "Failed to get net worth milestones")
+		return
+	}
+	c.JSON(http.StatusOK, milestones)
+}
+
+// HandleDebtSummary returns liability balances and credit utilization as of the end date
+func (s *Service) HandleDebtSummary(c *gin.Context) {
+	var endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		endDate = filter.EndDate
+	}
+
+	summary, err := s.parser.GetDebtSummary(endDate)
+	if err != nil {
+		s.log().Error("Error getting debt summary", "error", err)
+		respondError(c, err, "Failed to get debt summary")
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+// HandleRunway returns how many months liquid assets cover at the recent expense rate
+func (s *Service) HandleRunway(c *gin.Context) {
+	n := 6
+	if raw := c.Query("n"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "n must be a positive integer"})
+			return
+		}
+		n = parsed
+	}
+
+	runway, err := s.parser.GetRunway(n)
+	if err != nil {
+		s.log().Error("Error getting runway", "error", err)
+		respondError(c, err, "Failed to get runway")
+		return
+	}
+	c.JSON(http.StatusOK, runway)
+}
+
+// HandleRecurring returns expenses that repeat monthly or yearly at a steady amount
+func (s *Service) HandleRecurring(c *gin.Context) {
+	recurring, err := s.parser.GetRecurringTransactions()
+	if err != nil {
+		s.log().Error("Error getting recurring transactions", "error", err)
+		respondError(c, err, "Failed to get recurring transactions")
+		return
+	}
+	c.JSON(http.StatusOK, recurring)
+}
+
+// HandleDuplicates returns groups of transactions that look like double postings
+func (s *Service) HandleDuplicates(c *gin.Context) {
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	duplicates, err := s.parser.GetDuplicateTransactions(startDate, endDate)
+	if err != nil {
+		s.log().Error("Error getting duplicate transactions", "error", err)
+		respondError(c, err, "Failed to get duplicate transactions")
+		return
+	}
+	c.JSON(http.StatusOK, duplicates)
+}
+
+// HandleStatistics returns counts and the date span of the journal data
+func (s *Service) HandleStatistics(c *gin.Context) {
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	stats, err := s.parser.GetStatistics(startDate, endDate)
+	if err != nil {
+		s.log().Error("Error getting statistics", "error", err)
+		respondError(c, err, "Failed to get statistics")
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// HandleAccountHistogram returns a histogram of the posting amounts to an account, with the
+// number of buckets set by the buckets param (default 10)
+func (s *Service) HandleAccountHistogram(c *gin.Context) {
+	account := c.Query("account")
+	if account == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "account parameter required"})
+		return
+	}
+
+	buckets := 10
+	if raw := c.Query("buckets"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "buckets must be a positive integer"})
+			return
+		}
+		buckets = parsed
+	}
+
+	histogram, err := s.parser.GetAccountHistogram(account, buckets)
+	if err != nil {
+		s.log().Error("Error getting account histogram", "account", account, "error", err)
+		respondError(c, err, "Failed to get account histogram")
+		return
+	}
+	c.JSON(http.StatusOK, histogram)
+}
+
+// HandleIncomeVsExpense returns monthly income and expenses side by side
+func (s *Service) HandleIncomeVsExpense(c *gin.Context) {
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	series, err := s.parser.GetIncomeVsExpense(startDate, endDate)
+	if err != nil {
+		s.log().Error("Error getting income vs expense", "error", err)
+		respondError(c, err, "Failed to get income vs expense")
+		return
+	}
+	c.JSON(http.StatusOK, series)
+}
+
+// HandleDailySpending returns zero-filled expense totals per day for a heatmap
+func (s *Service) HandleDailySpending(c *gin.Context) {
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	daily, err := s.parser.GetDailySpending(startDate, endDate)
+	if err != nil {
+		s.log().Error("Error getting daily spending", "error", err)
+		respondError(c, err, "Failed to get daily spending")
+		return
+	}
+	c.JSON(http.StatusOK, daily)
+}
+
+// HandleAverageDailySpend returns each month's expenses with the average per day
+func (s *Service) HandleAverageDailySpend(c *gin.Context) {
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	averages, err := s.parser.GetAverageDailySpend(startDate, endDate)
+	if err != nil {
+		s.log().Error("Error getting average daily spend", "error", err)
+		respondError(c, err, "Failed to get average daily spend")
+		return
+	}
+	c.JSON(http.StatusOK, averages)
+}
+
+// HandleSavingsRateTrend returns the monthly savings rate with a trailing moving average
+func (s *Service) HandleSavingsRateTrend(c *gin.Context) {
+	window := 3
+	if raw := c.Query("window"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "window must be a positive integer"})
+			return
+		}
+		window = parsed
+	}
+
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	trend, err := s.parser.GetSavingsRateTrend(window, startDate, endDate)
+	if err != nil {
+		s.log().Error("Error getting savings rate trend", "error", err)
+		respondError(c, err, "Failed to get savings rate trend")
+		return
+	}
+	c.JSON(http.StatusOK, trend)
+}
+
+// HandleCategoryTrends returns spending trends for each category
+func (s *Service) HandleCategoryTrends(c *gin.Context) {
+	// smooth=N adds an N-month trailing moving average to each point
+	smooth := 0
+	if raw := c.Query("smooth"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "smooth must be a positive integer"})
+			return
+		}
+		smooth = parsed
+	}
+
+	// Check if date filtering is requested
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var categoryTrends []hledger.CategoryTrendData
+	if filter != nil {
+		categoryTrends, err = cachedFiltered(s, "category-trends", filter, s.parser.GetCategoryTrendsFiltered)
+		if err != nil {
+			s.log().Error("Error getting filtered category trends", "error", err)
+			respondError(c, err, "Failed to get category trends")
+			return
+		}
+	} else {
+		// Use cache for unfiltered requests
+		cache, ok := s.requireCache(c)
+		if !ok {
+			return
+		}
+		if !requireSection(c, cache, sectionCategoryTrends) {
+			return
+		}
+		categoryTrends = cache.CategoryTrends
+	}
+
+	if smooth > 0 {
+		categoryTrends = s.parser.SmoothCategoryTrends(categoryTrends, smooth)
+	}
+	c.JSON(http.StatusOK, categoryTrends)
+}
+
+// HandleYearOverYearComparison returns spending comparison across years
+func (s *Service) HandleYearOverYearComparison(c *gin.Context) {
+	// Check if date filtering is requested
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		yoyData, err := cachedFiltered(s, "year-over-year", filter, s.parser.GetYearOverYearComparisonFiltered)
+		if err != nil {
+			s.log().Error("Error getting filtered year-over-year", "error", err)
+			respondError(c, err, "Failed to get year-over-year comparison")
+			return
+		}
+		c.JSON(http.StatusOK, yoyData)
+		return
+	}
+
+	// Use cache for unfiltered requests
+	cache, ok := s.requireCache(c)
+	if !ok {
+		return
+	}
+	if !requireSection(c, cache, sectionYearOverYear) {
+		return
+	}
+	c.JSON(http.StatusOK, cache.YearOverYear)
+}
+
+// HandleYearOverYearChange returns the change in each month's spending between consecutive years
+func (s *Service) HandleYearOverYearChange(c *gin.Context) {
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	changes, err := s.parser.GetYearOverYearChange(startDate, endDate)
+	if err != nil {
+		s.log().Error("Error getting year-over-year change", "error", err)
+		respondError(c, err, "Failed to get year-over-year change")
+		return
+	}
+	c.JSON(http.StatusOK, changes)
+}
+
+// HandleCategoryGrowth returns expense categories ranked by how fast their spending is growing
+func (s *Service) HandleCategoryGrowth(c *gin.Context) {
+	growth, err := s.parser.GetCategoryGrowthRates()
+	if err != nil {
+		s.log().Error("Error getting category growth rates", "error", err)
+		respondError(c, err, "Failed to get category growth rates")
+		return
+	}
+	c.JSON(http.StatusOK, growth)
+}
+
+// HandleCategories returns every expense category in the journal with its posting count
+func (s *Service) HandleCategories(c *gin.Context) {
+	categories, err := s.parser.GetAllCategories()
+	if err != nil {
+		s.log().Error("Error getting categories", "error", err)
+		respondError(c, err, "Failed to get categories")
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+// HandleIncomeSources returns every income source in the journal with its posting count
+func (s *Service) HandleIncomeSources(c *gin.Context) {
+	sources, err := s.parser.GetAllIncomeSources()
+	if err != nil {
+		s.log().Error("Error getting income sources", "error", err)
+		respondError(c, err, "Failed to get income sources")
+		return
+	}
+	c.JSON(http.StatusOK, sources)
+}
+
+// HandleIncomeOverTime returns total income per month, zero-filled across the date range
+func (s *Service) HandleIncomeOverTime(c *gin.Context) {
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	income, err := s.parser.GetIncomeOverTime(startDate, endDate)
+	if err != nil {
+		s.log().Error("Error getting income over time", "error", err)
+		respondError(c, err, "Failed to get income over time")
+		return
+	}
+	c.JSON(http.StatusOK, income)
+}
+
+// HandleGetSettings returns the current application settings
+func (s *Service) HandleGetSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, s.currentSettings())
+}
+
+// HandleUpdateSettings updates application settings and saves to disk
+func (s *Service) HandleUpdateSettings(c *gin.Context) {
+	var updatedSettings config.Settings
+	if err := c.BindJSON(&updatedSettings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid settings format"})
+		return
+	}
+
+	// Reject invalid settings before touching memory or disk
+	if err := updatedSettings.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Clone compiles the tier patterns, which a bound request body lacks
+	installed, err := updatedSettings.Clone()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Save and swap under the settings lock, so a failed save leaves the running settings alone
+	// and concurrent updates can't install one body while saving another
+	err = s.modifySettings(func(settings *config.Settings) error {
+		*settings = *installed
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Mark cache as stale so the next refresh will recompute with new settings
+	s.markCacheStale()
+
+	c.JSON(http.StatusOK, gin.H{"message": "settings updated successfully"})
+}
+
+// HandleReloadSettings re-reads settings.json from disk, for picking up hand edits without
+// a restart. The running settings are kept if the file no longer validates.
+func (s *Service) HandleReloadSettings(c *gin.Context) {
+	reloaded, err := config.LoadSettings()
+	if err != nil {
+		s.log().Error("Error reloading settings", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := reloaded.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "settings file is invalid: " + err.Error()})
+		return
+	}
+
+	s.replaceSettings(reloaded)
+
+	// Mark cache as stale so the next refresh will recompute with the reloaded settings
+	s.markCacheStale()
+
+	c.JSON(http.StatusOK, reloaded)
+}
+
+// HandleExportSettings serves settings.json as a download, as a backup for HandleImportSettings
+func (s *Service) HandleExportSettings(c *gin.Context) {
+	data, err := config.ReadSettingsFile()
+	if err != nil {
+		s.log().Error("Error reading settings file", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, cache.Transactions)
-}
-
-// HandleSummary returns financial summary
-func (s *Service) HandleSummary(c *gin.Context) {
-	// Check if date filtering is requested
-	if s.hasDateFilter(c) {
-		filter := s.getDateFilter(c)
-		// Get cumulative balances up to end date for accurate net worth
-		accounts, err := s.parser.GetAccountsUpToDate(filter.EndDate)
-		if err != nil {
-			log.Printf("Error getting accounts up to date: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get summary"})
-			return
-		}
 
-		summary := SummaryData{}
-		for _, account := range accounts {
-			if len(account.Name) >= 7 && account.Name[:7] == "assets:" {
-				summary.TotalAssets += account.Balance
-			} else if len(account.Name) >= 12 && account.Name[:12] == "liabilities:" {
-				summary.TotalLiabilities += -account.Balance
-			}
-		}
-		summary.NetWorth = summary.TotalAssets - summary.TotalLiabilities
+	c.Header("Content-Disposition", `attachment; filename="settings.json"`)
+	c.Data(http.StatusOK, "application/json", data)
+}
 
-		c.JSON(http.StatusOK, gin.H{
-			"totalAssets":      summary.TotalAssets,
-			"totalLiabilities": summary.TotalLiabilities,
-			"netWorth":         summary.NetWorth,
-		})
+// HandleImportSettings replaces the settings with an uploaded settings.json. The payload is
+// parsed and validated before anything is written, so a bad upload leaves settings untouched.
+func (s *Service) HandleImportSettings(c *gin.Context) {
+	data, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
 		return
 	}
 
-	// Use cache for unfiltered requests
-	cache, ok := s.getCache()
-	if !ok {
-		c.JSON(http.StatusAccepted, gin.H{"message": "cache empty; refresh required", "needsRefresh": true})
+	imported, err := config.ParseSettings(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := imported.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"totalAssets":      cache.Summary.TotalAssets,
-		"totalLiabilities": cache.Summary.TotalLiabilities,
-		"netWorth":         cache.Summary.NetWorth,
+	// Save and swap under the settings lock so a concurrent change can't interleave
+	err = s.modifySettings(func(settings *config.Settings) error {
+		*settings = *imported
+		return nil
 	})
-}
-
-// HandleBudgetComparison returns budget data with historical averages
-func (s *Service) HandleBudgetComparison(c *gin.Context) {
-	cache, ok := s.getCache()
-	if !ok {
-		c.JSON(http.StatusAccepted, gin.H{"message": "cache empty; refresh required", "needsRefresh": true})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, cache.Budget)
+	s.markCacheStale()
+
+	c.JSON(http.StatusOK, imported)
 }
 
-// HandleBudgetHistory returns historical budget vs actuals
-func (s *Service) HandleBudgetHistory(c *gin.Context) {
-	// Check if date filtering is requested
-	if s.hasDateFilter(c) {
-		filter := s.getDateFilter(c)
-		budgetHistory, err := s.parser.GetBudgetHistoryFiltered(filter.StartDate, filter.EndDate)
-		if err != nil {
-			log.Printf("Error getting filtered budget history: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get budget history"})
-			return
-		}
-		c.JSON(http.StatusOK, budgetHistory)
+// HandleAddTransaction appends a transaction to the journal. Writing is disabled unless
+// the allowWrite preference is set, since it modifies the user's data.
+func (s *Service) HandleAddTransaction(c *gin.Context) {
+	if !s.currentSettings().GetPreferenceBool("allowWrite", false) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "journal writes are disabled; enable the allowWrite preference"})
 		return
 	}
 
-	// Use cache for unfiltered requests
-	cache, ok := s.getCache()
-	if !ok {
-		c.JSON(http.StatusAccepted, gin.H{"message": "cache empty; refresh required", "needsRefresh": true})
+	var tx hledger.NewTransaction
+	if err := c.BindJSON(&tx); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid transaction format"})
 		return
 	}
-	c.JSON(http.StatusOK, cache.BudgetHistory)
-}
-
-// HandleMonthlyMetrics returns monthly income, expenses, and savings
-func (s *Service) HandleMonthlyMetrics(c *gin.Context) {
-	// Check if date filtering is requested
-	if s.hasDateFilter(c) {
-		filter := s.getDateFilter(c)
-		monthlyMetrics, err := s.parser.GetMonthlyMetricsFiltered(filter.StartDate, filter.EndDate)
-		if err != nil {
-			log.Printf("Error getting filtered monthly metrics: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get monthly metrics"})
-			return
-		}
-		c.JSON(http.StatusOK, monthlyMetrics)
+	if _, err := hledger.FormatJournalEntry(tx); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Use cache for unfiltered requests
-	cache, ok := s.getCache()
-	if !ok {
-		c.JSON(http.StatusAccepted, gin.H{"message": "cache empty; refresh required", "needsRefresh": true})
+	entry, err := s.parser.AppendTransaction(tx)
+	if err != nil {
+		s.log().Error("Error appending transaction", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to append transaction"})
 		return
 	}
-	c.JSON(http.StatusOK, cache.MonthlyMetrics)
+
+	// The journal changed, so cached data is outdated
+	s.markCacheStale()
+
+	c.JSON(http.StatusCreated, gin.H{"entry": entry})
 }
 
-// HandleCategorySpending returns spending by category over time
-func (s *Service) HandleCategorySpending(c *gin.Context) {
-	// Check if date filtering is requested
-	if s.hasDateFilter(c) {
-		filter := s.getDateFilter(c)
-		categorySpending, err := s.parser.GetCategorySpendingFiltered(filter.StartDate, filter.EndDate)
-		if err != nil {
-			log.Printf("Error getting filtered category spending: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get category spending"})
+// HandleCheckJournal runs hledger check and reports any problems found. The optional checks
+// param is a comma-separated list of hledger check names and strict=true adds --strict.
+func (s *Service) HandleCheckJournal(c *gin.Context) {
+	var checks []string
+	for _, check := range strings.Split(c.Query("checks"), ",") {
+		if check = strings.TrimSpace(check); check != "" {
+			checks = append(checks, check)
+		}
+	}
+
+	result, err := s.parser.CheckJournal(checks, c.Query("strict") == "true")
+	if err != nil {
+		if errors.Is(err, hledger.ErrInvalidCheckName) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, categorySpending)
+		s.log().Error("Error checking journal", "error", err)
+		respondError(c, err, "Failed to check journal")
 		return
 	}
+	c.JSON(http.StatusOK, result)
+}
 
-	// Use cache for unfiltered requests
-	cache, ok := s.getCache()
-	if !ok {
-		c.JSON(http.StatusAccepted, gin.H{"message": "cache empty; refresh required", "needsRefresh": true})
+// HandleJournalFiles lists the files hledger reads, including included ones
+func (s *Service) HandleJournalFiles(c *gin.Context) {
+	files, err := s.parser.GetJournalFiles()
+	if err != nil {
+		s.log().Error("Error getting journal files", "error", err)
+		respondError(c, err, "Failed to get journal files")
 		return
 	}
-	c.JSON(http.StatusOK, cache.CategorySpending)
+	c.JSON(http.StatusOK, files)
 }
 
-// HandleIncomeBreakdown returns income categories aggregated across all months
-func (s *Service) HandleIncomeBreakdown(c *gin.Context) {
-	if s.hasDateFilter(c) {
-		filter := s.getDateFilter(c)
-		incomeBreakdown, err := s.parser.GetIncomeBreakdownFiltered(filter.StartDate, filter.EndDate)
+// HandleGoals returns savings goal progress on GET and creates a new goal on POST
+func (s *Service) HandleGoals(c *gin.Context) {
+	if c.Request.Method == http.MethodPost {
+		var goal config.Goal
+		if err := c.BindJSON(&goal); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid goal format"})
+			return
+		}
+		if goal.Name == "" || goal.Account == "" || goal.Target <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "goal requires a name, an account and a positive target"})
+			return
+		}
+
+		err := s.modifySettings(func(settings *config.Settings) error {
+			return settings.CreateGoal(goal)
+		})
+		if errors.Is(err, errSettingsNotSaved) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
 		if err != nil {
-			log.Printf("Error getting filtered income breakdown: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get income breakdown"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, incomeBreakdown)
+
+		c.JSON(http.StatusCreated, goal)
 		return
 	}
 
-	incomeBreakdown, err := s.parser.GetIncomeBreakdown()
+	progress, err := s.parser.GetGoalProgress()
 	if err != nil {
-		log.Printf("Error getting income breakdown: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get income breakdown"})
+		s.log().Error("Error getting goal progress", "error", err)
+		respondError(c, err, "Failed to get goal progress")
 		return
 	}
-	c.JSON(http.StatusOK, incomeBreakdown)
+	c.JSON(http.StatusOK, progress)
 }
 
-// HandleIncomeHistory returns income history by category and month
-func (s *Service) HandleIncomeHistory(c *gin.Context) {
-	if s.hasDateFilter(c) {
-		filter := s.getDateFilter(c)
-		incomeHistory, err := s.parser.GetIncomeHistoryFiltered(filter.StartDate, filter.EndDate)
+// themeRequest is the body accepted by HandleThemes to add or update a custom theme
+type themeRequest struct {
+	Name   string            `json:"name"`
+	Colors map[string]string `json:"colors"`
+}
+
+// HandleThemes lists the available themes on GET and adds or updates a custom theme on POST
+func (s *Service) HandleThemes(c *gin.Context) {
+	if c.Request.Method == http.MethodPost {
+		var theme themeRequest
+		if err := c.BindJSON(&theme); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid theme format"})
+			return
+		}
+
+		err := s.modifySettings(func(settings *config.Settings) error {
+			return settings.SetCustomTheme(theme.Name, theme.Colors)
+		})
+		if errors.Is(err, errSettingsNotSaved) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
 		if err != nil {
-			log.Printf("Error getting filtered income history: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get income history"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, incomeHistory)
+
+		c.JSON(http.StatusOK, theme)
 		return
 	}
 
-	incomeHistory, err := s.parser.GetIncomeHistory()
-	if err != nil {
-		log.Printf("Error getting income history: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get income history"})
-		return
+	settings := s.currentSettings()
+	customThemes := settings.CustomThemes
+	if customThemes == nil {
+		customThemes = map[string]map[string]string{}
 	}
-	c.JSON(http.StatusOK, incomeHistory)
+	c.JSON(http.StatusOK, gin.H{
+		"themes":       settings.GetAvailableThemes(),
+		"customThemes": customThemes,
+		"current":      settings.Theme,
+	})
 }
 
-// HandleNetWorthOverTime returns net worth for each month
-func (s *Service) HandleNetWorthOverTime(c *gin.Context) {
-	// Check if date filtering is requested
-	if s.hasDateFilter(c) {
-		filter := s.getDateFilter(c)
-		netWorth, err := s.parser.GetNetWorthOverTimeFiltered(filter.StartDate, filter.EndDate)
-		if err != nil {
-			log.Printf("Error getting filtered net worth: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get net worth"})
-			return
-		}
-		c.JSON(http.StatusOK, netWorth)
+// renameCategoryRequest is the body accepted by HandleRenameCategory
+type renameCategoryRequest struct {
+	OldName string `json:"oldName"`
+	NewName string `json:"newName"`
+}
+
+// HandleRenameCategory renames a category in every tier that lists it. Only the settings
+// change; the journal's account names have to be renamed separately.
+func (s *Service) HandleRenameCategory(c *gin.Context) {
+	var req renameCategoryRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rename format"})
 		return
 	}
 
-	// Use cache for unfiltered requests
-	cache, ok := s.getCache()
-	if !ok {
-		c.JSON(http.StatusAccepted, gin.H{"message": "cache empty; refresh required", "needsRefresh": true})
+	err := s.modifySettings(func(settings *config.Settings) error {
+		return settings.RenameCategory(req.OldName, req.NewName)
+	})
+	if errors.Is(err, errSettingsNotSaved) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, cache.NetWorthOverTime)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Cached spending carries tier names, so recompute it
+	s.markCacheStale()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "category renamed in tier settings only; rename the accounts in your journal to match",
+		"tiers":   s.currentSettings().Tiers,
+	})
 }
 
-// HandleCategoryTrends returns spending trends for each category
-func (s *Service) HandleCategoryTrends(c *gin.Context) {
-	// Check if date filtering is requested
-	if s.hasDateFilter(c) {
-		filter := s.getDateFilter(c)
-		categoryTrends, err := s.parser.GetCategoryTrendsFiltered(filter.StartDate, filter.EndDate)
-		if err != nil {
-			log.Printf("Error getting filtered category trends: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get category trends"})
-			return
+// healthCheckTimeout bounds how long the health check waits for hledger
+const healthCheckTimeout = 5 * time.Second
+
+// HandleHealth reports whether hledger runs and the journal is readable, for load balancer checks.
+// It deliberately avoids parsing the journal so it stays cheap to poll.
+func (s *Service) HandleHealth(c *gin.Context) {
+	s.cacheMu.RLock()
+	hasCache := s.cache != nil
+	var lastRefresh interface{}
+	if hasCache {
+		lastRefresh = s.cache.LastRefresh
+	}
+	s.cacheMu.RUnlock()
+
+	version, err := s.parser.Version(healthCheckTimeout)
+	if err == nil {
+		err = s.parser.CheckJournalFiles()
+	}
+	if err != nil {
+		message := err.Error()
+		if errors.Is(err, hledger.ErrHledgerNotFound) {
+			message = hledger.ErrHledgerNotFound.Error()
 		}
-		c.JSON(http.StatusOK, categoryTrends)
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":      "unavailable",
+			"error":       message,
+			"hasCache":    hasCache,
+			"lastRefresh": lastRefresh,
+		})
 		return
 	}
 
-	// Use cache for unfiltered requests
-	cache, ok := s.getCache()
+	c.JSON(http.StatusOK, gin.H{
+		"status":         "ok",
+		"hledgerVersion": version,
+		"hasCache":       hasCache,
+		"lastRefresh":    lastRefresh,
+	})
+}
+
+// ExportData is the document served by HandleExportAll. The JSON field names are part of the
+// export format and must stay stable for external tools.
+type ExportData struct {
+	ExportedAt       time.Time                   `json:"exportedAt"`
+	LastRefresh      time.Time                   `json:"lastRefresh"`
+	Stale            bool                        `json:"stale"`
+	Summary          SummaryData                 `json:"summary"`
+	Accounts         []hledger.Account           `json:"accounts"`
+	Transactions     []hledger.Transaction       `json:"transactions"`
+	Budget           []hledger.BudgetItem        `json:"budget"`
+	BudgetHistory    []hledger.BudgetHistoryItem `json:"budgetHistory"`
+	MonthlyMetrics   []hledger.MonthlyMetrics    `json:"monthlyMetrics"`
+	CategorySpending []hledger.CategorySpending  `json:"categorySpending"`
+	NetWorthOverTime []hledger.NetWorthPoint     `json:"netWorthOverTime"`
+	CategoryTrends   []hledger.CategoryTrendData `json:"categoryTrends"`
+	YearOverYear     []hledger.YearOverYearData  `json:"yearOverYear"`
+	Warnings         []string                    `json:"warnings"` // sections that failed to compute and are empty
+}
+
+// HandleExportAll serves the whole cached dataset as a single JSON download
+func (s *Service) HandleExportAll(c *gin.Context) {
+	cache, ok := s.requireCache(c)
 	if !ok {
-		c.JSON(http.StatusAccepted, gin.H{"message": "cache empty; refresh required", "needsRefresh": true})
 		return
 	}
-	c.JSON(http.StatusOK, cache.CategoryTrends)
+
+	export := ExportData{
+		ExportedAt:       time.Now(),
+		LastRefresh:      cache.LastRefresh,
+		Stale:            cache.Stale,
+		Summary:          cache.Summary,
+		Accounts:         cache.Accounts,
+		Transactions:     cache.Transactions,
+		Budget:           cache.Budget,
+		BudgetHistory:    cache.BudgetHistory,
+		MonthlyMetrics:   cache.MonthlyMetrics,
+		CategorySpending: cache.CategorySpending,
+		NetWorthOverTime: cache.NetWorthOverTime,
+		CategoryTrends:   cache.CategoryTrends,
+		YearOverYear:     cache.YearOverYear,
+		Warnings:         cache.Warnings,
+	}
+
+	filename := fmt.Sprintf("minted-export-%s.json", export.ExportedAt.Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.JSON(http.StatusOK, export)
 }
 
-// HandleYearOverYearComparison returns spending comparison across years
-func (s *Service) HandleYearOverYearComparison(c *gin.Context) {
-	// Check if date filtering is requested
-	if s.hasDateFilter(c) {
-		filter := s.getDateFilter(c)
-		yoyData, err := s.parser.GetYearOverYearComparisonFiltered(filter.StartDate, filter.EndDate)
+// DashboardBundle is the response of HandleDashboardBundle: the data behind the main dashboard
+// in one document. Each list has the same shape as the matching endpoint.
+type DashboardBundle struct {
+	LastRefresh      *time.Time                  `json:"lastRefresh"` // nil for date-filtered bundles, which are computed live
+	Stale            bool                        `json:"stale"`
+	Summary          SummaryData                 `json:"summary"`
+	Accounts         []hledger.Account           `json:"accounts"`
+	Budget           []hledger.BudgetItem        `json:"budget"`
+	BudgetHistory    []hledger.BudgetHistoryItem `json:"budgetHistory"`
+	MonthlyMetrics   []hledger.MonthlyMetrics    `json:"monthlyMetrics"`
+	CategorySpending []hledger.CategorySpending  `json:"categorySpending"`
+	NetWorthOverTime []hledger.NetWorthPoint     `json:"netWorthOverTime"`
+	CategoryTrends   []hledger.CategoryTrendData `json:"categoryTrends"`
+	YearOverYear     []hledger.YearOverYearData  `json:"yearOverYear"`
+	Warnings         []string                    `json:"warnings"` // sections that failed to compute and are null
+}
+
+// HandleDashboardBundle returns everything the dashboard needs on load in a single response,
+// leaving out the transactions. Without a date range it is served from the cache; with one,
+// each part is computed for the range as the individual endpoints would.
+func (s *Service) HandleDashboardBundle(c *gin.Context) {
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		bundle, err := s.filteredBundle(filter)
 		if err != nil {
-			log.Printf("Error getting filtered year-over-year: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get year-over-year comparison"})
+			s.log().Error("Error getting filtered dashboard bundle", "error", err)
+			respondError(c, err, "Failed to get dashboard data")
 			return
 		}
-		c.JSON(http.StatusOK, yoyData)
+		c.JSON(http.StatusOK, bundle)
 		return
 	}
 
-	// Use cache for unfiltered requests
-	cache, ok := s.getCache()
+	cache, ok := s.requireCache(c)
 	if !ok {
-		c.JSON(http.StatusAccepted, gin.H{"message": "cache empty; refresh required", "needsRefresh": true})
 		return
 	}
-	c.JSON(http.StatusOK, cache.YearOverYear)
+	lastRefresh := cache.LastRefresh
+	c.JSON(http.StatusOK, DashboardBundle{
+		LastRefresh:      &lastRefresh,
+		Stale:            cache.Stale,
+		Summary:          cache.Summary,
+		Accounts:         cache.Accounts,
+		Budget:           cache.Budget,
+		BudgetHistory:    cache.BudgetHistory,
+		MonthlyMetrics:   cache.MonthlyMetrics,
+		CategorySpending: cache.CategorySpending,
+		NetWorthOverTime: cache.NetWorthOverTime,
+		CategoryTrends:   cache.CategoryTrends,
+		YearOverYear:     cache.YearOverYear,
+		Warnings:         cache.Warnings,
+	})
 }
 
-// HandleGetSettings returns the current application settings
-func (s *Service) HandleGetSettings(c *gin.Context) {
-	c.JSON(http.StatusOK, s.settings)
-}
+// filteredBundle computes the dashboard bundle for a date range, sharing the filtered result
+// cache with the individual endpoints. The budget compares the current month with the
+// historical averages and so doesn't depend on the range.
+func (s *Service) filteredBundle(filter *DateFilter) (*DashboardBundle, error) {
+	bundle := &DashboardBundle{Warnings: []string{}}
+	var err error
 
-// HandleUpdateSettings updates application settings and saves to disk
-func (s *Service) HandleUpdateSettings(c *gin.Context) {
-	var updatedSettings config.Settings
-	if err := c.BindJSON(&updatedSettings); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid settings format"})
-		return
+	if bundle.Accounts, err = s.parser.GetAccountsFiltered(filter.StartDate, filter.EndDate, 0); err != nil {
+		return nil, err
 	}
-
-	// Update the settings in memory
-	s.settings = &updatedSettings
-
-	// Update the parser's settings so it uses the new tiers
-	s.parser.UpdateSettings(&updatedSettings)
-
-	// Mark cache as stale so the next refresh will recompute with new settings
-	s.cacheMu.Lock()
-	if s.cache != nil {
-		s.cache.Stale = true
+	// Net worth is cumulative, so the summary uses balances up to the end date
+	upToDate, err := s.parser.GetAccountsUpToDate(filter.EndDate)
+	if err != nil {
+		return nil, err
 	}
-	s.cacheMu.Unlock()
+	bundle.Summary = summarizeAccounts(upToDate, s.currentSettings())
 
-	// Save to disk
-	if err := config.SaveSettings(&updatedSettings); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+	if bundle.Budget, err = s.parser.GetBudgetData(); err != nil {
+		return nil, err
+	}
+	if bundle.BudgetHistory, err = cachedFiltered(s, "budget-history", filter, s.parser.GetBudgetHistoryFiltered); err != nil {
+		return nil, err
+	}
+	if bundle.MonthlyMetrics, err = cachedFiltered(s, "monthly-metrics", filter, s.parser.GetMonthlyMetricsFiltered); err != nil {
+		return nil, err
+	}
+	if bundle.CategorySpending, err = cachedFiltered(s, "category-spending", filter, s.parser.GetCategorySpendingFiltered); err != nil {
+		return nil, err
+	}
+	if bundle.NetWorthOverTime, err = cachedFiltered(s, "net-worth", filter, s.parser.GetNetWorthOverTimeFiltered); err != nil {
+		return nil, err
+	}
+	if bundle.CategoryTrends, err = cachedFiltered(s, "category-trends", filter, s.parser.GetCategoryTrendsFiltered); err != nil {
+		return nil, err
+	}
+	if bundle.YearOverYear, err = cachedFiltered(s, "year-over-year", filter, s.parser.GetYearOverYearComparisonFiltered); err != nil {
+		return nil, err
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "settings updated successfully"})
+	return bundle, nil
 }
 
 // HandleCacheStatus returns cache metadata
@@ -547,6 +2343,9 @@ func (s *Service) HandleCacheStatus(c *gin.Context) {
 	s.cacheMu.RLock()
 	defer s.cacheMu.RUnlock()
 
+	ttl := s.cacheTTL()
+	ttlMinutes := int(ttl / time.Minute)
+
 	if s.cache == nil {
 		c.JSON(http.StatusOK, gin.H{
 			"hasCache":     false,
@@ -554,15 +2353,30 @@ func (s *Service) HandleCacheStatus(c *gin.Context) {
 			"lastRefresh":  nil,
 			"stale":        false,
 			"needsRefresh": true,
+			"ttlMinutes":   ttlMinutes,
+			"nextRefresh":  nil,
 		})
 		return
 	}
 
+	var nextRefresh interface{}
+	stale := s.cache.Stale
+	if ttl > 0 {
+		next := s.cache.LastRefresh.Add(ttl)
+		nextRefresh = next
+		if !time.Now().Before(next) {
+			stale = true
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"hasCache":    true,
 		"inProgress":  s.cacheRefreshing,
 		"lastRefresh": s.cache.LastRefresh,
-		"stale":       s.cache.Stale,
+		"stale":       stale,
+		"ttlMinutes":  ttlMinutes,
+		"nextRefresh": nextRefresh,
+		"warnings":    s.cache.Warnings,
 	})
 }
 
@@ -573,11 +2387,14 @@ func (s *Service) HandleCacheRefresh(c *gin.Context) {
 			c.JSON(http.StatusAccepted, gin.H{"message": "refresh already in progress", "inProgress": true})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err, err.Error())
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "cache rebuilt", "lastRefresh": time.Now()})
+	s.cacheMu.RLock()
+	warnings := s.cache.Warnings
+	s.cacheMu.RUnlock()
+	c.JSON(http.StatusOK, gin.H{"message": "cache rebuilt", "lastRefresh": time.Now(), "warnings": warnings})
 }
 
 // HandleCategoryDetail returns detailed view for a specific category
@@ -588,18 +2405,24 @@ func (s *Service) HandleCategoryDetail(c *gin.Context) {
 		return
 	}
 
+	// Optional case-insensitive match on posting comments, which include tags
+	commentFilter := c.Query("filter")
+
 	var detail interface{}
-	var err error
 
-	if s.hasDateFilter(c) {
-		filter := s.getDateFilter(c)
-		detail, err = s.parser.GetCategoryDetailFiltered(category, filter.StartDate, filter.EndDate)
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		detail, err = s.parser.GetCategoryDetailFiltered(category, commentFilter, filter.StartDate, filter.EndDate)
 	} else {
-		detail, err = s.parser.GetCategoryDetail(category)
+		detail, err = s.parser.GetCategoryDetail(category, commentFilter)
 	}
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err, err.Error())
 		return
 	}
 
@@ -615,17 +2438,20 @@ func (s *Service) HandleTierDetail(c *gin.Context) {
 	}
 
 	var detail interface{}
-	var err error
 
-	if s.hasDateFilter(c) {
-		filter := s.getDateFilter(c)
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
 		detail, err = s.parser.GetTierDetailFiltered(tier, filter.StartDate, filter.EndDate)
 	} else {
 		detail, err = s.parser.GetTierDetail(tier)
 	}
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err, err.Error())
 		return
 	}
 
@@ -646,23 +2472,83 @@ func (s *Service) HandleAccountDetail(c *gin.Context) {
 	}
 
 	var detail interface{}
-	var err error
 
-	if s.hasDateFilter(c) {
-		filter := s.getDateFilter(c)
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
 		detail, err = s.parser.GetAccountDetailFiltered(account, filter.StartDate, filter.EndDate)
 	} else {
 		detail, err = s.parser.GetAccountDetail(account)
 	}
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err, err.Error())
 		return
 	}
 
 	c.JSON(http.StatusOK, detail)
 }
 
+// HandleAccountBalanceHistory returns an account's daily closing balance, including the
+// balance carried in from before the date range
+func (s *Service) HandleAccountBalanceHistory(c *gin.Context) {
+	account := c.Query("account")
+	if account == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "account parameter required"})
+		return
+	}
+
+	var startDate, endDate string
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
+		startDate, endDate = filter.StartDate, filter.EndDate
+	}
+
+	history, err := s.parser.GetAccountBalanceHistory(account, startDate, endDate)
+	if err != nil {
+		s.log().Error("Error getting account balance history", "error", err)
+		respondError(c, err, "Failed to get account balance history")
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// HandleAccountBalanceAsOf returns an account's balance at the end of the date param, which may
+// be a literal date or a relative keyword
+func (s *Service) HandleAccountBalanceAsOf(c *gin.Context) {
+	account := c.Query("account")
+	if account == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "account parameter required"})
+		return
+	}
+	if c.Query("date") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date parameter required"})
+		return
+	}
+	date, err := s.resolveDate(c.Query("date"), true)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid date %q: %v", c.Query("date"), err)})
+		return
+	}
+
+	balance, err := s.parser.GetAccountBalanceAsOf(account, date)
+	if err != nil {
+		s.log().Error("Error getting account balance as of date", "account", account, "date", date, "error", err)
+		respondError(c, err, "Failed to get account balance")
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
 // HandleIncomeDetail returns detailed view for a specific income category
 func (s *Service) HandleIncomeDetail(c *gin.Context) {
 	income := c.Query("income")
@@ -672,17 +2558,20 @@ func (s *Service) HandleIncomeDetail(c *gin.Context) {
 	}
 
 	var detail interface{}
-	var err error
 
-	if s.hasDateFilter(c) {
-		filter := s.getDateFilter(c)
+	filter, err := s.getDateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter != nil {
 		detail, err = s.parser.GetIncomeDetailFiltered(income, filter.StartDate, filter.EndDate)
 	} else {
 		detail, err = s.parser.GetIncomeDetail(income)
 	}
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err, err.Error())
 		return
 	}
 
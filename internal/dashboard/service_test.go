@@ -0,0 +1,559 @@
+package dashboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cwj5/minted/internal/config"
+	"github.com/cwj5/minted/internal/hledger"
+	"github.com/gin-gonic/gin"
+)
+
+// TestSettingsConcurrentReadWrite hammers the settings handlers from several goroutines; run it
+// with -race to catch unsynchronized access
+func TestSettingsConcurrentReadWrite(t *testing.T) {
+	fakeHledger(t, nil)
+	s := newTestService(t, config.DefaultSettings())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			settings := config.DefaultSettings()
+			settings.Preferences["currencySymbol"] = fmt.Sprintf("C%d", i)
+			body, _ := json.Marshal(settings)
+			for j := 0; j < 10; j++ {
+				if w := serve(s.HandleUpdateSettings, http.MethodPost, "/api/settings", bytes.NewReader(body)); w.Code != http.StatusOK {
+					t.Errorf("update: status %d: %s", w.Code, w.Body.String())
+					return
+				}
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if w := serve(s.HandleGetSettings, http.MethodGet, "/api/settings", nil); w.Code != http.StatusOK {
+					t.Errorf("get: status %d", w.Code)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Whatever update won, memory and disk must agree
+	saved, err := config.LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := saved.CurrencySymbol(), s.currentSettings().CurrencySymbol(); got != want {
+		t.Errorf("saved currency symbol %q, running %q", got, want)
+	}
+}
+
+func TestUpdateSettingsKeepsRunningSettingsWhenSaveFails(t *testing.T) {
+	fakeHledger(t, nil)
+	s := newTestService(t, config.DefaultSettings())
+
+	// A regular file where the config directory should be makes every save fail
+	blocked := t.TempDir() + "/file"
+	if err := os.WriteFile(blocked, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MINTED_DIR", blocked)
+
+	updated := config.DefaultSettings()
+	updated.Preferences["currencySymbol"] = "€"
+	body, _ := json.Marshal(updated)
+	w := serve(s.HandleUpdateSettings, http.MethodPost, "/api/settings", bytes.NewReader(body))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d, want 500: %s", w.Code, w.Body.String())
+	}
+	if got := s.currentSettings().CurrencySymbol(); got != "$" {
+		t.Errorf("running currency symbol %q after failed save, want $", got)
+	}
+}
+
+func TestStaleCacheResponsesAreFlagged(t *testing.T) {
+	fakeHledger(t, map[string]string{"print": "[]", "balance": "[[],[]]"})
+	s := newTestService(t, config.DefaultSettings())
+
+	if w := serve(s.HandleAccounts, http.MethodGet, "/api/accounts", nil); w.Header().Get("X-Cache-Stale") != "" {
+		t.Errorf("fresh cache flagged stale")
+	}
+
+	s.markCacheStale()
+	w := serve(s.HandleAccounts, http.MethodGet, "/api/accounts", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("X-Cache-Stale"); got != "true" {
+		t.Errorf("X-Cache-Stale = %q for stale cache, want true", got)
+	}
+}
+
+func TestTransactionsTagFilter(t *testing.T) {
+	flight := txn("2024-05-03", "flight", posting("expenses:travel", 300), posting("assets:checking", -300))
+	flight.Tags = hledger.Tags{"trip": "lisbon", "project": "talk"}
+	hotel := txn("2024-05-04", "hotel", posting("expenses:travel", 200), posting("assets:checking", -200))
+	hotel.Postings[0].Tags = hledger.Tags{"trip": "porto"}
+	groceries := txn("2024-05-05", "groceries", posting("expenses:food", 50), posting("assets:checking", -50))
+	fakeHledger(t, map[string]string{"print": printJSON(t, flight, hotel, groceries)})
+	s := newTestService(t, config.DefaultSettings())
+
+	tests := []struct {
+		tag  string
+		want []string
+	}{
+		{"trip", []string{"flight", "hotel"}},
+		{"trip=lisbon", []string{"flight"}},
+		{"trip=porto", []string{"hotel"}}, // posting tag
+		{"project=talk", []string{"flight"}},
+		{"client", nil},
+	}
+	for _, tt := range tests {
+		w := serve(s.HandleTransactions, http.MethodGet, "/api/transactions?startDate=2024-05-01&order=asc&tag="+tt.tag, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("tag=%s: status %d: %s", tt.tag, w.Code, w.Body.String())
+		}
+		var got []hledger.Transaction
+		decodeBody(t, w, &got)
+		var descriptions []string
+		for _, tx := range got {
+			descriptions = append(descriptions, tx.Description)
+		}
+		if !reflect.DeepEqual(descriptions, tt.want) {
+			t.Errorf("tag=%s: got %v, want %v", tt.tag, descriptions, tt.want)
+		}
+	}
+}
+
+func TestAccountsDepthParam(t *testing.T) {
+	tests := []struct {
+		query    string
+		wantCode int
+	}{
+		{"depth=2", http.StatusOK},
+		{"depth=0", http.StatusOK},
+		{"depth=-1", http.StatusBadRequest},
+		{"depth=two", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		fakeHledger(t, map[string]string{"print": "[]", "balance": "[[],[]]"})
+		s := newTestService(t, config.DefaultSettings())
+		if w := serve(s.HandleAccounts, http.MethodGet, "/api/accounts?"+tt.query, nil); w.Code != tt.wantCode {
+			t.Errorf("%s: status %d, want %d: %s", tt.query, w.Code, tt.wantCode, w.Body.String())
+		}
+	}
+}
+
+func TestEmptyJournalPipeline(t *testing.T) {
+	// What hledger prints for a journal without transactions
+	fakeHledger(t, map[string]string{"print": "[]", "balance": "[[],[]]", "register": "[]"})
+	s := newTestService(t, config.DefaultSettings())
+
+	if err := s.RebuildCache(); err != nil {
+		t.Fatalf("RebuildCache: %v", err)
+	}
+	if warnings := s.cache.Warnings; len(warnings) != 0 {
+		t.Errorf("cache warnings %v, want none", warnings)
+	}
+
+	handlers := map[string]gin.HandlerFunc{
+		"accounts":            s.HandleAccounts,
+		"accounts/tree":       s.HandleAccountTree,
+		"transactions":        s.HandleTransactions,
+		"budget":              s.HandleBudgetComparison,
+		"budget-history":      s.HandleBudgetHistory,
+		"monthly-metrics":     s.HandleMonthlyMetrics,
+		"category-spending":   s.HandleCategorySpending,
+		"net-worth-over-time": s.HandleNetWorthOverTime,
+		"category-trends":     s.HandleCategoryTrends,
+		"year-over-year":      s.HandleYearOverYearComparison,
+		"income-history":      s.HandleIncomeHistory,
+		"recurring":           s.HandleRecurring,
+		"duplicates":          s.HandleDuplicates,
+		"categories":          s.HandleCategories,
+	}
+	for name, handler := range handlers {
+		w := serve(handler, http.MethodGet, "/api/"+name, nil)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status %d: %s", name, w.Code, w.Body.String())
+			continue
+		}
+		if body := strings.TrimSpace(w.Body.String()); !strings.HasPrefix(body, "[") {
+			t.Errorf("%s: body %s, want an empty list", name, body)
+		}
+	}
+
+	w := serve(s.HandleSummary, http.MethodGet, "/api/summary", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("summary: status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTransactionsStatusFilter(t *testing.T) {
+	cleared := txn("2024-05-01", "rent", posting("expenses:rent", 1000), posting("assets:checking", -1000))
+	pending := txn("2024-05-02", "card", posting("expenses:food", 30), posting("liabilities:card", -30))
+	pending.Status = hledger.StatusPending
+	unmarked := txn("2024-05-03", "cash", posting("expenses:food", 10), posting("assets:cash", -10))
+	unmarked.Status = hledger.StatusUnmarked
+	fakeHledger(t, map[string]string{"print": printJSON(t, cleared, pending, unmarked)})
+	s := newTestService(t, config.DefaultSettings())
+
+	tests := []struct {
+		status   string
+		wantCode int
+		want     []string
+	}{
+		{"", http.StatusOK, []string{"rent", "card", "cash"}},
+		{"all", http.StatusOK, []string{"rent", "card", "cash"}},
+		{"cleared", http.StatusOK, []string{"rent"}},
+		{"Pending", http.StatusOK, []string{"card"}},
+		{"unmarked", http.StatusOK, []string{"cash"}},
+		{"reconciled", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		w := serve(s.HandleTransactions, http.MethodGet, "/api/transactions?startDate=2024-05-01&order=asc&status="+tt.status, nil)
+		if w.Code != tt.wantCode {
+			t.Errorf("status=%s: code %d, want %d: %s", tt.status, w.Code, tt.wantCode, w.Body.String())
+			continue
+		}
+		if tt.wantCode != http.StatusOK {
+			continue
+		}
+		var got []hledger.Transaction
+		decodeBody(t, w, &got)
+		var descriptions []string
+		for _, tx := range got {
+			descriptions = append(descriptions, tx.Description)
+		}
+		if !reflect.DeepEqual(descriptions, tt.want) {
+			t.Errorf("status=%s: got %v, want %v", tt.status, descriptions, tt.want)
+		}
+	}
+}
+
+func TestCategoryTrendsSmoothParam(t *testing.T) {
+	fakeHledger(t, map[string]string{"print": printJSON(t,
+		txn("2024-04-03", "market", posting("expenses:travel", 100), posting("assets:checking", -100)),
+		txn("2024-05-03", "market", posting("expenses:travel", 300), posting("assets:checking", -300)),
+	), "balance": "[[],[]]"})
+	s := newTestService(t, config.DefaultSettings())
+
+	tests := []struct {
+		query        string
+		wantCode     int
+		wantSmoothed []float64 // nil when points carry no smoothed amount
+	}{
+		{"", http.StatusOK, nil},
+		{"smooth=2", http.StatusOK, []float64{100, 200}},
+		{"smooth=0", http.StatusBadRequest, nil},
+		{"smooth=x", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		w := serve(s.HandleCategoryTrends, http.MethodGet, "/api/category-trends?"+tt.query, nil)
+		if w.Code != tt.wantCode {
+			t.Errorf("%s: status %d, want %d: %s", tt.query, w.Code, tt.wantCode, w.Body.String())
+			continue
+		}
+		if tt.wantCode != http.StatusOK {
+			continue
+		}
+		var trends []hledger.CategoryTrendData
+		decodeBody(t, w, &trends)
+		if len(trends) != 1 || len(trends[0].Data) != 2 {
+			t.Fatalf("%s: trends %+v, want one series of two months", tt.query, trends)
+		}
+		for i, point := range trends[0].Data {
+			if tt.wantSmoothed == nil {
+				if point.SmoothedAmount != nil {
+					t.Errorf("%s: point %d has smoothed amount %v", tt.query, i, *point.SmoothedAmount)
+				}
+			} else if point.SmoothedAmount == nil || *point.SmoothedAmount != tt.wantSmoothed[i] {
+				t.Errorf("%s: point %d smoothed %v, want %v", tt.query, i, point.SmoothedAmount, tt.wantSmoothed[i])
+			}
+		}
+	}
+}
+
+// transactionDescriptions fetches /api/transactions with query and returns the descriptions
+func transactionDescriptions(t *testing.T, s *Service, query string) (int, []string) {
+	t.Helper()
+	w := serve(s.HandleTransactions, http.MethodGet, "/api/transactions?"+query, nil)
+	if w.Code != http.StatusOK {
+		return w.Code, nil
+	}
+	var got []hledger.Transaction
+	decodeBody(t, w, &got)
+	descriptions := []string{}
+	for _, tx := range got {
+		descriptions = append(descriptions, tx.Description)
+	}
+	return w.Code, descriptions
+}
+
+func TestTransactionsAmountFilter(t *testing.T) {
+	fakeHledger(t, map[string]string{"print": printJSON(t,
+		txn("2024-05-01", "rent", posting("expenses:rent", 600), posting("assets:checking", -600)),
+		// Largest posting 400, but it moves 600 in total
+		txn("2024-05-02", "split", posting("expenses:food", 400), posting("expenses:household", 200),
+			posting("assets:checking", -300), posting("liabilities:card", -300)),
+		txn("2024-05-03", "coffee", posting("expenses:food", 50), posting("assets:checking", -50)),
+	)})
+	s := newTestService(t, config.DefaultSettings())
+
+	tests := []struct {
+		query    string
+		wantCode int
+		want     []string
+	}{
+		{"minAmount=500", http.StatusOK, []string{"rent"}},
+		{"minAmount=500&amountBy=total", http.StatusOK, []string{"rent", "split"}},
+		{"maxAmount=100", http.StatusOK, []string{"coffee"}},
+		{"minAmount=100&maxAmount=500", http.StatusOK, []string{"split"}},
+		{"minAmount=100&maxAmount=500&amountBy=total", http.StatusOK, []string{}},
+		{"minAmount=50&maxAmount=50", http.StatusOK, []string{"coffee"}}, // bounds are inclusive
+		{"minAmount=lots", http.StatusBadRequest, nil},
+		{"maxAmount=1&amountBy=average", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		code, got := transactionDescriptions(t, s, "startDate=2024-05-01&order=asc&"+tt.query)
+		if code != tt.wantCode {
+			t.Errorf("%s: status %d, want %d", tt.query, code, tt.wantCode)
+			continue
+		}
+		if code == http.StatusOK && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestTransactionsSort(t *testing.T) {
+	fakeHledger(t, map[string]string{"print": printJSON(t,
+		txn("2024-05-02", "Bakery", posting("expenses:food", 20), posting("assets:checking", -20)),
+		txn("2024-05-01", "rent", posting("expenses:rent", 900), posting("assets:checking", -900)),
+		txn("2024-05-02", "cinema", posting("expenses:fun", 20), posting("assets:checking", -20)),
+		txn("2024-05-03", "salary", posting("income:salary", -2000), posting("assets:checking", 2000)),
+	)})
+	s := newTestService(t, config.DefaultSettings())
+
+	// Ties (the two 2024-05-02 entries, the two 20s) keep journal order in both directions
+	tests := []struct {
+		query    string
+		wantCode int
+		want     []string
+	}{
+		{"", http.StatusOK, []string{"salary", "Bakery", "cinema", "rent"}}, // date, descending
+		{"sort=date&order=asc", http.StatusOK, []string{"rent", "Bakery", "cinema", "salary"}},
+		{"sort=date&order=desc", http.StatusOK, []string{"salary", "Bakery", "cinema", "rent"}},
+		{"sort=amount&order=asc", http.StatusOK, []string{"Bakery", "cinema", "rent", "salary"}},
+		{"sort=amount&order=desc", http.StatusOK, []string{"salary", "rent", "Bakery", "cinema"}},
+		{"sort=description&order=asc", http.StatusOK, []string{"Bakery", "cinema", "rent", "salary"}},
+		{"sort=description&order=desc", http.StatusOK, []string{"salary", "rent", "cinema", "Bakery"}},
+		{"sort=size", http.StatusBadRequest, nil},
+		{"order=up", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		code, got := transactionDescriptions(t, s, "startDate=2024-05-01&"+tt.query)
+		if code != tt.wantCode {
+			t.Errorf("%s: status %d, want %d", tt.query, code, tt.wantCode)
+			continue
+		}
+		if code == http.StatusOK && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSortTransactionsLeavesInputAlone(t *testing.T) {
+	input := []hledger.Transaction{
+		{Index: 1, Date: "2024-05-01", Description: "a"},
+		{Index: 2, Date: "2024-05-03", Description: "b"},
+	}
+	original := append([]hledger.Transaction(nil), input...)
+	sortTransactions(input, "date", true)
+	if !reflect.DeepEqual(input, original) {
+		t.Errorf("sortTransactions reordered its input to %+v", input)
+	}
+}
+
+func TestRebuildCacheKeepsSectionsThatSucceed(t *testing.T) {
+	dir := fakeHledger(t, map[string]string{"print": printJSON(t,
+		txn("2024-05-03", "market", posting("expenses:food", 40), posting("assets:checking", -40)),
+	)})
+	// Balance reports fail, which only the accounts section depends on
+	if err := os.WriteFile(filepath.Join(dir, "balance.fail"), []byte("hledger: balance exploded"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := newTestService(t, config.DefaultSettings())
+	if err := s.RebuildCache(); err != nil {
+		t.Fatalf("RebuildCache: %v", err)
+	}
+
+	warnings := s.cache.Warnings
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "accounts: ") {
+		t.Errorf("warnings %v, want one for accounts", warnings)
+	}
+
+	tests := []struct {
+		name     string
+		handler  gin.HandlerFunc
+		wantCode int
+	}{
+		{"accounts", s.HandleAccounts, http.StatusInternalServerError},
+		{"transactions", s.HandleTransactions, http.StatusOK},
+		{"category-spending", s.HandleCategorySpending, http.StatusOK},
+		{"budget-history", s.HandleBudgetHistory, http.StatusOK},
+		{"net-worth-over-time", s.HandleNetWorthOverTime, http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := serve(tt.handler, http.MethodGet, "/api/"+tt.name, nil)
+		if w.Code != tt.wantCode {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, w.Code, tt.wantCode, w.Body.String())
+		}
+		if tt.wantCode != http.StatusOK && !strings.Contains(w.Body.String(), "accounts could not be computed") {
+			t.Errorf("%s: body %s doesn't explain the failed section", tt.name, w.Body.String())
+		}
+	}
+}
+
+func TestSummaryNetWorthAccounts(t *testing.T) {
+	accounts := []hledger.Account{
+		{Name: "assets:checking", Balance: 5000},
+		{Name: "assets:house", Balance: 200000},
+		{Name: "liabilities:mortgage", Balance: -150000},
+		{Name: "equity:opening", Balance: -55000},
+	}
+	tests := []struct {
+		name     string
+		accounts config.NetWorthAccounts
+		want     SummaryData
+	}{
+		{"assets and liabilities by default", config.NetWorthAccounts{}, SummaryData{TotalAssets: 205000, TotalLiabilities: 150000, NetWorth: 55000}},
+		{"equity included", config.NetWorthAccounts{Include: []string{"assets:", "liabilities:", "equity:"}}, SummaryData{TotalAssets: 205000, TotalLiabilities: 150000, NetWorth: 0}},
+		{"house excluded", config.NetWorthAccounts{Exclude: []string{"assets:house"}}, SummaryData{TotalAssets: 5000, TotalLiabilities: 150000, NetWorth: -145000}},
+	}
+	for _, tt := range tests {
+		settings := config.DefaultSettings()
+		settings.NetWorthAccounts = tt.accounts
+		if got := summarizeAccounts(accounts, settings); got != tt.want {
+			t.Errorf("%s: summary %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCategoryBreakdownMinPercentParam(t *testing.T) {
+	fakeHledger(t, map[string]string{"print": printJSON(t,
+		txn("2024-05-01", "rent", posting("expenses:rent", 900), posting("assets:checking", -900)),
+		txn("2024-05-02", "coffee", posting("expenses:coffee", 60), posting("assets:checking", -60)),
+		txn("2024-05-03", "books", posting("expenses:books", 40), posting("assets:checking", -40)),
+	)})
+	s := newTestService(t, config.DefaultSettings())
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantNames  []string
+	}{
+		{"", http.StatusOK, []string{"rent", "coffee", "books"}},
+		{"?minPercent=10", http.StatusOK, []string{"rent", hledger.OtherCategory}},
+		{"?minPercent=abc", http.StatusBadRequest, nil},
+		{"?minPercent=101", http.StatusBadRequest, nil},
+		{"?type=assets", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		w := serve(s.HandleCategoryBreakdown, http.MethodGet, "/api/category-breakdown"+tt.query, nil)
+		if w.Code != tt.wantStatus {
+			t.Errorf("%q: status %d, want %d: %s", tt.query, w.Code, tt.wantStatus, w.Body.String())
+			continue
+		}
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+		var shares []hledger.CategoryShare
+		decodeBody(t, w, &shares)
+		names := []string{}
+		for _, share := range shares {
+			names = append(names, share.Category)
+		}
+		if !reflect.DeepEqual(names, tt.wantNames) {
+			t.Errorf("%q: categories %v, want %v", tt.query, names, tt.wantNames)
+		}
+	}
+}
+
+func TestJournalPathFollowsSettingsVariable(t *testing.T) {
+	dir := fakeHledger(t, map[string]string{"print": "[]", "balance": "[[],[]]", "register": "[]"})
+	t.Setenv("MINTED_DIR", t.TempDir())
+	t.Setenv("JOURNALS", "/data")
+	gin.SetMode(gin.TestMode)
+
+	// journalFiles returns the -f arguments of every hledger run since the last call
+	journalFiles := func(t *testing.T) []string {
+		t.Helper()
+		logPath := filepath.Join(dir, "args.log")
+		data, err := os.ReadFile(logPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Remove(logPath)
+		seen := map[string]bool{}
+		files := []string{}
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			args := strings.Fields(line)
+			for i := 0; i+1 < len(args); i++ {
+				if args[i] == "-f" && !seen[args[i+1]] {
+					seen[args[i+1]] = true
+					files = append(files, args[i+1])
+				}
+			}
+		}
+		return files
+	}
+	update := func(t *testing.T, s *Service, hledgerFile string) {
+		t.Helper()
+		settings := config.DefaultSettings()
+		settings.Variables["HLEDGER_FILE"] = hledgerFile
+		body, _ := json.Marshal(settings)
+		if w := serve(s.HandleUpdateSettings, http.MethodPost, "/api/settings", bytes.NewReader(body)); w.Code != http.StatusOK {
+			t.Fatalf("update: status %d: %s", w.Code, w.Body.String())
+		}
+		os.Remove(filepath.Join(dir, "args.log")) // drop the runs of any rebuild the update started
+		if err := s.RebuildCache(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("from settings", func(t *testing.T) {
+		settings := config.DefaultSettings()
+		settings.Variables["HLEDGER_FILE"] = "$JOURNALS/old.journal"
+		s := NewServiceWithFiles(nil, settings)
+		if got, want := journalFiles(t), []string{"/data/old.journal"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("journal files %v, want %v", got, want)
+		}
+
+		update(t, s, "$JOURNALS/new.journal"+string(os.PathListSeparator)+"$JOURNALS/prices.journal")
+		if got, want := journalFiles(t), []string{"/data/new.journal", "/data/prices.journal"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("journal files after the update %v, want %v", got, want)
+		}
+	})
+
+	t.Run("explicit journal", func(t *testing.T) {
+		os.Remove(filepath.Join(dir, "args.log"))
+		s := NewService("explicit.journal", config.DefaultSettings())
+		update(t, s, "$JOURNALS/new.journal")
+		if got, want := journalFiles(t), []string{"explicit.journal"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("journal files after the update %v, want the explicit %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,266 @@
+package hledger
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AccountNode represents an account in the account hierarchy.
+// Balance includes postings made directly to the account plus all of its children.
+type AccountNode struct {
+	Name     string        `json:"name"`
+	FullName string        `json:"fullName"`
+	Balance  float64       `json:"balance"`
+	Currency string        `json:"currency"`
+	Children []AccountNode `json:"children"`
+}
+
+// accountTreeNode is the mutable node used while building the account tree
+type accountTreeNode struct {
+	fullName string
+	balance  float64 // the account's own balance
+	total    float64 // balance plus all descendants, unrounded; set by sumTotals
+	currency string
+	children map[string]*accountTreeNode
+}
+
+// GetAccountTree returns all accounts within a date range as a nested hierarchy
+func (p *Parser) GetAccountTree(startDate, endDate string) ([]AccountNode, error) {
+	accounts, err := p.GetAccountsFiltered(startDate, endDate, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.buildAccountTree(accounts), nil
+}
+
+// buildAccountTree nests flat accounts by splitting their names on ":".
+// hledger's flat balance rows exclude subaccounts, so each row is the account's own balance.
+func (p *Parser) buildAccountTree(accounts []Account) []AccountNode {
+	root := &accountTreeNode{children: make(map[string]*accountTreeNode)}
+
+	for _, account := range accounts {
+		parts := strings.Split(account.Name, ":")
+		node := root
+		for i, part := range parts {
+			child, ok := node.children[part]
+			if !ok {
+				child = &accountTreeNode{
+					fullName: strings.Join(parts[:i+1], ":"),
+					children: make(map[string]*accountTreeNode),
+				}
+				node.children[part] = child
+			}
+			node = child
+		}
+
+		node.balance += account.Balance
+		if node.currency == "" {
+			node.currency = account.Currency
+		}
+	}
+
+	root.sumTotals()
+	return root.toNodes(p.decimalsFor)
+}
+
+// sumTotals sets each node's total from the raw balances below it, and takes the currency of
+// its first child with one when the node has no balance of its own. Summing before rounding
+// keeps a parent from accumulating its children's rounding error.
+func (n *accountTreeNode) sumTotals() {
+	n.total = n.balance
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		child := n.children[name]
+		child.sumTotals()
+		n.total += child.total
+		if n.currency == "" {
+			n.currency = child.currency
+		}
+	}
+}
+
+// toNodes converts the node's children into sorted AccountNodes, rounding each total once to
+// the decimal places of its commodity
+func (n *accountTreeNode) toNodes(decimals func(commodity string) int) []AccountNode {
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	nodes := []AccountNode{}
+	for _, name := range names {
+		child := n.children[name]
+		nodes = append(nodes, AccountNode{
+			Name:     name,
+			FullName: child.fullName,
+			Balance:  roundTo(child.total, decimals(child.currency)),
+			Currency: child.currency,
+			Children: child.toNodes(decimals),
+		})
+	}
+
+	return nodes
+}
+
+// InactiveAccount represents an account without recent postings
+type InactiveAccount struct {
+	Account      string  `json:"account"`
+	Balance      float64 `json:"balance"`
+	Currency     string  `json:"currency"`
+	LastActivity string  `json:"lastActivity"` // date of the latest posting, "" when never used
+	NeverUsed    bool    `json:"neverUsed"`
+}
+
+// GetInactiveAccounts returns asset and liability accounts whose latest posting is more than
+// sinceMonths months old, oldest activity first, followed by accounts that were never posted
+// to. Postings to subaccounts count as activity of their parents.
+func (p *Parser) GetInactiveAccounts(sinceMonths int) ([]InactiveAccount, error) {
+	accounts, err := p.GetAccounts()
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := p.GetTransactions()
+	if err != nil {
+		return nil, err
+	}
+
+	// Single pass: credit each posting's date to its account and every ancestor
+	lastActivity := make(map[string]string)
+	for _, tx := range transactions {
+		for _, posting := range tx.Postings {
+			name := posting.Account
+			for {
+				if tx.Date > lastActivity[name] {
+					lastActivity[name] = tx.Date
+				}
+				i := strings.LastIndex(name, ":")
+				if i < 0 {
+					break
+				}
+				name = name[:i]
+			}
+		}
+	}
+
+	cutoff := p.Now().AddDate(0, -sinceMonths, 0).Format("2006-01-02")
+
+	inactive := []InactiveAccount{}
+	for _, account := range accounts {
+		last := lastActivity[account.Name]
+		if last != "" && last >= cutoff {
+			continue
+		}
+		inactive = append(inactive, InactiveAccount{
+			Account:      account.Name,
+			Balance:      account.Balance,
+			Currency:     account.Currency,
+			LastActivity: last,
+			NeverUsed:    last == "",
+		})
+	}
+
+	sort.Slice(inactive, func(i, j int) bool {
+		if inactive[i].NeverUsed != inactive[j].NeverUsed {
+			return !inactive[i].NeverUsed
+		}
+		if inactive[i].LastActivity != inactive[j].LastActivity {
+			return inactive[i].LastActivity < inactive[j].LastActivity
+		}
+		return inactive[i].Account < inactive[j].Account
+	})
+
+	return inactive, nil
+}
+
+// GetAccountBalanceHistory returns the account's balance after each day with postings in the
+// date range. hledger register runs in historical mode, so the balance carries in everything
+// posted before startDate rather than starting from zero at the first in-window posting. Only
+// postings to the account itself count, matching GetAccountDetail.
+func (p *Parser) GetAccountBalanceHistory(account, startDate, endDate string) ([]BalanceHistoryPoint, error) {
+	query := "acct:^" + regexp.QuoteMeta(account) + "$"
+	args := append(p.fileArgs(), "register", query, "--historical", "-O", "json")
+	args = append(args, p.buildDateArgs(startDate, endDate)...)
+	args = append(args, p.valuationArgs()...)
+	args = append(args, p.statusArgs()...)
+	args = append(args, p.realArgs()...)
+
+	cmd := exec.Command("hledger", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		p.logCommandError(cmd, err)
+		return nil, wrapExecError(err)
+	}
+
+	// Register JSON rows: [date, date2, description, posting, running total]. The date is null
+	// on later postings of the same transaction, so the last seen date is carried forward.
+	var rows [][]interface{}
+	if err := decodeJSON(output, &rows); err != nil {
+		p.log().Error("Error parsing hledger JSON", "args", cmd.Args[1:], "error", err)
+		return nil, err
+	}
+
+	history := []BalanceHistoryPoint{}
+	date := ""
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		if d, ok := row[0].(string); ok && d != "" {
+			date = d
+		}
+		if date == "" {
+			continue
+		}
+		total, _ := row[len(row)-1].([]interface{})
+		balance := p.roundAmount(firstAmountValue(total))
+
+		// Rows are in date order; keep the closing balance of each day
+		if n := len(history); n > 0 && history[n-1].Date == date {
+			history[n-1].Balance = balance
+			continue
+		}
+		history = append(history, BalanceHistoryPoint{Date: date, Balance: balance})
+	}
+
+	return history, nil
+}
+
+// AccountBalanceAsOf is an account's balance at the end of a given day
+type AccountBalanceAsOf struct {
+	Account   string  `json:"account"`
+	Date      string  `json:"date"`
+	Balance   float64 `json:"balance"`
+	Commodity string  `json:"commodity"`
+}
+
+// GetAccountBalanceAsOf returns the balance of an account and its subaccounts at the end of
+// date (YYYY-MM-DD), including that day's postings. hledger's end date is exclusive, so the
+// query ends the day after.
+func (p *Parser) GetAccountBalanceAsOf(account, date string) (*AccountBalanceAsOf, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: %v", date, err)
+	}
+
+	total, err := p.accountTotal(account, day.AddDate(0, 0, 1).Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountBalanceAsOf{
+		Account:   account,
+		Date:      date,
+		Balance:   p.roundAmount(firstAmountValue(total)),
+		Commodity: firstAmountCommodity(total),
+	}, nil
+}
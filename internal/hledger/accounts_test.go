@@ -0,0 +1,115 @@
+package hledger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cwj5/minted/internal/config"
+)
+
+// registerRow is one posting of hledger register output with the running total after it. An
+// empty date stands for a later posting of the same transaction, which hledger reports as null.
+type registerRow struct {
+	date          string
+	amount, total float64
+}
+
+// registerJSON encodes checking account rows the way hledger register -O json does
+func registerJSON(t *testing.T, rows ...registerRow) string {
+	t.Helper()
+	encoded := []any{}
+	for _, row := range rows {
+		var date any
+		if row.date != "" {
+			date = row.date
+		}
+		encoded = append(encoded, []any{date, nil, "entry", posting("assets:checking", row.amount), usd(row.total)})
+	}
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestAccountBalanceHistoryCarriesOpeningBalance(t *testing.T) {
+	// 1400 was in checking before May; historical register totals include it
+	dir := fakeHledger(t, map[string]string{"register": registerJSON(t,
+		registerRow{"2024-05-03", 100, 1500},
+		registerRow{"2024-05-03", -50, 1450},
+		registerRow{"2024-05-10", 200, 1650},
+		registerRow{"", -25, 1625},
+	)})
+	p := NewParser("test.journal", config.DefaultSettings())
+
+	history, err := p.GetAccountBalanceHistory("assets:checking", "2024-05-01", "2024-06-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []BalanceHistoryPoint{
+		{Date: "2024-05-03", Balance: 1450}, // closing balance of the day, opening balance included
+		{Date: "2024-05-10", Balance: 1625}, // the null-dated posting belongs to 2024-05-10
+	}
+	if !reflect.DeepEqual(history, want) {
+		t.Errorf("history %+v, want %+v", history, want)
+	}
+
+	args := hledgerArgs(t, dir)[0]
+	for _, want := range []string{"register", "acct:^assets:checking$", "--historical", "-b 2024-05-01", "-e 2024-06-01"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("hledger args %q, want %s", args, want)
+		}
+	}
+}
+
+func TestAccountBalanceAsOfMatchesRunningBalance(t *testing.T) {
+	dir := fakeHledger(t, map[string]string{"register": registerJSON(t,
+		registerRow{"2024-05-03", 100, 1500},
+		registerRow{"2024-05-03", -50, 1450},
+		registerRow{"2024-05-31", 200, 1650},
+		registerRow{"", -25, 1625},
+	)})
+	// The balance stub answers with the total of everything before its exclusive -e date
+	balances := map[string]float64{"2024-05-03": 1400, "2024-05-04": 1450, "2024-05-31": 1450, "2024-06-01": 1625}
+	for end, total := range balances {
+		output := balanceJSON(t, balanceRow{"assets:checking", total})
+		if err := os.WriteFile(filepath.Join(dir, "balance-"+end+".out"), []byte(output), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	script := `#!/bin/sh
+dir=$(dirname "$0")
+echo "$*" >> "$dir/args.log"
+case " $* " in
+*" register "*) cat "$dir/register.out" ;;
+*) while [ "$1" != "-e" ]; do shift; done; cat "$dir/balance-$2.out" ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "hledger"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	p := NewParser("test.journal", config.DefaultSettings())
+
+	history, err := p.GetAccountBalanceHistory("assets:checking", "2024-05-01", "2024-06-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, point := range history {
+		got, err := p.GetAccountBalanceAsOf("assets:checking", point.Date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := AccountBalanceAsOf{Account: "assets:checking", Date: point.Date, Balance: point.Balance, Commodity: "$"}
+		if *got != want {
+			t.Errorf("balance as of %s = %+v, want the running balance %+v", point.Date, *got, want)
+		}
+	}
+
+	if _, err := p.GetAccountBalanceAsOf("assets:checking", "05/31/2024"); err == nil {
+		t.Error("GetAccountBalanceAsOf accepted a date that is not YYYY-MM-DD")
+	}
+}
@@ -0,0 +1,407 @@
+package hledger
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/cwj5/minted/internal/config"
+)
+
+// historyCategories returns the sorted categories of a budget or income history
+func historyCategories(items []BudgetHistoryItem) []string {
+	categories := []string{}
+	for _, item := range items {
+		categories = append(categories, item.Category)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// budgetCategories returns the sorted categories of budget items
+func budgetCategories(items []BudgetItem) []string {
+	categories := []string{}
+	for _, item := range items {
+		categories = append(categories, item.Category)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+func TestMinMonthsForAverage(t *testing.T) {
+	// food and salary have two months of history before June, gym and bonus only one
+	journal := func() []Transaction {
+		return []Transaction{
+			expense("2024-04-03", "expenses:food", 100),
+			expense("2024-05-03", "expenses:food", 120),
+			expense("2024-05-10", "expenses:gym", 40),
+			income("2024-04-25", "income:salary", 3000),
+			income("2024-05-25", "income:salary", 3000),
+			income("2024-05-26", "income:bonus", 500),
+		}
+	}
+	tests := []struct {
+		minMonths    any // nil leaves the default of 2
+		wantExpenses []string
+		wantIncome   []string
+	}{
+		{nil, []string{"food"}, []string{"salary"}},
+		{2, []string{"food"}, []string{"salary"}},
+		{1, []string{"food", "gym"}, []string{"bonus", "salary"}},
+		{3, []string{}, []string{}},
+	}
+	for _, tt := range tests {
+		settings := config.DefaultSettings()
+		if tt.minMonths != nil {
+			settings.Preferences["minMonthsForAverage"] = tt.minMonths
+		}
+		p := newTestParser(t, settings, journal()...)
+
+		budget, err := p.GetBudgetData()
+		if err != nil {
+			t.Fatal(err)
+		}
+		history, err := p.GetBudgetHistory()
+		if err != nil {
+			t.Fatal(err)
+		}
+		filtered, err := p.GetBudgetHistoryFiltered("2024-04-01", "2024-06-01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		incomeHistory, err := p.GetIncomeHistory()
+		if err != nil {
+			t.Fatal(err)
+		}
+		incomeFiltered, err := p.GetIncomeHistoryFiltered("2024-04-01", "2024-06-01")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		results := map[string][]string{
+			"GetBudgetData":            budgetCategories(budget),
+			"GetBudgetHistory":         historyCategories(history),
+			"GetBudgetHistoryFiltered": historyCategories(filtered),
+			"GetIncomeHistory":         historyCategories(incomeHistory),
+			"GetIncomeHistoryFiltered": historyCategories(incomeFiltered),
+		}
+		for name, got := range results {
+			want := tt.wantExpenses
+			if name == "GetIncomeHistory" || name == "GetIncomeHistoryFiltered" {
+				want = tt.wantIncome
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("minMonthsForAverage=%v: %s categories %v, want %v", tt.minMonths, name, got, want)
+			}
+		}
+	}
+}
+
+func TestAverageExcludingExtremes(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		mult   float64
+		want   float64
+	}{
+		{"outlier excluded at 2", []float64{100, 100, 100, 500}, 2, 100},
+		{"outlier kept at 4", []float64{100, 100, 100, 500}, 4, 200},
+		{"no outliers", []float64{90, 110}, 2, 100},
+		{"empty", nil, 2, 0},
+	}
+	for _, tt := range tests {
+		if got := averageExcludingExtremes(tt.values, tt.mult); !approxEqual(got, tt.want) {
+			t.Errorf("%s: averageExcludingExtremes(%v, %v) = %v, want %v", tt.name, tt.values, tt.mult, got, tt.want)
+		}
+	}
+}
+
+func TestExtremeMultiplierPreference(t *testing.T) {
+	journal := func() []Transaction {
+		return []Transaction{
+			expense("2024-02-03", "expenses:food", 100),
+			expense("2024-03-03", "expenses:food", 100),
+			expense("2024-04-03", "expenses:food", 100),
+			expense("2024-05-03", "expenses:food", 500),
+			income("2024-02-25", "income:salary", 1000),
+			income("2024-03-25", "income:salary", 1000),
+			income("2024-04-25", "income:salary", 1000),
+			income("2024-05-25", "income:salary", 5000),
+		}
+	}
+	tests := []struct {
+		multiplier any // nil leaves the default of 2
+		wantFood   float64
+		wantSalary float64
+	}{
+		{nil, 100, 1000},
+		{2.0, 100, 1000},
+		{4.0, 200, 2000},
+	}
+	for _, tt := range tests {
+		settings := config.DefaultSettings()
+		if tt.multiplier != nil {
+			settings.Preferences["extremeMultiplier"] = tt.multiplier
+		}
+		p := newTestParser(t, settings, journal()...)
+
+		budget, err := p.GetBudgetHistory()
+		if err != nil {
+			t.Fatal(err)
+		}
+		budgetFiltered, err := p.GetBudgetHistoryFiltered("2024-02-01", "2024-06-01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		income, err := p.GetIncomeHistory()
+		if err != nil {
+			t.Fatal(err)
+		}
+		incomeFiltered, err := p.GetIncomeHistoryFiltered("2024-02-01", "2024-06-01")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for name, check := range map[string]struct {
+			items    []BudgetHistoryItem
+			category string
+			want     float64
+		}{
+			"GetBudgetHistory":         {budget, "food", tt.wantFood},
+			"GetBudgetHistoryFiltered": {budgetFiltered, "food", tt.wantFood},
+			"GetIncomeHistory":         {income, "salary", tt.wantSalary},
+			"GetIncomeHistoryFiltered": {incomeFiltered, "salary", tt.wantSalary},
+		} {
+			found := false
+			for _, item := range check.items {
+				if item.Category != check.category {
+					continue
+				}
+				found = true
+				if item.AverageExcludingExtremes != check.want {
+					t.Errorf("extremeMultiplier=%v: %s %s average excluding extremes %v, want %v",
+						tt.multiplier, name, check.category, item.AverageExcludingExtremes, check.want)
+				}
+			}
+			if !found {
+				t.Errorf("extremeMultiplier=%v: %s has no %s", tt.multiplier, name, check.category)
+			}
+		}
+	}
+}
+
+func TestBudgetLookbackMonths(t *testing.T) {
+	// Heavy spending two years before June 2024, little in the last few months, nothing between
+	journal := func() []Transaction {
+		return []Transaction{
+			expense("2022-05-03", "expenses:food", 1000),
+			expense("2022-06-03", "expenses:food", 1000),
+			expense("2022-07-03", "expenses:food", 1000),
+			expense("2024-03-03", "expenses:food", 100),
+			expense("2024-04-03", "expenses:food", 100),
+			expense("2024-05-03", "expenses:food", 100),
+		}
+	}
+	tests := []struct {
+		lookback any // nil leaves the default of all months
+		want     float64
+	}{
+		{nil, 550},
+		{0, 550},
+		{12, 100},
+		{24, 460}, // 2022-06 is exactly 24 months back and counts, 2022-05 does not
+		{2, 100},
+	}
+	for _, tt := range tests {
+		settings := config.DefaultSettings()
+		if tt.lookback != nil {
+			settings.Preferences["budgetLookbackMonths"] = tt.lookback
+		}
+		p := newTestParser(t, settings, journal()...)
+
+		budget, err := p.GetBudgetData()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(budget) != 1 || budget[0].Average != tt.want {
+			t.Errorf("budgetLookbackMonths=%v: GetBudgetData = %+v, want food averaging %v", tt.lookback, budget, tt.want)
+		}
+		history, err := p.GetBudgetHistory()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) != 1 || history[0].Average != tt.want {
+			t.Errorf("budgetLookbackMonths=%v: GetBudgetHistory = %+v, want food averaging %v", tt.lookback, history, tt.want)
+		}
+	}
+}
+
+func TestInBudgetWindow(t *testing.T) {
+	tests := []struct {
+		month    string
+		lookback int
+		want     bool
+	}{
+		{"2024-06", 0, false}, // the current month is never history
+		{"2024-07", 0, false},
+		{"2019-01", 0, true},
+		{"2024-05", 1, true},
+		{"2024-04", 1, false},
+		{"2023-06", 12, true},
+		{"2023-05", 12, false},
+		{"2023-12", 6, true}, // across the year boundary
+		{"2023-11", 6, false},
+		{"bad", 6, false},
+		{"", 0, false}, // sorts before every month but isn't one
+		{"2", 0, false},
+	}
+	for _, tt := range tests {
+		if got := inBudgetWindow(tt.month, "2024-06", tt.lookback); got != tt.want {
+			t.Errorf("inBudgetWindow(%q, 2024-06, %d) = %v, want %v", tt.month, tt.lookback, got, tt.want)
+		}
+	}
+}
+
+func TestSeasonalBudgetMode(t *testing.T) {
+	// Heating costs 300 in December, January and February and 60 the rest of 2022 and 2023
+	var journal []Transaction
+	for month := time.Date(2022, time.January, 5, 0, 0, 0, 0, time.UTC); month.Year() < 2024; month = month.AddDate(0, 1, 0) {
+		amount := 60.0
+		if month.Month() == time.December || month.Month() <= time.February {
+			amount = 300
+		}
+		journal = append(journal, expense(month.Format("2006-01-02"), "expenses:heating", amount))
+	}
+	// An undated entry has no month and must neither count nor break the same-month lookup
+	journal = append(journal, expense("", "expenses:heating", 5000))
+
+	tests := []struct {
+		name string
+		mode any // nil leaves the default flat mode
+		asOf time.Time
+		want float64
+	}{
+		{"flat January", nil, time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC), 60},
+		{"explicit flat", "flat", time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC), 60},
+		{"seasonal January", "seasonal", time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC), 300},
+		{"seasonal July", "seasonal", time.Date(2024, time.July, 15, 0, 0, 0, 0, time.UTC), 60},
+		{"one earlier January falls back to flat", "seasonal", time.Date(2023, time.January, 15, 0, 0, 0, 0, time.UTC), 60},
+		{"two earlier Decembers", "seasonal", time.Date(2024, time.December, 15, 0, 0, 0, 0, time.UTC), 300},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := config.DefaultSettings()
+			settings.Preferences["budgetMethod"] = "median"
+			if tt.mode != nil {
+				settings.Preferences["budgetMode"] = tt.mode
+			}
+			p := newTestParser(t, settings, journal...)
+
+			budget, err := p.GetBudgetDataAsOf(tt.asOf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(budget) != 1 || budget[0].Average != tt.want {
+				t.Errorf("GetBudgetDataAsOf(%s) = %+v, want heating averaging %v", tt.asOf.Format("2006-01"), budget, tt.want)
+			}
+		})
+	}
+}
+
+func TestBudgetMethod(t *testing.T) {
+	amounts := []float64{100, 110, 120, 130, 140, 150, 160, 170, 300, 1000}
+	var journal []Transaction
+	month := testNow.AddDate(0, -len(amounts), 0)
+	for _, amount := range amounts {
+		journal = append(journal, expense(month.Format("2006-01")+"-05", "expenses:food", amount))
+		month = month.AddDate(0, 1, 0)
+	}
+
+	tests := []struct {
+		method any // nil leaves the default
+		want   float64
+	}{
+		{nil, 135}, // mean without the IQR outliers 300 and 1000
+		{"mean", 135},
+		{"median", 145},  // (140 + 150) / 2
+		{"trimmed", 160}, // mean without the lowest and highest 10%: 100 and 1000
+		{"unknown", 135}, // falls back to mean
+	}
+	for _, tt := range tests {
+		settings := config.DefaultSettings()
+		if tt.method != nil {
+			settings.Preferences["budgetMethod"] = tt.method
+		}
+		p := newTestParser(t, settings, journal...)
+
+		if got := p.budgetAverage(amounts); !approxEqual(got, tt.want) {
+			t.Errorf("budgetMethod=%v: budgetAverage = %v, want %v", tt.method, got, tt.want)
+		}
+		budget, err := p.GetBudgetData()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(budget) != 1 || budget[0].Average != tt.want {
+			t.Errorf("budgetMethod=%v: GetBudgetData = %+v, want food averaging %v", tt.method, budget, tt.want)
+		}
+	}
+}
+
+func TestRemoveOutliers(t *testing.T) {
+	for n := 3; n <= 10; n++ {
+		equal := make([]float64, n)
+		spread := make([]float64, n)
+		for i := range equal {
+			equal[i] = 50
+			spread[i] = float64(100 + 10*i)
+		}
+		withOutlier := append(append([]float64(nil), spread[:n-1]...), 1000)
+		// With three values the quartiles straddle the outlier, so it stays
+		withoutOutlier := withOutlier
+		if n >= 4 {
+			withoutOutlier = spread[:n-1]
+		}
+
+		tests := []struct {
+			name   string
+			values []float64
+			want   []float64
+		}{
+			{"equal values", equal, equal},
+			{"evenly spread", spread, spread},
+			{"one outlier", withOutlier, withoutOutlier},
+		}
+		for _, tt := range tests {
+			input := append([]float64(nil), tt.values...)
+			if got := removeOutliers(input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("n=%d %s: removeOutliers(%v) = %v, want %v", n, tt.name, tt.values, got, tt.want)
+			}
+			if !reflect.DeepEqual(input, tt.values) {
+				t.Errorf("n=%d %s: removeOutliers reordered its input to %v", n, tt.name, input)
+			}
+		}
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	sorted := []float64{10, 20, 30, 40}
+	tests := []struct {
+		q    float64
+		want float64
+	}{
+		{0, 10},
+		{0.25, 17.5},
+		{0.5, 25},
+		{0.75, 32.5},
+		{1, 40},
+	}
+	for _, tt := range tests {
+		if got := quantile(sorted, tt.q); !approxEqual(got, tt.want) {
+			t.Errorf("quantile(%v, %v) = %v, want %v", sorted, tt.q, got, tt.want)
+		}
+	}
+	if got := quantile(nil, 0.5); got != 0 {
+		t.Errorf("quantile(nil) = %v, want 0", got)
+	}
+}
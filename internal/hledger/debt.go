@@ -0,0 +1,76 @@
+package hledger
+
+import (
+	"sort"
+	"strings"
+)
+
+// DebtAccount is the balance owed on one liability account
+type DebtAccount struct {
+	Account     string   `json:"account"`
+	Balance     float64  `json:"balance"`     // amount owed, positive
+	Limit       *float64 `json:"limit"`       // nil when no credit limit is configured
+	Utilization *float64 `json:"utilization"` // percent of the limit used, nil without a limit
+}
+
+// DebtSummary totals liabilities and the utilization of accounts with credit limits
+type DebtSummary struct {
+	Accounts    []DebtAccount `json:"accounts"`
+	TotalDebt   float64       `json:"totalDebt"`
+	TotalLimit  float64       `json:"totalLimit"`
+	Utilization *float64      `json:"utilization"` // over accounts with limits only
+}
+
+// utilizationPercent returns debt as a percentage of limit, rounded to two places
+func utilizationPercent(debt, limit float64) *float64 {
+	percent := roundRatio(debt / limit * 100)
+	return &percent
+}
+
+// GetDebtSummary reports what is owed on each liability account as of endDate (all postings
+// when empty), with utilization for accounts that have a credit limit in settings. Balances
+// are a snapshot, so startDate is not used.
+func (p *Parser) GetDebtSummary(endDate string) (*DebtSummary, error) {
+	accounts, err := p.GetAccountsUpToDate(endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := p.currentSettings().CreditLimits
+	summary := &DebtSummary{Accounts: []DebtAccount{}}
+	var limitedDebt float64
+	for _, account := range accounts {
+		if !strings.HasPrefix(account.Name, "liabilities:") {
+			continue
+		}
+
+		// Liabilities are negative in hledger; report debt as positive
+		debt := -account.Balance
+		item := DebtAccount{
+			Account: account.Name,
+			Balance: p.roundAmount(debt),
+		}
+		if limit, ok := limits[account.Name]; ok && limit > 0 {
+			limit := limit
+			item.Limit = &limit
+			item.Utilization = utilizationPercent(debt, limit)
+			summary.TotalLimit += limit
+			limitedDebt += debt
+		}
+
+		summary.TotalDebt += debt
+		summary.Accounts = append(summary.Accounts, item)
+	}
+
+	summary.TotalDebt = p.roundAmount(summary.TotalDebt)
+	summary.TotalLimit = p.roundAmount(summary.TotalLimit)
+	if summary.TotalLimit > 0 {
+		summary.Utilization = utilizationPercent(limitedDebt, summary.TotalLimit)
+	}
+
+	sort.Slice(summary.Accounts, func(i, j int) bool {
+		return summary.Accounts[i].Balance > summary.Accounts[j].Balance
+	})
+
+	return summary, nil
+}
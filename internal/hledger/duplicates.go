@@ -0,0 +1,143 @@
+package hledger
+
+import "sort"
+
+// duplicateMaxDistance is the most single-character edits two normalized descriptions may
+// differ by and still count as the same payee
+const duplicateMaxDistance = 2
+
+// DuplicateGroup represents transactions that look like the same expense posted twice
+type DuplicateGroup struct {
+	Date         string        `json:"date"`
+	Amount       float64       `json:"amount"`
+	Indices      []int         `json:"indices"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+// transactionMagnitude returns the total of the positive postings, which is the size of a
+// balanced transaction regardless of which side is inspected
+func (p *Parser) transactionMagnitude(tx Transaction) float64 {
+	var total float64
+	for _, posting := range tx.Postings {
+		if len(posting.Amount) == 0 {
+			continue
+		}
+		if amount := convertAmount(posting.Amount[0].Quantity); amount > 0 {
+			total += amount
+		}
+	}
+	return p.roundAmount(total)
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(min(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// similarDescriptions reports whether two normalized descriptions name the same payee.
+// Short descriptions must match exactly so that e.g. "bar" and "car" stay distinct.
+func similarDescriptions(a, b string) bool {
+	if a == b {
+		return true
+	}
+	longest := len([]rune(a))
+	if n := len([]rune(b)); n > longest {
+		longest = n
+	}
+	if longest < 4*duplicateMaxDistance {
+		return false
+	}
+	return levenshtein(a, b) <= duplicateMaxDistance
+}
+
+// GetDuplicateTransactions finds transactions that were likely posted twice. To avoid flagging
+// legitimately repeated purchases, candidates must share the exact date and amount and have
+// near-identical descriptions. Transfers are included since imports can double those too.
+func (p *Parser) GetDuplicateTransactions(startDate, endDate string) ([]DuplicateGroup, error) {
+	transactions, err := p.GetTransactionsFiltered(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	type bucketKey struct {
+		date   string
+		amount float64
+	}
+	buckets := make(map[bucketKey][]Transaction)
+	for _, tx := range transactions {
+		amount := p.transactionMagnitude(tx)
+		if amount == 0 {
+			continue
+		}
+		key := bucketKey{date: tx.Date, amount: amount}
+		buckets[key] = append(buckets[key], tx)
+	}
+
+	result := []DuplicateGroup{}
+	for key, candidates := range buckets {
+		if len(candidates) < 2 {
+			continue
+		}
+
+		// Cluster the candidates by description; each cluster keeps its first description
+		var clusters [][]Transaction
+		var names []string
+		for _, tx := range candidates {
+			name := normalizeDescription(tx.Description)
+			placed := false
+			for i := range clusters {
+				if similarDescriptions(names[i], name) {
+					clusters[i] = append(clusters[i], tx)
+					placed = true
+					break
+				}
+			}
+			if !placed {
+				clusters = append(clusters, []Transaction{tx})
+				names = append(names, name)
+			}
+		}
+
+		for _, cluster := range clusters {
+			if len(cluster) < 2 {
+				continue
+			}
+			group := DuplicateGroup{
+				Date:         key.date,
+				Amount:       key.amount,
+				Indices:      make([]int, 0, len(cluster)),
+				Transactions: cluster,
+			}
+			for _, tx := range cluster {
+				group.Indices = append(group.Indices, tx.Index)
+			}
+			result = append(result, group)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Date != result[j].Date {
+			return result[i].Date > result[j].Date
+		}
+		return result[i].Amount > result[j].Amount
+	})
+
+	return result, nil
+}
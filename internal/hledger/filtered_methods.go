@@ -1,8 +1,6 @@
 package hledger
 
 import (
-	"encoding/json"
-	"log"
 	"math"
 	"os/exec"
 	"sort"
@@ -13,29 +11,31 @@ import (
 
 // Filtered method implementations - these bypass the cache and apply date ranges
 
-// GetAccountsFiltered retrieves accounts with balances for the period (changes only)
-func (p *Parser) GetAccountsFiltered(startDate, endDate string) ([]Account, error) {
-	args := []string{"-f", p.journalFile, "balance", "--empty", "-O", "json"}
+// GetAccountsFiltered retrieves accounts with balances for the period (changes only),
+// rolled up to the given depth (0 means no rollup)
+func (p *Parser) GetAccountsFiltered(startDate, endDate string, depth int) ([]Account, error) {
+	args := append(p.fileArgs(), "balance", "--empty", "-O", "json")
 	args = append(args, p.buildDateArgs(startDate, endDate)...)
+	args = append(args, depthArgs(depth)...)
+	args = append(args, p.valuationArgs()...)
+	args = append(args, p.statusArgs()...)
+	args = append(args, p.realArgs()...)
 
 	cmd := exec.Command("hledger", args...)
 	output, err := cmd.Output()
 	if err != nil {
-		log.Printf("Error running hledger balance (filtered): file=%s, error=%v", p.journalFile, err)
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			log.Printf("stderr: %s", string(exitErr.Stderr))
-		}
-		return nil, err
+		p.logCommandError(cmd, err)
+		return nil, wrapExecError(err)
 	}
 
 	var balanceData [][]interface{}
-	err = json.Unmarshal(output, &balanceData)
+	err = decodeJSON(output, &balanceData)
 	if err != nil {
-		log.Printf("Error parsing JSON: %v", err)
+		p.log().Error("Error parsing hledger JSON", "args", cmd.Args[1:], "error", err)
 		return nil, err
 	}
 
-	var accounts []Account
+	accounts := []Account{}
 
 	if len(balanceData) > 0 {
 		accountsList := balanceData[0]
@@ -53,6 +53,7 @@ func (p *Parser) GetAccountsFiltered(startDate, endDate string) ([]Account, erro
 						Name:     name,
 						Balance:  0,
 						Currency: "",
+						Balances: []CommodityBalance{},
 					})
 					continue
 				}
@@ -77,6 +78,7 @@ func (p *Parser) GetAccountsFiltered(startDate, endDate string) ([]Account, erro
 					Name:     name,
 					Balance:  balance,
 					Currency: currency,
+					Balances: commodityBalances(commodityData),
 				})
 			}
 		}
@@ -87,29 +89,29 @@ func (p *Parser) GetAccountsFiltered(startDate, endDate string) ([]Account, erro
 
 // GetAccountsUpToDate retrieves accounts with cumulative balances from start of journal up to end date
 func (p *Parser) GetAccountsUpToDate(endDate string) ([]Account, error) {
-	args := []string{"-f", p.journalFile, "balance", "--empty", "-O", "json"}
+	args := append(p.fileArgs(), "balance", "--empty", "-O", "json")
 	if endDate != "" {
 		args = append(args, "-e", endDate)
 	}
+	args = append(args, p.valuationArgs()...)
+	args = append(args, p.statusArgs()...)
+	args = append(args, p.realArgs()...)
 
 	cmd := exec.Command("hledger", args...)
 	output, err := cmd.Output()
 	if err != nil {
-		log.Printf("Error running hledger balance (up to date): file=%s, error=%v", p.journalFile, err)
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			log.Printf("stderr: %s", string(exitErr.Stderr))
-		}
-		return nil, err
+		p.logCommandError(cmd, err)
+		return nil, wrapExecError(err)
 	}
 
 	var balanceData [][]interface{}
-	err = json.Unmarshal(output, &balanceData)
+	err = decodeJSON(output, &balanceData)
 	if err != nil {
-		log.Printf("Error parsing JSON: %v", err)
+		p.log().Error("Error parsing hledger JSON", "args", cmd.Args[1:], "error", err)
 		return nil, err
 	}
 
-	var accounts []Account
+	accounts := []Account{}
 
 	if len(balanceData) > 0 {
 		accountsList := balanceData[0]
@@ -127,6 +129,7 @@ func (p *Parser) GetAccountsUpToDate(endDate string) ([]Account, error) {
 						Name:     name,
 						Balance:  0,
 						Currency: "",
+						Balances: []CommodityBalance{},
 					})
 					continue
 				}
@@ -151,6 +154,7 @@ func (p *Parser) GetAccountsUpToDate(endDate string) ([]Account, error) {
 					Name:     name,
 					Balance:  balance,
 					Currency: currency,
+					Balances: commodityBalances(commodityData),
 				})
 			}
 		}
@@ -161,29 +165,44 @@ func (p *Parser) GetAccountsUpToDate(endDate string) ([]Account, error) {
 
 // GetTransactionsFiltered retrieves transactions within a date range
 func (p *Parser) GetTransactionsFiltered(startDate, endDate string) ([]Transaction, error) {
-	args := []string{"-f", p.journalFile, "print", "-O", "json"}
-	args = append(args, p.buildDateArgs(startDate, endDate)...)
-
-	cmd := exec.Command("hledger", args...)
+	cmd := exec.Command("hledger", p.printArgs(startDate, endDate)...)
 	output, err := cmd.Output()
 	if err != nil {
-		log.Printf("Error running hledger print (filtered): %v", err)
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			log.Printf("stderr: %s", string(exitErr.Stderr))
-		}
-		return nil, err
+		p.logCommandError(cmd, err)
+		return nil, wrapExecError(err)
 	}
 
-	var transactions []Transaction
-	err = json.Unmarshal(output, &transactions)
+	transactions := []Transaction{}
+	err = decodeJSON(output, &transactions)
 	if err != nil {
-		log.Printf("Error parsing JSON: %v", err)
+		p.log().Error("Error parsing hledger JSON", "args", cmd.Args[1:], "error", err)
 		return nil, err
 	}
+	p.observePrecision(transactions)
 
 	return transactions, nil
 }
 
+// spendingTransactions returns the transactions the filtered spending reports bucket. hledger
+// selects transactions by their own date, but with posting dates a transaction dated outside
+// the range can have postings inside it, so the whole journal is read and the caller drops
+// postings outside the range.
+func (p *Parser) spendingTransactions(startDate, endDate string, usePostingDates bool) ([]Transaction, error) {
+	if usePostingDates {
+		return p.GetTransactionsFiltered("", "")
+	}
+	return p.GetTransactionsFiltered(startDate, endDate)
+}
+
+// inDateRange reports whether a YYYY-MM-DD date falls in [startDate, endDate). Like
+// buildDateArgs, a range missing either bound doesn't restrict anything.
+func inDateRange(date, startDate, endDate string) bool {
+	if startDate == "" || endDate == "" {
+		return true
+	}
+	return date >= startDate && date < endDate
+}
+
 // GetMonthlyMetricsFiltered returns financial metrics filtered to a specific date range
 func (p *Parser) GetMonthlyMetricsFiltered(startDate, endDate string) ([]MonthlyMetrics, error) {
 	transactions, err := p.GetTransactionsFiltered(startDate, endDate)
@@ -191,6 +210,8 @@ func (p *Parser) GetMonthlyMetricsFiltered(startDate, endDate string) ([]Monthly
 		return nil, err
 	}
 
+	settings := p.currentSettings()
+
 	// Map of month -> {income, expenses}
 	monthlyData := make(map[string]struct {
 		income   float64
@@ -198,22 +219,30 @@ func (p *Parser) GetMonthlyMetricsFiltered(startDate, endDate string) ([]Monthly
 	})
 
 	for _, tx := range transactions {
+		if p.isTransfer(tx) {
+			continue
+		}
+
 		month := getYearMonth(tx.Date)
 
 		for _, posting := range tx.Postings {
+			if !settings.IsReportedAccount(posting.Account) {
+				continue
+			}
+
 			var amount float64
 			if len(posting.Amount) > 0 {
 				amount = convertAmount(posting.Amount[0].Quantity)
 			}
 
-			// Positive amounts for income (convert negative to positive), negative for expenses
+			// Count income and expenses as positive amounts, whatever the journal's sign convention
 			if strings.HasPrefix(posting.Account, "income:") {
 				data := monthlyData[month]
-				data.income += -amount // Income is negative in hledger, so negate it
+				data.income += p.incomeAmount(amount)
 				monthlyData[month] = data
 			} else if strings.HasPrefix(posting.Account, "expenses:") {
 				data := monthlyData[month]
-				data.expenses += amount
+				data.expenses += p.expenseAmount(amount)
 				monthlyData[month] = data
 			}
 		}
@@ -227,7 +256,7 @@ func (p *Parser) GetMonthlyMetricsFiltered(startDate, endDate string) ([]Monthly
 	sort.Strings(months)
 
 	// Build metrics
-	var metrics []MonthlyMetrics
+	metrics := []MonthlyMetrics{}
 	for _, month := range months {
 		data := monthlyData[month]
 
@@ -239,10 +268,10 @@ func (p *Parser) GetMonthlyMetricsFiltered(startDate, endDate string) ([]Monthly
 
 		metrics = append(metrics, MonthlyMetrics{
 			Month:       month,
-			Income:      math.Round(data.income*100) / 100,
-			Expenses:    math.Round(data.expenses*100) / 100,
+			Income:      p.roundAmount(data.income),
+			Expenses:    p.roundAmount(data.expenses),
 			NetWorth:    0.0, // Simplified
-			SavingsRate: math.Round(savingsRate*100) / 100,
+			SavingsRate: roundRatio(savingsRate),
 		})
 	}
 
@@ -251,7 +280,10 @@ func (p *Parser) GetMonthlyMetricsFiltered(startDate, endDate string) ([]Monthly
 
 // GetCategorySpendingFiltered returns category spending filtered to a specific date range
 func (p *Parser) GetCategorySpendingFiltered(startDate, endDate string) ([]CategorySpending, error) {
-	transactions, err := p.GetTransactionsFiltered(startDate, endDate)
+	settings := p.currentSettings()
+	usePostingDates := settings.GetPreferenceBool("usePostingDates", false)
+
+	transactions, err := p.spendingTransactions(startDate, endDate, usePostingDates)
 	if err != nil {
 		return nil, err
 	}
@@ -260,13 +292,23 @@ func (p *Parser) GetCategorySpendingFiltered(startDate, endDate string) ([]Categ
 	monthlyCategories := make(map[string]map[string]float64)
 
 	for _, tx := range transactions {
-		month := getYearMonth(tx.Date)
+		if p.isTransfer(tx) {
+			continue
+		}
 
 		for _, posting := range tx.Postings {
+			if !settings.IsReportedAccount(posting.Account) {
+				continue
+			}
+
 			// Only include Expenses accounts
 			if !strings.HasPrefix(posting.Account, "expenses:") {
 				continue
 			}
+			if usePostingDates && !inDateRange(postingDate(tx, posting), startDate, endDate) {
+				continue
+			}
+			month := postingMonth(tx, posting, usePostingDates)
 
 			// Extract category
 			parts := strings.Split(posting.Account, ":")
@@ -279,12 +321,7 @@ func (p *Parser) GetCategorySpendingFiltered(startDate, endDate string) ([]Categ
 
 			var amount float64
 			if len(posting.Amount) > 0 {
-				amount = convertAmount(posting.Amount[0].Quantity)
-			}
-
-			// Store positive value for expenses
-			if amount < 0 {
-				amount = -amount
+				amount = p.expenseAmount(convertAmount(posting.Amount[0].Quantity))
 			}
 
 			if monthlyCategories[month] == nil {
@@ -294,14 +331,17 @@ func (p *Parser) GetCategorySpendingFiltered(startDate, endDate string) ([]Categ
 		}
 	}
 
+	clampMonthlyTotals(monthlyCategories)
+
 	// Build result
-	var result []CategorySpending
+	result := []CategorySpending{}
 	for month, categories := range monthlyCategories {
 		for category, amount := range categories {
 			result = append(result, CategorySpending{
 				Month:    month,
 				Category: category,
-				Amount:   math.Round(amount*100) / 100,
+				Tier:     p.tierName(category),
+				Amount:   p.roundAmount(amount),
 			})
 		}
 	}
@@ -348,22 +388,19 @@ func (p *Parser) GetIncomeBreakdownFiltered(startDate, endDate string) ([]Catego
 				amount = convertAmount(posting.Amount[0].Quantity)
 			}
 
-			// Income amounts are typically negative in hledger, make them positive
-			if amount < 0 {
-				amount = -amount
-			}
+			amount = p.incomeAmount(amount)
 
 			incomeCategories[category] += amount
 		}
 	}
 
 	// Build result - return as if all income happened in a single period
-	var result []CategorySpending
+	result := []CategorySpending{}
 	for category, amount := range incomeCategories {
 		result = append(result, CategorySpending{
 			Month:    "period",
 			Category: category,
-			Amount:   math.Round(amount*100) / 100,
+			Amount:   p.roundAmount(amount),
 		})
 	}
 
@@ -377,7 +414,10 @@ func (p *Parser) GetIncomeBreakdownFiltered(startDate, endDate string) ([]Catego
 
 // GetBudgetHistoryFiltered returns budget history filtered to a specific date range
 func (p *Parser) GetBudgetHistoryFiltered(startDate, endDate string) ([]BudgetHistoryItem, error) {
-	transactions, err := p.GetTransactionsFiltered(startDate, endDate)
+	settings := p.currentSettings()
+	usePostingDates := settings.GetPreferenceBool("usePostingDates", false)
+
+	transactions, err := p.spendingTransactions(startDate, endDate, usePostingDates)
 	if err != nil {
 		return nil, err
 	}
@@ -386,12 +426,22 @@ func (p *Parser) GetBudgetHistoryFiltered(startDate, endDate string) ([]BudgetHi
 	monthlySpending := make(map[string]map[string]float64)
 
 	for _, tx := range transactions {
-		month := getYearMonth(tx.Date)
+		if p.isTransfer(tx) {
+			continue
+		}
 
 		for _, posting := range tx.Postings {
+			if !settings.IsReportedAccount(posting.Account) {
+				continue
+			}
+
 			if !strings.HasPrefix(posting.Account, "expenses:") {
 				continue
 			}
+			if usePostingDates && !inDateRange(postingDate(tx, posting), startDate, endDate) {
+				continue
+			}
+			month := postingMonth(tx, posting, usePostingDates)
 
 			// Extract category
 			parts := strings.Split(posting.Account, ":")
@@ -404,12 +454,7 @@ func (p *Parser) GetBudgetHistoryFiltered(startDate, endDate string) ([]BudgetHi
 
 			var amount float64
 			if len(posting.Amount) > 0 {
-				amount = convertAmount(posting.Amount[0].Quantity)
-			}
-
-			// Store positive value for expenses
-			if amount < 0 {
-				amount = -amount
+				amount = p.expenseAmount(convertAmount(posting.Amount[0].Quantity))
 			}
 
 			if monthlySpending[month] == nil {
@@ -419,6 +464,8 @@ func (p *Parser) GetBudgetHistoryFiltered(startDate, endDate string) ([]BudgetHi
 		}
 	}
 
+	clampMonthlyTotals(monthlySpending)
+
 	// Collect all months
 	var allMonths []string
 	for month := range monthlySpending {
@@ -434,10 +481,10 @@ func (p *Parser) GetBudgetHistoryFiltered(startDate, endDate string) ([]BudgetHi
 		}
 	}
 
-	var history []BudgetHistoryItem
+	history := []BudgetHistoryItem{}
 
 	for category, amounts := range categoryHistory {
-		if len(amounts) < 1 {
+		if len(amounts) < p.minMonthsForAverage() {
 			continue
 		}
 
@@ -447,23 +494,10 @@ func (p *Parser) GetBudgetHistoryFiltered(startDate, endDate string) ([]BudgetHi
 		}
 		avg := sum / float64(len(amounts))
 
-		// Calculate average excluding extremes (values > 2x average)
-		var filteredAmounts []float64
-		for _, v := range amounts {
-			if v <= avg*2 {
-				filteredAmounts = append(filteredAmounts, v)
-			}
-		}
-		avgExcludingExtremes := avg
-		if len(filteredAmounts) > 0 {
-			var filteredSum float64
-			for _, v := range filteredAmounts {
-				filteredSum += v
-			}
-			avgExcludingExtremes = filteredSum / float64(len(filteredAmounts))
-		}
+		// Calculate average excluding extremes (values > multiplier x average)
+		avgExcludingExtremes := averageExcludingExtremes(amounts, p.extremeMultiplier())
 
-		var monthData []MonthBudget
+		monthData := []MonthBudget{}
 		for _, month := range allMonths {
 			var amount float64
 			if categories, ok := monthlySpending[month]; ok {
@@ -484,16 +518,16 @@ func (p *Parser) GetBudgetHistoryFiltered(startDate, endDate string) ([]BudgetHi
 			monthData = append(monthData, MonthBudget{
 				Month:           month,
 				Year:            year,
-				Amount:          math.Round(amount*100) / 100,
-				PercentOfBudget: math.Round(percent*100) / 100,
+				Amount:          p.roundAmount(amount),
+				PercentOfBudget: roundRatio(percent),
 				OverBudget:      amount > avg,
 			})
 		}
 
 		history = append(history, BudgetHistoryItem{
 			Category:                 category,
-			Average:                  math.Round(avg*100) / 100,
-			AverageExcludingExtremes: math.Round(avgExcludingExtremes*100) / 100,
+			Average:                  p.roundAmount(avg),
+			AverageExcludingExtremes: p.roundAmount(avgExcludingExtremes),
 			Months:                   monthData,
 		})
 	}
@@ -537,10 +571,7 @@ func (p *Parser) GetIncomeHistoryFiltered(startDate, endDate string) ([]BudgetHi
 				amount = convertAmount(posting.Amount[0].Quantity)
 			}
 
-			// Income is negative in hledger, so negate it for positive display
-			if amount < 0 {
-				amount = -amount
-			}
+			amount = p.incomeAmount(amount)
 
 			if monthlyIncome[month] == nil {
 				monthlyIncome[month] = make(map[string]float64)
@@ -564,10 +595,10 @@ func (p *Parser) GetIncomeHistoryFiltered(startDate, endDate string) ([]BudgetHi
 		}
 	}
 
-	var history []BudgetHistoryItem
+	history := []BudgetHistoryItem{}
 
 	for category, amounts := range categoryHistory {
-		if len(amounts) < 1 {
+		if len(amounts) < p.minMonthsForAverage() {
 			continue
 		}
 
@@ -577,23 +608,10 @@ func (p *Parser) GetIncomeHistoryFiltered(startDate, endDate string) ([]BudgetHi
 		}
 		avg := sum / float64(len(amounts))
 
-		// Calculate average excluding extremes
-		var filteredAmounts []float64
-		for _, v := range amounts {
-			if v <= avg*2 {
-				filteredAmounts = append(filteredAmounts, v)
-			}
-		}
-		avgExcludingExtremes := avg
-		if len(filteredAmounts) > 0 {
-			var filteredSum float64
-			for _, v := range filteredAmounts {
-				filteredSum += v
-			}
-			avgExcludingExtremes = filteredSum / float64(len(filteredAmounts))
-		}
+		// Calculate average excluding extremes (values > multiplier x average)
+		avgExcludingExtremes := averageExcludingExtremes(amounts, p.extremeMultiplier())
 
-		var monthData []MonthBudget
+		monthData := []MonthBudget{}
 		for _, month := range allMonths {
 			var amount float64
 			if categories, ok := monthlyIncome[month]; ok {
@@ -614,16 +632,16 @@ func (p *Parser) GetIncomeHistoryFiltered(startDate, endDate string) ([]BudgetHi
 			monthData = append(monthData, MonthBudget{
 				Month:           month,
 				Year:            year,
-				Amount:          math.Round(amount*100) / 100,
-				PercentOfBudget: math.Round(percent*100) / 100,
+				Amount:          p.roundAmount(amount),
+				PercentOfBudget: roundRatio(percent),
 				OverBudget:      false, // Income doesn't have "over budget"
 			})
 		}
 
 		history = append(history, BudgetHistoryItem{
 			Category:                 category,
-			Average:                  math.Round(avg*100) / 100,
-			AverageExcludingExtremes: math.Round(avgExcludingExtremes*100) / 100,
+			Average:                  p.roundAmount(avg),
+			AverageExcludingExtremes: p.roundAmount(avgExcludingExtremes),
 			Months:                   monthData,
 		})
 	}
@@ -635,49 +653,72 @@ func (p *Parser) GetIncomeHistoryFiltered(startDate, endDate string) ([]BudgetHi
 	return history, nil
 }
 
-// GetNetWorthOverTimeFiltered returns net worth points filtered to a specific date range
+// GetNetWorthOverTimeFiltered returns net worth points filtered to a specific date range.
+// With a start date the series is seeded from the historical balances just before it, so the
+// curve starts at the true net worth, including opening balances, rather than at zero.
 func (p *Parser) GetNetWorthOverTimeFiltered(startDate, endDate string) ([]NetWorthPoint, error) {
 	transactions, err := p.GetTransactionsFiltered(startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
 
-	// Map of date -> net worth
-	dateNetWorth := make(map[string]float64)
+	settings := p.currentSettings()
+
+	var opening float64
+	if startDate != "" {
+		// hledger's end date is exclusive, so this is everything posted before startDate
+		accounts, err := p.GetAccountsUpToDate(startDate)
+		if err != nil {
+			return nil, err
+		}
+		for _, account := range accounts {
+			if !settings.IsReportedAccount(account.Name) {
+				continue
+			}
+			if settings.IsNetWorthAccount(account.Name) {
+				opening += account.Balance
+			}
+		}
+	}
+
+	// Map of date -> change in net worth
+	dateChange := make(map[string]float64)
 
 	for _, tx := range transactions {
 		for _, posting := range tx.Postings {
-			// Include all asset/liability accounts to calculate net worth
-			if strings.HasPrefix(posting.Account, "assets:") || strings.HasPrefix(posting.Account, "liabilities:") {
+			if !settings.IsReportedAccount(posting.Account) {
+				continue
+			}
+
+			// Include the net worth accounts, by default assets and liabilities. Liabilities are
+			// negative in hledger, so adding them as-is subtracts the debt.
+			if settings.IsNetWorthAccount(posting.Account) {
 				var amount float64
 				if len(posting.Amount) > 0 {
 					amount = convertAmount(posting.Amount[0].Quantity)
 				}
-
-				// Liabilities are negative
-				if strings.HasPrefix(posting.Account, "liabilities:") {
-					amount = -amount
-				}
-
-				dateNetWorth[tx.Date] += amount
+				dateChange[tx.Date] += amount
 			}
 		}
 	}
 
-	// Build result
-	var result []NetWorthPoint
-	for date, netWorth := range dateNetWorth {
+	dates := make([]string, 0, len(dateChange))
+	for date := range dateChange {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	// Build result, applying each day's change to the opening net worth
+	result := []NetWorthPoint{}
+	netWorth := opening
+	for _, date := range dates {
+		netWorth += dateChange[date]
 		result = append(result, NetWorthPoint{
 			Date:     date,
-			NetWorth: math.Round(netWorth*100) / 100,
+			NetWorth: p.roundAmount(netWorth),
 		})
 	}
 
-	// Sort by date
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].Date < result[j].Date
-	})
-
 	return result, nil
 }
 
@@ -699,7 +740,7 @@ func (p *Parser) GetCategoryTrendsFiltered(startDate, endDate string) ([]Categor
 	}
 
 	// Build result
-	var result []CategoryTrendData
+	result := []CategoryTrendData{}
 	for category, data := range categoryData {
 		// Sort by month
 		sort.Slice(data, func(i, j int) bool {
@@ -729,6 +770,7 @@ func (p *Parser) GetYearOverYearComparisonFiltered(startDate, endDate string) ([
 
 	// Map of month (MM) -> year (YYYY) -> amount
 	monthYearData := make(map[string]map[string]float64)
+	fiscalStart := p.fiscalYearStart()
 
 	for _, item := range spending {
 		// Extract month and year from item.Month (format: YYYY-MM)
@@ -737,7 +779,10 @@ func (p *Parser) GetYearOverYearComparisonFiltered(startDate, endDate string) ([
 		}
 
 		month := item.Month[5:7] // Get MM part
-		year := item.Month[:4]   // Get YYYY part
+		year := fiscalYear(item.Month, fiscalStart)
+		if year == "" {
+			continue
+		}
 
 		if monthYearData[month] == nil {
 			monthYearData[month] = make(map[string]float64)
@@ -747,7 +792,7 @@ func (p *Parser) GetYearOverYearComparisonFiltered(startDate, endDate string) ([
 	}
 
 	// Build result
-	var result []YearOverYearData
+	result := []YearOverYearData{}
 	for month, years := range monthYearData {
 		result = append(result, YearOverYearData{
 			Month: month,
@@ -755,9 +800,9 @@ func (p *Parser) GetYearOverYearComparisonFiltered(startDate, endDate string) ([
 		})
 	}
 
-	// Sort by month
+	// Sort by month, starting at the first month of the fiscal year
 	sort.Slice(result, func(i, j int) bool {
-		return result[i].Month < result[j].Month
+		return fiscalMonthIndex(result[i].Month, fiscalStart) < fiscalMonthIndex(result[j].Month, fiscalStart)
 	})
 
 	return result, nil
@@ -765,14 +810,15 @@ func (p *Parser) GetYearOverYearComparisonFiltered(startDate, endDate string) ([
 
 // Detail page filtered methods
 
-func (p *Parser) GetCategoryDetailFiltered(category, startDate, endDate string) (*CategoryDetailData, error) {
+// GetCategoryDetailFiltered is GetCategoryDetail restricted to a date range
+func (p *Parser) GetCategoryDetailFiltered(category, commentFilter, startDate, endDate string) (*CategoryDetailData, error) {
 	transactions, err := p.GetTransactionsFiltered(startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
 
 	// Filter transactions for this category
-	var filteredTxs []Transaction
+	filteredTxs := []Transaction{}
 	subcategoryTotals := make(map[string]float64)
 
 	for _, tx := range transactions {
@@ -788,19 +834,17 @@ func (p *Parser) GetCategoryDetailFiltered(category, startDate, endDate string)
 				postingCategory = parts[1]
 			}
 
-			if postingCategory == category {
+			if postingCategory == category && postingCommentMatches(posting, commentFilter) {
 				hasCategory = true
 
 				// Extract subcategory based on depth
-				subcategory := p.extractSubcategory(posting.Account, p.settings.SubcategoryDepth)
+				subcategory := p.extractSubcategory(posting.Account, p.currentSettings().SubcategoryDepth)
 
 				var amount float64
 				if len(posting.Amount) > 0 {
 					amount = convertAmount(posting.Amount[0].Quantity)
 				}
-				if amount < 0 {
-					amount = -amount
-				}
+				amount = p.expenseAmount(amount)
 
 				subcategoryTotals[subcategory] += amount
 			}
@@ -812,7 +856,7 @@ func (p *Parser) GetCategoryDetailFiltered(category, startDate, endDate string)
 	}
 
 	// Build breakdown
-	var breakdown []SubcategoryBreakdown
+	breakdown := []SubcategoryBreakdown{}
 	for name, amount := range subcategoryTotals {
 		breakdown = append(breakdown, SubcategoryBreakdown{
 			Name:   name,
@@ -825,13 +869,13 @@ func (p *Parser) GetCategoryDetailFiltered(category, startDate, endDate string)
 		return breakdown[i].Amount > breakdown[j].Amount
 	})
 
-	// Get budget history - filtering not fully implemented yet, using all history
-	budgetHistory, err := p.GetBudgetHistory()
+	// Budget history over the same window, so averages reflect only the selected months
+	budgetHistory, err := p.GetBudgetHistoryFiltered(startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
 
-	var categoryBudgetHistory []BudgetHistoryItem
+	categoryBudgetHistory := []BudgetHistoryItem{}
 	for _, item := range budgetHistory {
 		if item.Category == category {
 			categoryBudgetHistory = append(categoryBudgetHistory, item)
@@ -849,9 +893,10 @@ func (p *Parser) GetCategoryDetailFiltered(category, startDate, endDate string)
 func (p *Parser) GetTierDetailFiltered(tier, startDate, endDate string) (*TierDetailData, error) {
 	// Find the tier
 	var tierConfig *config.Tier
-	for i := range p.settings.Tiers {
-		if p.settings.Tiers[i].Name == tier {
-			tierConfig = &p.settings.Tiers[i]
+	settings := p.currentSettings()
+	for i := range settings.Tiers {
+		if settings.Tiers[i].Name == tier {
+			tierConfig = &settings.Tiers[i]
 			break
 		}
 	}
@@ -866,7 +911,7 @@ func (p *Parser) GetTierDetailFiltered(tier, startDate, endDate string) (*TierDe
 	}
 
 	// Filter transactions for categories in this tier
-	var filteredTxs []Transaction
+	filteredTxs := []Transaction{}
 	categoryTotals := make(map[string]float64)
 
 	for _, tx := range transactions {
@@ -882,21 +927,17 @@ func (p *Parser) GetTierDetailFiltered(tier, startDate, endDate string) (*TierDe
 				category = parts[1]
 			}
 
-			// Check if category is in this tier
-			for _, tierCat := range tierConfig.Categories {
-				if category == tierCat {
-					hasTierCategory = true
+			// Check if category is in this tier, by listing or pattern
+			if p.inTier(category, tier) {
+				hasTierCategory = true
 
-					var amount float64
-					if len(posting.Amount) > 0 {
-						amount = convertAmount(posting.Amount[0].Quantity)
-					}
-					if amount < 0 {
-						amount = -amount
-					}
-
-					categoryTotals[category] += amount
+				var amount float64
+				if len(posting.Amount) > 0 {
+					amount = convertAmount(posting.Amount[0].Quantity)
 				}
+				amount = p.expenseAmount(amount)
+
+				categoryTotals[category] += amount
 			}
 		}
 
@@ -906,7 +947,7 @@ func (p *Parser) GetTierDetailFiltered(tier, startDate, endDate string) (*TierDe
 	}
 
 	// Build breakdown by category (not subcategory for tiers)
-	var breakdown []SubcategoryBreakdown
+	breakdown := []SubcategoryBreakdown{}
 	for name, amount := range categoryTotals {
 		breakdown = append(breakdown, SubcategoryBreakdown{
 			Name:   name,
@@ -919,19 +960,16 @@ func (p *Parser) GetTierDetailFiltered(tier, startDate, endDate string) (*TierDe
 		return breakdown[i].Amount > breakdown[j].Amount
 	})
 
-	// Get budget history for all categories in this tier
-	budgetHistory, err := p.GetBudgetHistory()
+	// Get budget history for all categories in this tier within the window
+	budgetHistory, err := p.GetBudgetHistoryFiltered(startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
 
-	var tierBudgetHistory []BudgetHistoryItem
+	tierBudgetHistory := []BudgetHistoryItem{}
 	for _, item := range budgetHistory {
-		for _, tierCat := range tierConfig.Categories {
-			if item.Category == tierCat {
-				tierBudgetHistory = append(tierBudgetHistory, item)
-				break
-			}
+		if p.inTier(item.Category, tier) {
+			tierBudgetHistory = append(tierBudgetHistory, item)
 		}
 	}
 
@@ -950,48 +988,23 @@ func (p *Parser) GetAccountDetailFiltered(account, startDate, endDate string) (*
 	}
 
 	// Filter transactions for this account
-	var filteredTxs []Transaction
-	balanceMap := make(map[string]float64)
-
-	runningBalance := 0.0
-
+	filteredTxs := []Transaction{}
 	for _, tx := range transactions {
-		hasAccount := false
-		txAmount := 0.0
-
 		for _, posting := range tx.Postings {
 			if posting.Account == account {
-				hasAccount = true
-
-				var amount float64
-				if len(posting.Amount) > 0 {
-					amount = convertAmount(posting.Amount[0].Quantity)
-				}
-				txAmount += amount
+				filteredTxs = append(filteredTxs, tx)
+				break
 			}
 		}
-
-		if hasAccount {
-			filteredTxs = append(filteredTxs, tx)
-			runningBalance += txAmount
-			balanceMap[tx.Date] = runningBalance
-		}
 	}
 
-	// Build balance history
-	var balanceHistory []BalanceHistoryPoint
-	for date, balance := range balanceMap {
-		balanceHistory = append(balanceHistory, BalanceHistoryPoint{
-			Date:    date,
-			Balance: balance,
-		})
+	// Summing only in-window transactions would start the balance at zero; use historical
+	// balances so the opening balance is carried in
+	balanceHistory, err := p.GetAccountBalanceHistory(account, startDate, endDate)
+	if err != nil {
+		return nil, err
 	}
 
-	// Sort by date
-	sort.Slice(balanceHistory, func(i, j int) bool {
-		return balanceHistory[i].Date < balanceHistory[j].Date
-	})
-
 	return &AccountDetailData{
 		Account:        account,
 		Transactions:   filteredTxs,
@@ -1006,7 +1019,7 @@ func (p *Parser) GetIncomeDetailFiltered(incomeName, startDate, endDate string)
 	}
 
 	// Filter transactions for this income category
-	var filteredTxs []Transaction
+	filteredTxs := []Transaction{}
 	subcategoryTotals := make(map[string]float64)
 
 	for _, tx := range transactions {
@@ -1026,16 +1039,13 @@ func (p *Parser) GetIncomeDetailFiltered(incomeName, startDate, endDate string)
 				hasIncome = true
 
 				// Extract subcategory based on depth
-				subcategory := p.extractSubcategory(posting.Account, p.settings.SubcategoryDepth)
+				subcategory := p.extractSubcategory(posting.Account, p.currentSettings().SubcategoryDepth)
 
 				var amount float64
 				if len(posting.Amount) > 0 {
 					amount = convertAmount(posting.Amount[0].Quantity)
 				}
-				// For income, amounts are positive
-				if amount < 0 {
-					amount = -amount
-				}
+				amount = p.incomeAmount(amount)
 
 				subcategoryTotals[subcategory] += amount
 			}
@@ -1047,7 +1057,7 @@ func (p *Parser) GetIncomeDetailFiltered(incomeName, startDate, endDate string)
 	}
 
 	// Build breakdown
-	var breakdown []SubcategoryBreakdown
+	breakdown := []SubcategoryBreakdown{}
 	for name, amount := range subcategoryTotals {
 		breakdown = append(breakdown, SubcategoryBreakdown{
 			Name:   name,
@@ -0,0 +1,255 @@
+package hledger
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cwj5/minted/internal/config"
+)
+
+// reportedAccountsJournal spends on food and on a reimbursable trip in two months
+func reportedAccountsJournal() []Transaction {
+	return []Transaction{
+		expense("2024-04-03", "expenses:food", 100),
+		expense("2024-04-10", "expenses:reimbursable:travel", 500),
+		expense("2024-05-03", "expenses:food", 100),
+		expense("2024-05-10", "expenses:reimbursable:travel", 500),
+	}
+}
+
+func TestReportedAccountsInFilteredReports(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    []string // categories expected in the reports
+	}{
+		{"default", nil, nil, []string{"food", "reimbursable"}},
+		{"exclude", nil, []string{"expenses:reimbursable:"}, []string{"food"}},
+		{"include", []string{"expenses:food"}, nil, []string{"food"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := config.DefaultSettings()
+			settings.IncludeAccounts = tt.include
+			settings.ExcludeAccounts = tt.exclude
+			p := newTestParser(t, settings, reportedAccountsJournal()...)
+
+			history, err := p.GetBudgetHistoryFiltered("2024-04-01", "2024-06-01")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var historyCategories []string
+			for _, item := range history {
+				historyCategories = append(historyCategories, item.Category)
+			}
+			assertCategories(t, "budget history", historyCategories, tt.want)
+
+			spending, err := p.GetCategorySpendingFiltered("2024-04-01", "2024-06-01")
+			if err != nil {
+				t.Fatal(err)
+			}
+			seen := map[string]bool{}
+			var spendingCategories []string
+			for _, item := range spending {
+				if !seen[item.Category] {
+					seen[item.Category] = true
+					spendingCategories = append(spendingCategories, item.Category)
+				}
+			}
+			assertCategories(t, "category spending", spendingCategories, tt.want)
+
+			metrics, err := p.GetMonthlyMetricsFiltered("2024-04-01", "2024-06-01")
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantExpenses := 0.0
+			for _, category := range tt.want {
+				wantExpenses += map[string]float64{"food": 100, "reimbursable": 500}[category]
+			}
+			for _, month := range metrics {
+				if month.Expenses != wantExpenses {
+					t.Errorf("monthly metrics %s: expenses %v, want %v", month.Month, month.Expenses, wantExpenses)
+				}
+			}
+		})
+	}
+}
+
+// assertCategories compares category lists regardless of order
+func assertCategories(t *testing.T, report string, got, want []string) {
+	t.Helper()
+	got = append([]string(nil), got...)
+	sort.Strings(got)
+	if len(got) != len(want) {
+		t.Errorf("%s categories %v, want %v", report, got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("%s categories %v, want %v", report, got, want)
+			return
+		}
+	}
+}
+
+func TestDetailBudgetHistoryStaysInWindow(t *testing.T) {
+	all := []Transaction{
+		expense("2024-01-10", "expenses:groceries", 900),
+		expense("2024-02-10", "expenses:groceries", 800),
+		expense("2024-03-10", "expenses:groceries", 700),
+		expense("2024-04-10", "expenses:groceries", 100),
+		expense("2024-05-10", "expenses:groceries", 200),
+	}
+	window := append([]Transaction(nil), all[3:]...)
+	// The stub answers ranged queries (-b) with the window's transactions only, like hledger
+	dir := fakeHledger(t, nil)
+	for name, content := range map[string]string{"all.json": printJSON(t, all...), "window.json": printJSON(t, window...)} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stub := "#!/bin/sh\ndir=$(dirname \"$0\")\ncase \" $* \" in *\" -b \"*) cat \"$dir/window.json\" ;; *) cat \"$dir/all.json\" ;; esac\n"
+	if err := os.WriteFile(filepath.Join(dir, "hledger"), []byte(stub), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	p := NewParser("test.journal", config.DefaultSettings())
+	p.SetNow(func() time.Time { return testNow })
+
+	category, err := p.GetCategoryDetailFiltered("groceries", "", "2024-04-01", "2024-06-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tier, err := p.GetTierDetailFiltered("Essential", "2024-04-01", "2024-06-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, history := range map[string][]BudgetHistoryItem{"GetCategoryDetailFiltered": category.BudgetHistory, "GetTierDetailFiltered": tier.BudgetHistory} {
+		if len(history) != 1 {
+			t.Errorf("%s: budget history %+v, want groceries only", name, history)
+			continue
+		}
+		item := history[0]
+		if item.Average != 150 {
+			t.Errorf("%s: groceries average %v, want 150 from April and May only", name, item.Average)
+		}
+		for _, month := range item.Months {
+			if month.Month < "2024-04" || month.Month > "2024-05" {
+				t.Errorf("%s: budget history includes %s, outside the window", name, month.Month)
+			}
+		}
+	}
+}
+
+func TestNetWorthStartsFromOpeningBalance(t *testing.T) {
+	opening := txn("2024-01-01", "opening balances", posting("assets:checking", 5000), posting("equity:opening", -5000))
+	spend := expense("2024-05-10", "expenses:food", 100)
+	paid := income("2024-05-25", "income:salary", 2000)
+
+	tests := []struct {
+		name      string
+		print     []Transaction // what hledger print returns for the query
+		startDate string
+		want      []NetWorthPoint
+	}{
+		{"whole journal", []Transaction{opening, spend, paid}, "", []NetWorthPoint{
+			{Date: "2024-01-01", NetWorth: 5000},
+			{Date: "2024-05-10", NetWorth: 4900},
+			{Date: "2024-05-25", NetWorth: 6900},
+		}},
+		// The opening balance predates the window, so it comes from the balance report
+		{"window after the opening balance", []Transaction{spend, paid}, "2024-05-01", []NetWorthPoint{
+			{Date: "2024-05-10", NetWorth: 4900},
+			{Date: "2024-05-25", NetWorth: 6900},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := fakeHledger(t, map[string]string{
+				"print":   printJSON(t, tt.print...),
+				"balance": balanceJSON(t, balanceRow{"assets:checking", 5000}, balanceRow{"equity:opening", -5000}),
+			})
+			p := NewParser("test.journal", config.DefaultSettings())
+
+			var points []NetWorthPoint
+			var err error
+			if tt.startDate == "" {
+				points, err = p.GetNetWorthOverTime()
+			} else {
+				points, err = p.GetNetWorthOverTimeFiltered(tt.startDate, "2024-06-01")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(points, tt.want) {
+				t.Errorf("net worth %+v, want %+v", points, tt.want)
+			}
+
+			if tt.startDate != "" {
+				found := false
+				for _, args := range hledgerArgs(t, dir) {
+					found = found || strings.Contains(args, "balance") && strings.Contains(args, "-e "+tt.startDate)
+				}
+				if !found {
+					t.Errorf("no balance report up to %s among %v", tt.startDate, hledgerArgs(t, dir))
+				}
+			}
+		})
+	}
+}
+
+func TestNetWorthAccountSelection(t *testing.T) {
+	opening := txn("2024-01-01", "opening balances",
+		posting("assets:checking", 5000),
+		posting("assets:house", 200000),
+		posting("liabilities:mortgage", -150000),
+		posting("equity:opening", -55000))
+	spend := expense("2024-05-10", "expenses:food", 100)
+	balances := balanceJSON(t,
+		balanceRow{"assets:checking", 5000},
+		balanceRow{"assets:house", 200000},
+		balanceRow{"liabilities:mortgage", -150000},
+		balanceRow{"equity:opening", -55000})
+
+	tests := []struct {
+		name     string
+		accounts config.NetWorthAccounts
+		want     []float64 // net worth after the opening balances and after the purchase
+	}{
+		{"assets and liabilities by default", config.NetWorthAccounts{}, []float64{55000, 54900}},
+		{"equity included", config.NetWorthAccounts{Include: []string{"assets:", "liabilities:", "equity:"}}, []float64{0, -100}},
+		{"house excluded", config.NetWorthAccounts{Exclude: []string{"assets:house"}}, []float64{-145000, -145100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := config.DefaultSettings()
+			settings.NetWorthAccounts = tt.accounts
+
+			fakeHledger(t, map[string]string{"print": printJSON(t, opening, spend)})
+			points, err := NewParser("test.journal", settings).GetNetWorthOverTime()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []NetWorthPoint{{Date: "2024-01-01", NetWorth: tt.want[0]}, {Date: "2024-05-10", NetWorth: tt.want[1]}}
+			if !reflect.DeepEqual(points, want) {
+				t.Errorf("GetNetWorthOverTime %+v, want %+v", points, want)
+			}
+
+			// The window starts after the opening balances, which come from the balance report
+			fakeHledger(t, map[string]string{"print": printJSON(t, spend), "balance": balances})
+			points, err = NewParser("test.journal", settings).GetNetWorthOverTimeFiltered("2024-05-01", "2024-06-01")
+			if err != nil {
+				t.Fatal(err)
+			}
+			want = want[1:]
+			if !reflect.DeepEqual(points, want) {
+				t.Errorf("GetNetWorthOverTimeFiltered %+v, want %+v", points, want)
+			}
+		})
+	}
+}
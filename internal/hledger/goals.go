@@ -0,0 +1,89 @@
+package hledger
+
+import (
+	"math"
+	"time"
+)
+
+// goalContributionMonths is how many complete months are averaged for a goal's contribution rate
+const goalContributionMonths = 3
+
+// GoalProgress represents progress towards a savings goal
+type GoalProgress struct {
+	Name                string  `json:"name"`
+	Account             string  `json:"account"`
+	Current             float64 `json:"current"`
+	Target              float64 `json:"target"`
+	Percent             float64 `json:"percent"`
+	Deadline            string  `json:"deadline"`
+	MonthlyContribution float64 `json:"monthlyContribution"`
+	ProjectedCompletion string  `json:"projectedCompletion"` // empty when reached or not progressing
+	Achieved            bool    `json:"achieved"`
+}
+
+// GetGoalProgress returns the progress of each configured savings goal, projecting a
+// completion date from the average contribution over the last few complete months
+func (p *Parser) GetGoalProgress() ([]GoalProgress, error) {
+	progress := []GoalProgress{}
+	goals := p.currentSettings().Goals
+	if len(goals) == 0 {
+		return progress, nil
+	}
+
+	transactions, err := p.GetTransactions()
+	if err != nil {
+		return nil, err
+	}
+
+	now := p.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	windowStart := currentMonth.AddDate(0, -goalContributionMonths, 0).Format("2006-01")
+	windowEnd := currentMonth.Format("2006-01")
+
+	for _, goal := range goals {
+		current, err := p.GetAccountBalance(goal.Account)
+		if err != nil {
+			return nil, err
+		}
+
+		// Net inflow into the goal account during the contribution window
+		var contributed float64
+		for _, tx := range transactions {
+			month := getYearMonth(tx.Date)
+			if month < windowStart || month >= windowEnd {
+				continue
+			}
+			for _, posting := range tx.Postings {
+				if isAccountOrChild(posting.Account, goal.Account) && len(posting.Amount) > 0 {
+					contributed += convertAmount(posting.Amount[0].Quantity)
+				}
+			}
+		}
+		rate := contributed / goalContributionMonths
+
+		percent := 0.0
+		if goal.Target > 0 {
+			percent = (current / goal.Target) * 100
+		}
+
+		item := GoalProgress{
+			Name:                goal.Name,
+			Account:             goal.Account,
+			Current:             p.roundAmount(current),
+			Target:              goal.Target,
+			Percent:             roundRatio(percent),
+			Deadline:            goal.Deadline,
+			MonthlyContribution: p.roundAmount(rate),
+			Achieved:            current >= goal.Target,
+		}
+
+		if !item.Achieved && rate > 0 {
+			monthsNeeded := int(math.Ceil((goal.Target - current) / rate))
+			item.ProjectedCompletion = now.AddDate(0, monthsNeeded, 0).Format("2006-01-02")
+		}
+
+		progress = append(progress, item)
+	}
+
+	return progress, nil
+}
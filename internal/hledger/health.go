@@ -0,0 +1,37 @@
+package hledger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Version runs hledger --version, giving up after timeout, and returns its output line
+func (p *Parser) Version(timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "hledger", "--version").Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("hledger --version timed out after %s", timeout)
+	}
+	if err != nil {
+		return "", wrapExecError(err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CheckJournalFiles verifies each journal file can be opened for reading without parsing it
+func (p *Parser) CheckJournalFiles() error {
+	for _, file := range p.currentJournalFiles() {
+		f, err := os.Open(file)
+		if err != nil {
+			return fmt.Errorf("journal file not readable: %w", err)
+		}
+		f.Close()
+	}
+	return nil
+}
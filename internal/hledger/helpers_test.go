@@ -0,0 +1,147 @@
+package hledger
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cwj5/minted/internal/config"
+)
+
+// fakeHledgerScript prints <dir>/<arg>.out for the first argument that has such a file, or
+// fails with <dir>/<arg>.fail as stderr, and logs each invocation's arguments to args.log
+const fakeHledgerScript = `#!/bin/sh
+dir=$(dirname "$0")
+echo "$*" >> "$dir/args.log"
+for arg in "$@"; do
+	if [ -f "$dir/$arg.fail" ]; then
+		cat "$dir/$arg.fail" >&2
+		exit 1
+	fi
+	if [ -f "$dir/$arg.out" ]; then
+		cat "$dir/$arg.out"
+		exit 0
+	fi
+done
+`
+
+// fakeHledger puts an hledger stub first on PATH for the rest of the test and returns its
+// directory. outputs maps a subcommand such as print or balance to what the stub prints.
+func fakeHledger(t *testing.T, outputs map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hledger"), []byte(fakeHledgerScript), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for command, output := range outputs {
+		if err := os.WriteFile(filepath.Join(dir, command+".out"), []byte(output), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+// hledgerArgs returns the argument lists the stub in dir was invoked with, one per run
+func hledgerArgs(t *testing.T, dir string) []string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "args.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+// printJSON encodes transactions the way hledger print -O json does, numbering them in order
+func printJSON(t *testing.T, transactions ...Transaction) string {
+	t.Helper()
+	for i := range transactions {
+		transactions[i].Index = i + 1
+	}
+	data, err := json.Marshal(transactions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// balanceRow is one account line of hledger balance -O json output
+type balanceRow struct {
+	account string
+	amount  float64
+}
+
+// balanceJSON encodes dollar balances the way hledger balance -O json does
+func balanceJSON(t *testing.T, rows ...balanceRow) string {
+	t.Helper()
+	entries := []any{}
+	var total float64
+	for _, row := range rows {
+		depth := strings.Count(row.account, ":") + 1
+		entries = append(entries, []any{row.account, row.account, depth, usd(row.amount)})
+		total += row.amount
+	}
+	data, err := json.Marshal([]any{entries, usd(total)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// testNow is the clock newTestParser pins parsers to
+var testNow = time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC)
+
+// newTestParser returns a parser over a stub journal holding transactions, with its clock
+// pinned to testNow. A nil settings means the defaults.
+func newTestParser(t *testing.T, settings *config.Settings, transactions ...Transaction) *Parser {
+	t.Helper()
+	if settings == nil {
+		settings = config.DefaultSettings()
+	}
+	fakeHledger(t, map[string]string{"print": printJSON(t, transactions...)})
+	p := NewParser("test.journal", settings)
+	p.SetNow(func() time.Time { return testNow })
+	return p
+}
+
+// quantity builds a quantity with the given decimal places, e.g. quantity(12.5, 2)
+func quantity(amount float64, places int) Quantity {
+	return Quantity{
+		DecimalMantissa: int64(math.Round(amount * math.Pow(10, float64(places)))),
+		DecimalPlaces:   places,
+	}
+}
+
+// usd builds a dollar amount with two decimal places
+func usd(amount float64) []Amount {
+	return []Amount{{Commodity: "$", Quantity: quantity(amount, 2)}}
+}
+
+// posting builds a regular dollar posting
+func posting(account string, amount float64) Posting {
+	return Posting{Account: account, Amount: usd(amount), Type: PostingRegular}
+}
+
+// txn builds a cleared transaction
+func txn(date, description string, postings ...Posting) Transaction {
+	return Transaction{Date: date, Description: description, Status: StatusCleared, Postings: postings}
+}
+
+// expense builds a transaction paying amount from checking to an expense account
+func expense(date, account string, amount float64) Transaction {
+	return txn(date, "purchase", posting(account, amount), posting("assets:checking", -amount))
+}
+
+// income builds a transaction depositing amount from an income account into checking
+func income(date, account string, amount float64) Transaction {
+	return txn(date, "deposit", posting(account, -amount), posting("assets:checking", amount))
+}
+
+// approxEqual reports whether two amounts agree to within a cent fraction
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -0,0 +1,219 @@
+package hledger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// journalWriteMu serializes appends so concurrent requests can't interleave entries
+var journalWriteMu sync.Mutex
+
+// NewTransaction is a transaction submitted for appending to the journal
+type NewTransaction struct {
+	Date        string       `json:"date"`
+	Description string       `json:"description"`
+	Postings    []NewPosting `json:"postings"`
+}
+
+// NewPosting is one posting of a NewTransaction. A nil Amount lets hledger infer the
+// balancing amount; at most one posting may omit it.
+type NewPosting struct {
+	Account   string   `json:"account"`
+	Amount    *float64 `json:"amount"`
+	Commodity string   `json:"commodity"`
+}
+
+// formatPostingAmount renders an amount with its commodity the way hledger prints it:
+// symbols such as $ go before the number, codes such as EUR after it
+func formatPostingAmount(amount float64, commodity string) string {
+	number := strconv.FormatFloat(amount, 'f', -1, 64)
+	if commodity == "" {
+		return number
+	}
+	for _, r := range commodity {
+		if unicode.IsLetter(r) {
+			return number + " " + commodity
+		}
+	}
+	if amount < 0 {
+		return "-" + commodity + strconv.FormatFloat(-amount, 'f', -1, 64)
+	}
+	return commodity + number
+}
+
+// FormatJournalEntry validates a transaction and renders it as a journal entry ending in a
+// newline. Postings with amounts must balance to zero per commodity unless one posting
+// leaves its amount for hledger to infer.
+func FormatJournalEntry(tx NewTransaction) (string, error) {
+	if _, err := time.Parse("2006-01-02", tx.Date); err != nil {
+		return "", fmt.Errorf("invalid date %q: expected YYYY-MM-DD", tx.Date)
+	}
+	description := strings.TrimSpace(tx.Description)
+	if description == "" || strings.ContainsAny(description, "\r\n") {
+		return "", errors.New("description must be a single non-empty line")
+	}
+	if len(tx.Postings) < 2 {
+		return "", errors.New("a transaction needs at least two postings")
+	}
+
+	var b strings.Builder
+	b.WriteString(tx.Date + " " + description + "\n")
+
+	inferred := 0
+	sums := make(map[string]float64)
+	for _, posting := range tx.Postings {
+		account := strings.TrimSpace(posting.Account)
+		// Two spaces separate the account from the amount in journal syntax
+		if account == "" || strings.ContainsAny(account, "\t\r\n") || strings.Contains(account, "  ") {
+			return "", fmt.Errorf("invalid account name %q", posting.Account)
+		}
+		if strings.ContainsAny(posting.Commodity, "0123456789-.\"\r\n ") {
+			return "", fmt.Errorf("invalid commodity %q", posting.Commodity)
+		}
+
+		if posting.Amount == nil {
+			inferred++
+			b.WriteString("    " + account + "\n")
+			continue
+		}
+		sums[posting.Commodity] += *posting.Amount
+		b.WriteString("    " + account + "  " + formatPostingAmount(*posting.Amount, posting.Commodity) + "\n")
+	}
+
+	if inferred > 1 {
+		return "", errors.New("at most one posting may omit its amount")
+	}
+	if inferred == 0 {
+		for commodity, sum := range sums {
+			if math.Abs(sum) > 0.005 {
+				return "", fmt.Errorf("postings in %q do not balance: off by %g", commodity, sum)
+			}
+		}
+	}
+
+	return b.String(), nil
+}
+
+// AppendTransaction formats tx and appends it to the first journal file, separated from the
+// previous entry by a blank line. It returns the entry that was written.
+func (p *Parser) AppendTransaction(tx NewTransaction) (string, error) {
+	entry, err := FormatJournalEntry(tx)
+	if err != nil {
+		return "", err
+	}
+	journalFiles := p.currentJournalFiles()
+	if len(journalFiles) == 0 {
+		return "", errors.New("no journal file configured")
+	}
+
+	journalWriteMu.Lock()
+	defer journalWriteMu.Unlock()
+
+	f, err := os.OpenFile(journalFiles[0], os.O_RDWR|os.O_APPEND, 0)
+	if err != nil {
+		return "", fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	// Start on a fresh line with a blank line before the entry, unless the file is empty
+	prefix := ""
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err != nil && err != io.EOF {
+			return "", fmt.Errorf("failed to read journal: %w", err)
+		}
+		prefix = "\n"
+		if last[0] != '\n' {
+			prefix = "\n\n"
+		}
+	}
+
+	if _, err := f.WriteString(prefix + entry); err != nil {
+		return "", fmt.Errorf("failed to write journal: %w", err)
+	}
+	return entry, nil
+}
+
+// checkNamePattern restricts check names to plain words so they can't smuggle in flags
+var checkNamePattern = regexp.MustCompile(`^[a-z]+$`)
+
+// ErrInvalidCheckName is returned by CheckJournal for a check name that isn't a plain word
+var ErrInvalidCheckName = errors.New("invalid check name")
+
+// JournalCheck is the outcome of running hledger check
+type JournalCheck struct {
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors"`
+}
+
+// CheckJournal runs hledger check with the named checks (hledger's defaults when empty),
+// adding --strict when requested. A failing check is reported in the result rather than as
+// an error; the error return is for invalid check names or hledger not running at all.
+func (p *Parser) CheckJournal(checks []string, strict bool) (*JournalCheck, error) {
+	args := append(p.fileArgs(), "check")
+	if strict {
+		args = append(args, "--strict")
+	}
+	for _, check := range checks {
+		if !checkNamePattern.MatchString(check) {
+			return nil, fmt.Errorf("%w %q", ErrInvalidCheckName, check)
+		}
+		args = append(args, check)
+	}
+
+	result := &JournalCheck{Valid: true, Errors: []string{}}
+
+	_, err := exec.Command("hledger", args...).Output()
+	if err == nil {
+		return result, nil
+	}
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return nil, wrapExecError(err)
+	}
+
+	result.Valid = false
+	for _, line := range strings.Split(string(exitErr.Stderr), "\n") {
+		if line = strings.TrimRight(line, " \r"); strings.TrimSpace(line) != "" {
+			result.Errors = append(result.Errors, line)
+		}
+	}
+	if len(result.Errors) == 0 {
+		result.Errors = append(result.Errors, exitErr.Error())
+	}
+	return result, nil
+}
+
+// GetJournalFiles runs hledger files and returns every file hledger reads for the configured
+// journals, including those pulled in by include directives, in hledger's order. A file reached
+// from more than one journal is listed once.
+func (p *Parser) GetJournalFiles() ([]string, error) {
+	cmd := exec.Command("hledger", append(p.fileArgs(), "files")...)
+	output, err := cmd.Output()
+	if err != nil {
+		p.logCommandError(cmd, err)
+		return nil, wrapExecError(err)
+	}
+
+	files := []string{}
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		file := strings.TrimSpace(line)
+		if file == "" || seen[file] {
+			continue
+		}
+		seen[file] = true
+		files = append(files, file)
+	}
+	return files, nil
+}
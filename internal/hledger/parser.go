@@ -1,28 +1,47 @@
 package hledger
 
 import (
+	"bytes"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"math"
 	"os/exec"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cwj5/minted/internal/config"
+	"github.com/cwj5/minted/internal/logging"
 )
 
 // Account represents an hledger account
 type Account struct {
-	Name     string  `json:"aname"`
-	Balance  float64 `json:"aebalance"`
-	Currency string  `json:"currency"`
+	Name     string             `json:"aname"`
+	Balance  float64            `json:"aebalance"` // amount in the primary (first listed) commodity
+	Currency string             `json:"currency"`  // the primary commodity
+	Balances []CommodityBalance `json:"balances"`  // every commodity the account holds
+}
+
+// CommodityBalance is an account's balance in one commodity
+type CommodityBalance struct {
+	Commodity string  `json:"commodity"`
+	Amount    float64 `json:"amount"`
 }
 
 // Transaction represents a transaction
 type Transaction struct {
+	Index       int       `json:"tindex"` // position in the journal, stable across queries
 	Date        string    `json:"tdate"`
 	Description string    `json:"tdescription"`
+	Status      string    `json:"tstatus"` // Cleared, Pending or Unmarked
+	Comment     string    `json:"tcomment"`
+	Tags        Tags      `json:"ttags"`
 	Postings    []Posting `json:"tpostings"`
 }
 
@@ -31,6 +50,122 @@ type Posting struct {
 	Account string   `json:"paccount"`
 	Amount  []Amount `json:"pamount"`
 	Comment string   `json:"pcomment"`
+	Tags    Tags     `json:"ptags"`
+	Date    string   `json:"pdate,omitempty"` // posting date (date: tag), empty when it is the transaction's
+	Type    string   `json:"ptype"`           // RegularPosting, VirtualPosting or BalancedVirtualPosting
+	Virtual bool     `json:"virtual"`         // derived from Type: (parenthesized) or [bracketed] account
+}
+
+// Posting types as reported by hledger's JSON output
+const (
+	PostingRegular         = "RegularPosting"
+	PostingVirtual         = "VirtualPosting"
+	PostingBalancedVirtual = "BalancedVirtualPosting"
+)
+
+// UnmarshalJSON decodes a posting and derives Virtual from its hledger posting type
+func (posting *Posting) UnmarshalJSON(data []byte) error {
+	type rawPosting Posting // same fields without this method, to avoid recursing
+	var raw rawPosting
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*posting = Posting(raw)
+	posting.Virtual = posting.Virtual || posting.Type == PostingVirtual || posting.Type == PostingBalancedVirtual
+	return nil
+}
+
+// Tags holds hledger tag metadata as tag name -> value
+type Tags map[string]string
+
+// UnmarshalJSON accepts hledger's [[name, value], ...] pair list as well as a plain object
+func (t *Tags) UnmarshalJSON(data []byte) error {
+	var pairs [][]string
+	if err := json.Unmarshal(data, &pairs); err == nil {
+		tags := make(Tags, len(pairs))
+		for _, pair := range pairs {
+			if len(pair) == 0 {
+				continue
+			}
+			value := ""
+			if len(pair) > 1 {
+				value = pair[1]
+			}
+			tags[pair[0]] = value
+		}
+		*t = tags
+		return nil
+	}
+
+	var tags map[string]string
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return err
+	}
+	*t = tags
+	return nil
+}
+
+// HasTag reports whether the transaction or any of its postings carries the tag.
+// An empty value matches the tag regardless of its value.
+func (tx Transaction) HasTag(name, value string) bool {
+	if v, ok := tx.Tags[name]; ok && (value == "" || v == value) {
+		return true
+	}
+	for _, posting := range tx.Postings {
+		if v, ok := posting.Tags[name]; ok && (value == "" || v == value) {
+			return true
+		}
+	}
+	return false
+}
+
+// LargestPostingAmount returns the largest absolute amount among the transaction's postings
+func (tx Transaction) LargestPostingAmount() float64 {
+	largest := 0.0
+	for _, posting := range tx.Postings {
+		if len(posting.Amount) == 0 {
+			continue
+		}
+		if amount := math.Abs(convertAmount(posting.Amount[0].Quantity)); amount > largest {
+			largest = amount
+		}
+	}
+	return largest
+}
+
+// TotalAmount returns the sum of the transaction's positive postings, i.e. the amount it moves.
+// For a balanced transaction this equals the sum of its negative postings.
+func (tx Transaction) TotalAmount() float64 {
+	total := 0.0
+	for _, posting := range tx.Postings {
+		if len(posting.Amount) == 0 {
+			continue
+		}
+		if amount := convertAmount(posting.Amount[0].Quantity); amount > 0 {
+			total += amount
+		}
+	}
+	return total
+}
+
+// IncomeExpenseAmount returns the summed magnitude of the transaction's income and expense
+// postings, falling back to TotalAmount for transactions without any, such as transfers
+func (tx Transaction) IncomeExpenseAmount() float64 {
+	total := 0.0
+	found := false
+	for _, posting := range tx.Postings {
+		if len(posting.Amount) == 0 {
+			continue
+		}
+		if strings.HasPrefix(posting.Account, "income:") || strings.HasPrefix(posting.Account, "expenses:") {
+			total += math.Abs(convertAmount(posting.Amount[0].Quantity))
+			found = true
+		}
+	}
+	if !found {
+		return tx.TotalAmount()
+	}
+	return total
 }
 
 // Amount represents a monetary amount with commodity
@@ -47,11 +182,40 @@ type Quantity struct {
 
 // BudgetItem represents budget information for a spending category
 type BudgetItem struct {
-	Category      string  `json:"category"`
-	Average       float64 `json:"average"`
-	CurrentMonth  float64 `json:"currentMonth"`
-	Variance      float64 `json:"variance"`
-	PercentBudget float64 `json:"percentBudget"`
+	Category        string   `json:"category"`
+	Average         float64  `json:"average"`
+	CurrentMonth    float64  `json:"currentMonth"`
+	Variance        float64  `json:"variance"`
+	PercentBudget   float64  `json:"percentBudget"`
+	Source          string   `json:"source"`                    // BudgetSourceManual or BudgetSourceAverage
+	ProratedAverage *float64 `json:"proratedAverage,omitempty"` // average scaled to the elapsed share of the month
+	ProratedPercent *float64 `json:"proratedPercent,omitempty"` // current month spend vs ProratedAverage
+}
+
+// Budget sources reported in BudgetItem.Source
+const (
+	BudgetSourceManual  = "manual"
+	BudgetSourceAverage = "average"
+)
+
+// SpendingForecast projects a category's full-month spending from its run-rate so far
+type SpendingForecast struct {
+	Category         string  `json:"category"`
+	Current          float64 `json:"current"`
+	Projected        float64 `json:"projected"`
+	Average          float64 `json:"average"`
+	OverBudgetLikely bool    `json:"overBudgetLikely"`
+}
+
+// BudgetAlert is a category whose current-month spending exceeds its budget by more than the
+// alert threshold
+type BudgetAlert struct {
+	Category     string  `json:"category"`
+	Budget       float64 `json:"budget"`
+	CurrentMonth float64 `json:"currentMonth"`
+	AmountOver   float64 `json:"amountOver"`
+	PercentOver  float64 `json:"percentOver"` // how far over the budget, as a percent of it
+	Source       string  `json:"source"`      // BudgetSourceManual or BudgetSourceAverage
 }
 
 // MonthBudget represents spend for a category in a given month
@@ -84,6 +248,7 @@ type MonthlyMetrics struct {
 type CategorySpending struct {
 	Month    string  `json:"month"`
 	Category string  `json:"category"`
+	Tier     string  `json:"tier"` // empty when the category is not in any tier
 	Amount   float64 `json:"amount"`
 }
 
@@ -93,6 +258,13 @@ type NetWorthPoint struct {
 	NetWorth float64 `json:"netWorth"`
 }
 
+// NetWorthProjection holds the monthly net worth series and its linear extrapolation
+type NetWorthProjection struct {
+	Historical   []NetWorthPoint `json:"historical"`
+	Projected    []NetWorthPoint `json:"projected"`
+	MonthlySlope float64         `json:"monthlySlope"` // fitted change in net worth per month
+}
+
 // CategoryTrendData represents spending trend for a single category
 type CategoryTrendData struct {
 	Category string            `json:"category"`
@@ -101,8 +273,9 @@ type CategoryTrendData struct {
 
 // MonthAmountPair represents a month and amount
 type MonthAmountPair struct {
-	Month  string  `json:"month"`
-	Amount float64 `json:"amount"`
+	Month          string   `json:"month"`
+	Amount         float64  `json:"amount"`
+	SmoothedAmount *float64 `json:"smoothedAmount,omitempty"` // set by SmoothCategoryTrends
 }
 
 // YearOverYearData represents same-month comparison across years
@@ -146,25 +319,146 @@ type BalanceHistoryPoint struct {
 	Balance float64 `json:"balance"`
 }
 
+// ExpensePosting represents a single expense posting with its transaction context
+type ExpensePosting struct {
+	Date        string  `json:"date"`
+	Description string  `json:"description"`
+	Account     string  `json:"account"`
+	Amount      float64 `json:"amount"`
+}
+
 // Parser handles hledger journal parsing
 type Parser struct {
-	journalFile string
-	settings    *config.Settings
+	// journalFiles is replaced as a whole by SetJournalFiles, never modified in place
+	journalFilesMu sync.RWMutex
+	journalFiles   []string
+
+	// settings is replaced as a whole by UpdateSettings, never modified in place
+	settingsMu sync.RWMutex
+	settings   *config.Settings
+
+	// Decimal places and usage counts per commodity, learned from parsed transactions
+	precisionMu  sync.RWMutex
+	precision    map[string]int
+	commodityUse map[string]int
+
+	// logger may be swapped with SetLogger while queries run
+	logger atomic.Pointer[slog.Logger]
+
+	// now supplies the current date for month-to-date and "current month" logic
+	now func() time.Time
 }
 
-// NewParser creates a new hledger parser
+// NewParser creates a new hledger parser for a single journal file
 func NewParser(journalFile string, settings *config.Settings) *Parser {
-	return &Parser{
-		journalFile: journalFile,
-		settings:    settings,
+	return NewParserWithFiles([]string{journalFile}, settings)
+}
+
+// NewParserWithFiles creates a new hledger parser that reads several journal files as one dataset
+func NewParserWithFiles(journalFiles []string, settings *config.Settings) *Parser {
+	p := &Parser{
+		journalFiles: journalFiles,
+		settings:     settings,
+		now:          time.Now,
 	}
+	p.logger.Store(logging.Default())
+	return p
+}
+
+// SetLogger replaces the logger the parser reports hledger failures to, e.g. to capture them in tests
+func (p *Parser) SetLogger(logger *slog.Logger) {
+	p.logger.Store(logger)
+}
+
+// SetNow replaces the clock the parser takes the current date from, e.g. to pin it in tests.
+// Call it before the parser is shared between goroutines.
+func (p *Parser) SetNow(now func() time.Time) {
+	p.now = now
+}
+
+// Now returns the current time according to the parser's clock
+func (p *Parser) Now() time.Time {
+	return p.now()
+}
+
+// log returns the logger in effect
+func (p *Parser) log() *slog.Logger {
+	return p.logger.Load()
+}
+
+// logCommandError logs a failed hledger run with the journal files, arguments and hledger's stderr
+func (p *Parser) logCommandError(cmd *exec.Cmd, err error) {
+	attrs := []any{"files", p.currentJournalFiles(), "args", cmd.Args[1:], "error", err}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		attrs = append(attrs, "stderr", strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	p.log().Error("Error running hledger", attrs...)
+}
+
+// fileArgs returns one -f flag per journal file so hledger merges them
+func (p *Parser) fileArgs() []string {
+	journalFiles := p.currentJournalFiles()
+	args := make([]string, 0, len(journalFiles)*2)
+	for _, file := range journalFiles {
+		args = append(args, "-f", file)
+	}
+	return args
+}
+
+// SetJournalFile points the parser at a single journal file, e.g. after the configured path changes
+func (p *Parser) SetJournalFile(journalFile string) {
+	p.SetJournalFiles([]string{journalFile})
+}
+
+// SetJournalFiles replaces the journal files the parser reads as one dataset
+func (p *Parser) SetJournalFiles(journalFiles []string) {
+	p.journalFilesMu.Lock()
+	defer p.journalFilesMu.Unlock()
+	p.journalFiles = journalFiles
+}
+
+// currentJournalFiles returns the journal files in effect, safe against concurrent updates
+func (p *Parser) currentJournalFiles() []string {
+	p.journalFilesMu.RLock()
+	defer p.journalFilesMu.RUnlock()
+	return p.journalFiles
 }
 
 // UpdateSettings updates the parser's settings (used when settings change at runtime)
 func (p *Parser) UpdateSettings(settings *config.Settings) {
+	p.settingsMu.Lock()
+	defer p.settingsMu.Unlock()
 	p.settings = settings
 }
 
+// currentSettings returns the settings in effect, safe against a concurrent UpdateSettings
+func (p *Parser) currentSettings() *config.Settings {
+	p.settingsMu.RLock()
+	defer p.settingsMu.RUnlock()
+	return p.settings
+}
+
+// ErrHledgerNotFound is returned when the hledger executable cannot be found on PATH
+var ErrHledgerNotFound = errors.New("hledger is not installed or not on PATH")
+
+// wrapExecError maps a missing hledger binary to ErrHledgerNotFound and leaves other
+// exec failures, such as journal errors reported by hledger, unchanged
+func wrapExecError(err error) error {
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("%w: %v", ErrHledgerNotFound, err)
+	}
+	return err
+}
+
+// decodeJSON unmarshals hledger -O json output into v. Blank output, as printed for a journal
+// with no postings, is treated as no data and leaves v untouched.
+func decodeJSON(output []byte, v interface{}) error {
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(output, v)
+}
+
 // buildDateArgs constructs hledger command line args for date filtering
 func (p *Parser) buildDateArgs(startDate, endDate string) []string {
 	if startDate == "" || endDate == "" {
@@ -174,27 +468,106 @@ func (p *Parser) buildDateArgs(startDate, endDate string) []string {
 	return []string{"-b", startDate, "-e", endDate}
 }
 
+// Transaction statuses as reported by hledger's JSON output
+const (
+	StatusCleared  = "Cleared"
+	StatusPending  = "Pending"
+	StatusUnmarked = "Unmarked"
+)
+
+// statusArgs returns the hledger flag restricting balances to cleared postings when the
+// clearedOnly preference is set, so reported balances match the bank statement
+func (p *Parser) statusArgs() []string {
+	if p.currentSettings().GetPreferenceBool("clearedOnly", false) {
+		return []string{"--cleared"}
+	}
+	return []string{}
+}
+
+// costArgs returns the hledger -B flag when the costBasis preference is set, converting amounts
+// to their cost in the currency they were bought with. Priced commodities then come back as
+// that single currency.
+func (p *Parser) costArgs() []string {
+	if p.currentSettings().GetPreferenceBool("costBasis", false) {
+		return []string{"-B"}
+	}
+	return []string{}
+}
+
+// printArgs builds the arguments for hledger print within an optional date range
+func (p *Parser) printArgs(startDate, endDate string) []string {
+	args := append(p.fileArgs(), "print", "-O", "json")
+	args = append(args, p.buildDateArgs(startDate, endDate)...)
+	args = append(args, p.realArgs()...)
+	return append(args, p.costArgs()...)
+}
+
+// realArgs returns the hledger -R flag, which drops virtual postings, unless the includeVirtual
+// preference is set. Virtual postings usually mirror real ones (e.g. envelope budgeting), so
+// counting them in reports would double amounts.
+func (p *Parser) realArgs() []string {
+	if p.currentSettings().GetPreferenceBool("includeVirtual", false) {
+		return []string{}
+	}
+	return []string{"-R"}
+}
+
+// isBalanceSheetAccount reports whether an account is an asset or liability, including the
+// top-level assets and liabilities rows hledger reports at depth 1
+func isBalanceSheetAccount(name string) bool {
+	return name == "assets" || strings.HasPrefix(name, "assets:") ||
+		name == "liabilities" || strings.HasPrefix(name, "liabilities:")
+}
+
+// depthArgs returns the hledger --depth flag for rolling up subaccounts; depth 0 means no rollup
+func depthArgs(depth int) []string {
+	if depth <= 0 {
+		return []string{}
+	}
+	return []string{"--depth", strconv.Itoa(depth)}
+}
+
+// valuationArgs returns the hledger flags for the valuation preference. "market" converts
+// balances to the base commodity at market prices (-V), so every amount shares one commodity;
+// anything else keeps raw cost amounts.
+func (p *Parser) valuationArgs() []string {
+	if p.currentSettings().GetPreferenceString("valuation", "cost") == "market" {
+		return []string{"-V"}
+	}
+	return []string{}
+}
+
 // GetAccounts retrieves Assets and Liabilities accounts from hledger with their balances
 func (p *Parser) GetAccounts() ([]Account, error) {
-	cmd := exec.Command("hledger", "-f", p.journalFile, "balance", "--empty", "-O", "json")
+	return p.GetAccountsAtDepth(0)
+}
+
+// GetAccountsAtDepth retrieves Assets and Liabilities accounts rolled up to the given depth,
+// so depth 2 reports assets:bank instead of assets:bank:checking and assets:bank:savings.
+// Depth 0 means no rollup.
+func (p *Parser) GetAccountsAtDepth(depth int) ([]Account, error) {
+	args := append(p.fileArgs(), "balance", "--empty", "-O", "json")
+	args = append(args, depthArgs(depth)...)
+	args = append(args, p.valuationArgs()...)
+	args = append(args, p.statusArgs()...)
+	args = append(args, p.realArgs()...)
+
+	cmd := exec.Command("hledger", args...)
 	output, err := cmd.Output()
 	if err != nil {
-		log.Printf("Error running hledger balance: file=%s, error=%v", p.journalFile, err)
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			log.Printf("stderr: %s", string(exitErr.Stderr))
-		}
-		return nil, err
+		p.logCommandError(cmd, err)
+		return nil, wrapExecError(err)
 	}
 
 	// Balance JSON structure: [[account_entry1, account_entry2, ...], [total]]
 	var balanceData [][]interface{}
-	err = json.Unmarshal(output, &balanceData)
+	err = decodeJSON(output, &balanceData)
 	if err != nil {
-		log.Printf("Error parsing JSON: %v", err)
+		p.log().Error("Error parsing hledger JSON", "args", cmd.Args[1:], "error", err)
 		return nil, err
 	}
 
-	var accounts []Account
+	accounts := []Account{}
 
 	// The first element contains all the account entries
 	if len(balanceData) > 0 {
@@ -209,8 +582,9 @@ func (p *Parser) GetAccounts() ([]Account, error) {
 					continue
 				}
 
-				// Only include Assets and Liabilities accounts in the main accounts section
-				if !strings.HasPrefix(name, "assets:") && !strings.HasPrefix(name, "liabilities:") {
+				// Only include Assets and Liabilities accounts in the main accounts section, along
+				// with any other accounts configured to count towards net worth
+				if !isBalanceSheetAccount(name) && !p.currentSettings().IsNetWorthAccount(name) {
 					continue
 				}
 
@@ -234,10 +608,22 @@ func (p *Parser) GetAccounts() ([]Account, error) {
 					}
 				}
 
+				// Use the commodity hledger reports, falling back to the configured symbol
+				currency := p.currentSettings().CurrencySymbol()
+				if amounts, ok := itemArr[3].([]interface{}); ok && len(amounts) > 0 {
+					if amountObj, ok := amounts[0].(map[string]interface{}); ok {
+						if commodity, ok := amountObj["acommodity"].(string); ok && commodity != "" {
+							currency = commodity
+						}
+					}
+				}
+
+				amounts, _ := itemArr[3].([]interface{})
 				accounts = append(accounts, Account{
 					Name:     name,
 					Balance:  balance,
-					Currency: "$",
+					Currency: currency,
+					Balances: commodityBalances(amounts),
 				})
 			}
 		}
@@ -255,49 +641,114 @@ func min(a, b int) int {
 
 // GetTransactions retrieves recent transactions
 func (p *Parser) GetTransactions() ([]Transaction, error) {
-	cmd := exec.Command("hledger", "-f", p.journalFile, "print", "-O", "json")
+	cmd := exec.Command("hledger", p.printArgs("", "")...)
 	output, err := cmd.Output()
 	if err != nil {
-		log.Printf("Error running hledger print: %v", err)
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			log.Printf("stderr: %s", string(exitErr.Stderr))
-		}
-		return nil, err
+		p.logCommandError(cmd, err)
+		return nil, wrapExecError(err)
 	}
 
-	var transactions []Transaction
-	err = json.Unmarshal(output, &transactions)
+	transactions := []Transaction{}
+	err = decodeJSON(output, &transactions)
 	if err != nil {
-		log.Printf("Error parsing JSON: %v", err)
+		p.log().Error("Error parsing hledger JSON", "args", cmd.Args[1:], "error", err)
 		return nil, err
 	}
+	p.observePrecision(transactions)
 
 	return transactions, nil
 }
 
-// GetAccountBalance retrieves the balance of a specific account
+// GetAccountBalance retrieves the balance of a specific account, including its subaccounts
 func (p *Parser) GetAccountBalance(account string) (float64, error) {
-	cmd := exec.Command("hledger", "-f", p.journalFile, "balance", account, "-O", "json")
-	output, err := cmd.Output()
+	total, err := p.accountTotal(account, "")
 	if err != nil {
-		log.Printf("Error running hledger: %v", err)
 		return 0, err
 	}
+	return firstAmountValue(total), nil
+}
+
+// accountTotal returns the amounts hledger reports as the total of an account and its
+// subaccounts, counting postings before endDate when it is set
+func (p *Parser) accountTotal(account, endDate string) ([]interface{}, error) {
+	// Anchor the query so "assets:savings" doesn't also match "assets:savings-old" or infix names
+	query := "acct:^" + regexp.QuoteMeta(account) + "(:|$)"
+	args := append(p.fileArgs(), "balance", query, "-O", "json")
+	if endDate != "" {
+		args = append(args, "-e", endDate)
+	}
+	args = append(args, p.valuationArgs()...)
+	args = append(args, p.statusArgs()...)
+	args = append(args, p.realArgs()...)
 
-	var accounts []Account
-	err = json.Unmarshal(output, &accounts)
+	cmd := exec.Command("hledger", args...)
+	output, err := cmd.Output()
 	if err != nil {
-		log.Printf("Error parsing JSON: %v", err)
-		return 0, err
+		p.logCommandError(cmd, err)
+		return nil, wrapExecError(err)
+	}
+
+	// Balance JSON structure: [[account_entry1, ...], [total_amount1, ...]]
+	var balanceData [][]interface{}
+	err = decodeJSON(output, &balanceData)
+	if err != nil {
+		p.log().Error("Error parsing hledger JSON", "args", cmd.Args[1:], "error", err)
+		return nil, err
+	}
+
+	if len(balanceData) < 2 {
+		return nil, nil
+	}
+
+	return balanceData[1], nil
+}
+
+// firstAmountValue converts the first amount object in an hledger amount list to a float
+func firstAmountValue(amounts []interface{}) float64 {
+	if len(amounts) == 0 {
+		return 0
+	}
+	amountObj, ok := amounts[0].(map[string]interface{})
+	if !ok {
+		return 0
+	}
+	qty, ok := amountObj["aquantity"].(map[string]interface{})
+	if !ok {
+		return 0
 	}
+	mantissa, _ := qty["decimalMantissa"].(float64)
+	places, _ := qty["decimalPlaces"].(float64)
+	return mantissa / math.Pow(10, places)
+}
 
-	if len(accounts) > 0 {
-		// Convert balance to float
-		// For now, return 0 as we need better logic to handle the complex balance format
-		return 0, nil
+// firstAmountCommodity returns the commodity of the first amount object in an hledger amount list
+func firstAmountCommodity(amounts []interface{}) string {
+	if len(amounts) == 0 {
+		return ""
+	}
+	amountObj, ok := amounts[0].(map[string]interface{})
+	if !ok {
+		return ""
 	}
+	commodity, _ := amountObj["acommodity"].(string)
+	return commodity
+}
 
-	return 0, nil
+// commodityBalances converts every amount object in an hledger amount list, one per commodity
+func commodityBalances(amounts []interface{}) []CommodityBalance {
+	balances := []CommodityBalance{}
+	for _, item := range amounts {
+		amountObj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		commodity, _ := amountObj["acommodity"].(string)
+		qty, _ := amountObj["aquantity"].(map[string]interface{})
+		mantissa, _ := qty["decimalMantissa"].(float64)
+		places, _ := qty["decimalPlaces"].(float64)
+		balances = append(balances, CommodityBalance{Commodity: commodity, Amount: mantissa / math.Pow(10, places)})
+	}
+	return balances
 }
 
 // convertAmount converts hledger quantity to float64
@@ -309,6 +760,27 @@ func convertAmount(quantity Quantity) float64 {
 	return float64(quantity.DecimalMantissa) / divisor
 }
 
+// tierName returns the name of the tier a category belongs to, or "" when it has none
+func (p *Parser) tierName(category string) string {
+	if tier := p.currentSettings().GetTierForCategory(category); tier != nil {
+		return tier.Name
+	}
+	return ""
+}
+
+// postingCommentMatches reports whether a posting's comment contains filter, ignoring case.
+// Tags are part of the comment text, so a filter like "reimburse:" selects tagged postings.
+// An empty filter matches every posting.
+func postingCommentMatches(posting Posting, filter string) bool {
+	return filter == "" || strings.Contains(strings.ToLower(posting.Comment), strings.ToLower(filter))
+}
+
+// inTier reports whether a category belongs to the named tier, either listed explicitly or
+// matched by one of its patterns
+func (p *Parser) inTier(category, tier string) bool {
+	return tier != "" && p.tierName(category) == tier
+}
+
 // getYearMonth extracts YYYY-MM from date string YYYY-MM-DD
 func getYearMonth(dateStr string) string {
 	if len(dateStr) >= 7 {
@@ -317,9 +789,89 @@ func getYearMonth(dateStr string) string {
 	return dateStr
 }
 
-// getCurrentYearMonth returns the current month in YYYY-MM format
-func getCurrentYearMonth() string {
-	return time.Now().Format("2006-01")
+// postingMonth returns the YYYY-MM month a posting counts towards: its own posting date when
+// usePostingDates is set and it has one, otherwise the transaction's date
+func postingMonth(tx Transaction, posting Posting, usePostingDates bool) string {
+	if usePostingDates {
+		return getYearMonth(postingDate(tx, posting))
+	}
+	return getYearMonth(tx.Date)
+}
+
+// postingDate returns a posting's own date, falling back to its transaction's date
+func postingDate(tx Transaction, posting Posting) string {
+	if posting.Date != "" {
+		return posting.Date
+	}
+	return tx.Date
+}
+
+// currentYearMonth returns the current month by the parser's clock in YYYY-MM format
+func (p *Parser) currentYearMonth() string {
+	return p.Now().Format("2006-01")
+}
+
+// daysInMonth returns the number of days in t's month, accounting for leap years
+func daysInMonth(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+}
+
+// monthFractionElapsed returns the share of t's month elapsed, counting t's day as complete
+func monthFractionElapsed(t time.Time) float64 {
+	return float64(t.Day()) / float64(daysInMonth(t))
+}
+
+// ProrateBudget returns a copy of the budget items with averages scaled to the share of
+// the current month elapsed at now, so mid-month spending is judged against the expected fraction
+func (p *Parser) ProrateBudget(items []BudgetItem, now time.Time) []BudgetItem {
+	fraction := monthFractionElapsed(now)
+
+	prorated := make([]BudgetItem, len(items))
+	for i, item := range items {
+		average := p.roundAmount(item.Average * fraction)
+		percent := 0.0
+		if average > 0 {
+			percent = roundRatio((item.CurrentMonth / average) * 100)
+		}
+
+		item.ProratedAverage = &average
+		item.ProratedPercent = &percent
+		prorated[i] = item
+	}
+
+	return prorated
+}
+
+// isTransfer reports whether every posting in a transaction moves money between transfer accounts.
+// With the default asset and liability prefixes a transfer has no income or expense postings,
+// so skipping it only changes income and spending when transferAccounts lists such an account.
+func (p *Parser) isTransfer(tx Transaction) bool {
+	if len(tx.Postings) == 0 {
+		return false
+	}
+	for _, posting := range tx.Postings {
+		if !p.currentSettings().IsTransferAccount(posting.Account) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetTransfers returns the transactions excluded from income and spending as transfers
+func (p *Parser) GetTransfers(startDate, endDate string) ([]Transaction, error) {
+	transactions, err := p.GetTransactionsFiltered(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	transfers := []Transaction{}
+	for _, tx := range transactions {
+		if p.isTransfer(tx) {
+			transfers = append(transfers, tx)
+		}
+	}
+
+	return transfers, nil
 }
 
 // GetMonthlySpending aggregates expenses by category and month
@@ -329,17 +881,27 @@ func (p *Parser) GetMonthlySpending() (map[string]map[string]float64, error) {
 		return nil, err
 	}
 
+	settings := p.currentSettings()
+	usePostingDates := settings.GetPreferenceBool("usePostingDates", false)
+
 	// Map of month -> category -> total amount
 	monthlyByCategory := make(map[string]map[string]float64)
 
 	for _, tx := range transactions {
-		month := getYearMonth(tx.Date)
+		if p.isTransfer(tx) {
+			continue
+		}
 
 		for _, posting := range tx.Postings {
+			if !settings.IsReportedAccount(posting.Account) {
+				continue
+			}
+
 			// Only include Expenses accounts
 			if !strings.HasPrefix(posting.Account, "expenses:") {
 				continue
 			}
+			month := postingMonth(tx, posting, usePostingDates)
 
 			// Extract category (second part of account name)
 			parts := strings.Split(posting.Account, ":")
@@ -350,15 +912,10 @@ func (p *Parser) GetMonthlySpending() (map[string]map[string]float64, error) {
 				category = posting.Account
 			}
 
-			// Get amount (use absolute value for expenses)
+			// Keep the signed amount so refunds net against purchases
 			var amount float64
 			if len(posting.Amount) > 0 {
-				amount = convertAmount(posting.Amount[0].Quantity)
-			}
-
-			// Store positive value for expenses
-			if amount < 0 {
-				amount = -amount
+				amount = p.expenseAmount(convertAmount(posting.Amount[0].Quantity))
 			}
 
 			// Initialize month map if needed
@@ -370,36 +927,156 @@ func (p *Parser) GetMonthlySpending() (map[string]map[string]float64, error) {
 		}
 	}
 
+	clampMonthlyTotals(monthlyByCategory)
+
 	return monthlyByCategory, nil
 }
 
-// removeOutliers removes the highest and lowest values from a slice using IQR method
-func removeOutliers(values []float64) []float64 {
-	if len(values) <= 2 {
-		return values
+// minMonthsForAverage returns how many months of history a category needs before it is averaged
+func (p *Parser) minMonthsForAverage() int {
+	minMonths := p.currentSettings().GetPreferenceInt("minMonthsForAverage", 2)
+	if minMonths < 1 {
+		return 1
 	}
+	return minMonths
+}
 
-	sort.Float64s(values)
+// extremeMultiplier returns how many times the average a month may reach before it counts as an extreme
+func (p *Parser) extremeMultiplier() float64 {
+	multiplier := p.currentSettings().GetPreferenceFloat("extremeMultiplier", 2.0)
+	if multiplier <= 0 {
+		return 2.0
+	}
+	return multiplier
+}
 
-	// Calculate Q1 and Q3
-	q1Index := len(values) / 4
-	q3Index := (len(values) * 3) / 4
+// budgetLookbackMonths returns how many months before the current one budget averages draw on,
+// with 0 meaning all of them
+func (p *Parser) budgetLookbackMonths() int {
+	lookback := p.currentSettings().GetPreferenceInt("budgetLookbackMonths", 0)
+	if lookback < 0 {
+		return 0
+	}
+	return lookback
+}
+
+// inBudgetWindow reports whether month (YYYY-MM) comes before currentMonth and, when lookback is
+// positive, at most lookback calendar months before it. Months are compared by calendar
+// distance, so gaps in the journal don't stretch the window. Keys that aren't months never are.
+func inBudgetWindow(month, currentMonth string, lookback int) bool {
+	if month >= currentMonth {
+		return false
+	}
+	from, err := time.Parse("2006-01", month)
+	if err != nil {
+		return false
+	}
+	if lookback <= 0 {
+		return true
+	}
+	to, err := time.Parse("2006-01", currentMonth)
+	if err != nil {
+		return false
+	}
+	elapsed := (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+	return elapsed <= lookback
+}
 
-	if q1Index == q3Index {
-		// Not enough data points
+// expenseAmount converts an expense posting amount to spending, so purchases count positive and
+// refunds negative. The expenseSign preference says which sign the journal records purchases
+// with; hledger's convention, the default, is positive.
+func (p *Parser) expenseAmount(amount float64) float64 {
+	if strings.EqualFold(p.currentSettings().GetPreferenceString("expenseSign", "positive"), "negative") {
+		return -amount
+	}
+	return amount
+}
+
+// incomeAmount converts an income posting amount to earnings, so income counts positive and
+// reversals negative. The incomeSign preference says which sign the journal records income
+// with; hledger's convention, the default, is negative.
+func (p *Parser) incomeAmount(amount float64) float64 {
+	if strings.EqualFold(p.currentSettings().GetPreferenceString("incomeSign", "negative"), "positive") {
+		return amount
+	}
+	return -amount
+}
+
+// averageExcludingExtremes averages values after dropping those above mult times their mean.
+// Falls back to the plain mean if every value would be dropped.
+func averageExcludingExtremes(values []float64, mult float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	avg := sum / float64(len(values))
+
+	var filteredSum float64
+	var filteredCount int
+	for _, v := range values {
+		if v <= avg*mult {
+			filteredSum += v
+			filteredCount++
+		}
+	}
+	if filteredCount == 0 {
+		return avg
+	}
+
+	return filteredSum / float64(filteredCount)
+}
+
+// clampMonthlyTotals floors each category's monthly total at zero so a refund landing
+// in a month without matching purchases doesn't report negative spending
+func clampMonthlyTotals(monthly map[string]map[string]float64) {
+	for _, categories := range monthly {
+		for category, amount := range categories {
+			if amount < 0 {
+				categories[category] = 0
+			}
+		}
+	}
+}
+
+// quantile returns the q-th quantile (0 <= q <= 1) of sorted values, interpolating linearly
+// between the closest ranks so small samples still get distinct quartiles
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (pos-float64(lower))*(sorted[upper]-sorted[lower])
+}
+
+// removeOutliers drops values outside Q1 - 1.5*IQR and Q3 + 1.5*IQR without reordering the input.
+// Equal values give a zero IQR and are all kept, so the result is never empty for non-empty input.
+func removeOutliers(values []float64) []float64 {
+	if len(values) <= 2 {
 		return values
 	}
 
-	q1 := values[q1Index]
-	q3 := values[q3Index]
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	q1 := quantile(sorted, 0.25)
+	q3 := quantile(sorted, 0.75)
 	iqr := q3 - q1
 
 	// Lower and upper bounds (Q1 - 1.5*IQR, Q3 + 1.5*IQR)
 	lowerBound := q1 - 1.5*iqr
 	upperBound := q3 + 1.5*iqr
 
-	var filtered []float64
-	for _, v := range values {
+	filtered := []float64{}
+	for _, v := range sorted {
 		if v >= lowerBound && v <= upperBound {
 			filtered = append(filtered, v)
 		}
@@ -408,26 +1085,86 @@ func removeOutliers(values []float64) []float64 {
 	return filtered
 }
 
+// budgetAverage computes a category's budget baseline using the budgetMethod preference:
+// "mean" (default) averages after IQR outlier removal, "median" takes the middle value,
+// and "trimmed" drops the top and bottom 10% before averaging
+func (p *Parser) budgetAverage(amounts []float64) float64 {
+	switch p.currentSettings().GetPreferenceString("budgetMethod", "mean") {
+	case "median":
+		return median(amounts)
+	case "trimmed":
+		return trimmedMean(amounts, 0.1)
+	default:
+		return mean(removeOutliers(amounts))
+	}
+}
+
+// mean returns the arithmetic mean of values, or 0 for an empty slice
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// median returns the middle value of values without reordering the input
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// trimmedMean drops the given fraction of values from each end before averaging.
+// Inputs too small to trim at least one value per side are averaged as-is.
+func trimmedMean(values []float64, fraction float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	trim := int(float64(len(sorted)) * fraction)
+	return mean(sorted[trim : len(sorted)-trim])
+}
+
 // GetBudgetHistory returns per-category spend by month with percent vs average
 func (p *Parser) GetBudgetHistory() ([]BudgetHistoryItem, error) {
+	return p.GetBudgetHistoryAsOf(p.Now())
+}
+
+// GetBudgetHistoryAsOf returns the budget history as it looked on asOf: asOf's month is the
+// current month, and later months are left out of both the averages and the history.
+func (p *Parser) GetBudgetHistoryAsOf(asOf time.Time) ([]BudgetHistoryItem, error) {
 	monthlySpending, err := p.GetMonthlySpending()
 	if err != nil {
 		return nil, err
 	}
 
-	currentMonth := getCurrentYearMonth()
+	currentMonth := asOf.Format("2006-01")
 
-	// Collect all months and extract unique years
+	// Collect all months up to the current one and extract unique years
 	var allMonths []string
 	for month := range monthlySpending {
-		allMonths = append(allMonths, month)
+		if month <= currentMonth {
+			allMonths = append(allMonths, month)
+		}
 	}
 	sort.Strings(allMonths)
 
-	// Build category history excluding current month for averages
+	// Build category history from the months before the current one, within the lookback window
+	lookback := p.budgetLookbackMonths()
 	categoryHistory := make(map[string][]float64)
 	for month, categories := range monthlySpending {
-		if month == currentMonth {
+		if !inBudgetWindow(month, currentMonth, lookback) {
 			continue
 		}
 		for category, amount := range categories {
@@ -435,11 +1172,11 @@ func (p *Parser) GetBudgetHistory() ([]BudgetHistoryItem, error) {
 		}
 	}
 
-	var history []BudgetHistoryItem
+	history := []BudgetHistoryItem{}
 
 	for category, amounts := range categoryHistory {
-		if len(amounts) < 2 {
-			// Need at least two months to establish a reasonable average
+		if len(amounts) < p.minMonthsForAverage() {
+			// Need enough months to establish a reasonable average
 			continue
 		}
 
@@ -449,23 +1186,10 @@ func (p *Parser) GetBudgetHistory() ([]BudgetHistoryItem, error) {
 		}
 		avg := sum / float64(len(amounts))
 
-		// Calculate average excluding extremes (values > 2x average)
-		var filteredAmounts []float64
-		for _, v := range amounts {
-			if v <= avg*2 {
-				filteredAmounts = append(filteredAmounts, v)
-			}
-		}
-		avgExcludingExtremes := avg
-		if len(filteredAmounts) > 0 {
-			var filteredSum float64
-			for _, v := range filteredAmounts {
-				filteredSum += v
-			}
-			avgExcludingExtremes = filteredSum / float64(len(filteredAmounts))
-		}
+		// Calculate average excluding extremes (values > multiplier x average)
+		avgExcludingExtremes := averageExcludingExtremes(amounts, p.extremeMultiplier())
 
-		var monthData []MonthBudget
+		monthData := []MonthBudget{}
 		for _, month := range allMonths {
 			var amount float64
 			if categories, ok := monthlySpending[month]; ok {
@@ -486,16 +1210,16 @@ func (p *Parser) GetBudgetHistory() ([]BudgetHistoryItem, error) {
 			monthData = append(monthData, MonthBudget{
 				Month:           month,
 				Year:            year,
-				Amount:          math.Round(amount*100) / 100,
-				PercentOfBudget: math.Round(percent*100) / 100,
+				Amount:          p.roundAmount(amount),
+				PercentOfBudget: roundRatio(percent),
 				OverBudget:      amount > avg,
 			})
 		}
 
 		history = append(history, BudgetHistoryItem{
 			Category:                 category,
-			Average:                  math.Round(avg*100) / 100,
-			AverageExcludingExtremes: math.Round(avgExcludingExtremes*100) / 100,
+			Average:                  p.roundAmount(avg),
+			AverageExcludingExtremes: p.roundAmount(avgExcludingExtremes),
 			Months:                   monthData,
 		})
 	}
@@ -507,27 +1231,42 @@ func (p *Parser) GetBudgetHistory() ([]BudgetHistoryItem, error) {
 	return history, nil
 }
 
-// GetBudgetData calculates budget targets based on historical spending averages
+// GetBudgetData calculates budget targets from manual limits, falling back to historical spending averages
 func (p *Parser) GetBudgetData() ([]BudgetItem, error) {
+	return p.GetBudgetDataAsOf(p.Now())
+}
+
+// GetBudgetDataAsOf calculates the budget as it looked on asOf, comparing asOf's month against
+// averages over the months before it
+func (p *Parser) GetBudgetDataAsOf(asOf time.Time) ([]BudgetItem, error) {
 	monthlySpending, err := p.GetMonthlySpending()
 	if err != nil {
 		return nil, err
 	}
 
-	// Map of category -> list of monthly amounts
+	// Map of category -> list of monthly amounts, plus the amounts from the same calendar month
+	// in earlier years for the seasonal budget mode
 	categoryHistory := make(map[string][]float64)
-	currentMonth := getCurrentYearMonth()
+	sameMonthHistory := make(map[string][]float64)
+	currentMonth := asOf.Format("2006-01")
 
+	lookback := p.budgetLookbackMonths()
 	for month, categories := range monthlySpending {
-		// Skip current month from budget calculation
-		if month == currentMonth {
+		// Skip current and later months, and months older than the lookback window
+		if !inBudgetWindow(month, currentMonth, lookback) {
 			continue
 		}
-
+		// inBudgetWindow only passes keys that parse as months
+		parsed, _ := time.Parse("2006-01", month)
+		sameMonth := parsed.Month() == asOf.Month()
 		for category, amount := range categories {
 			categoryHistory[category] = append(categoryHistory[category], amount)
+			if sameMonth {
+				sameMonthHistory[category] = append(sameMonthHistory[category], amount)
+			}
 		}
 	}
+	seasonal := p.currentSettings().GetPreferenceString("budgetMode", "flat") == "seasonal"
 
 	// Get current month spending
 	currentMonthSpending := make(map[string]float64)
@@ -535,44 +1274,33 @@ func (p *Parser) GetBudgetData() ([]BudgetItem, error) {
 		currentMonthSpending = current
 	}
 
-	var budgetItems []BudgetItem
+	budgetItems := []BudgetItem{}
 
 	// Calculate averages and variances
 	for category, amounts := range categoryHistory {
-		// Only include categories with at least 2 months of history
-		if len(amounts) < 2 {
+		// Manual targets take precedence and are added below
+		if _, manual := p.currentSettings().Budgets[category]; manual {
 			continue
 		}
 
-		// Remove outliers
-		filtered := removeOutliers(amounts)
-
-		// Calculate average
-		var average float64
-		for _, v := range filtered {
-			average += v
+		// Only include categories with enough months of history
+		if len(amounts) < p.minMonthsForAverage() {
+			continue
 		}
-		average /= float64(len(filtered))
-
-		// Get current month spending
-		current := currentMonthSpending[category]
 
-		// Calculate variance
-		variance := current - average
-
-		// Calculate percent of budget
-		percentBudget := 0.0
-		if average > 0 {
-			percentBudget = (current / average) * 100
+		// Calculate average using the configured method. Seasonal budgets compare against the
+		// same month in earlier years once there are at least two of them.
+		average := p.budgetAverage(amounts)
+		if same := sameMonthHistory[category]; seasonal && len(same) >= 2 {
+			average = p.budgetAverage(same)
 		}
 
-		budgetItems = append(budgetItems, BudgetItem{
-			Category:      category,
-			Average:       math.Round(average*100) / 100, // Round to 2 decimals
-			CurrentMonth:  math.Round(current*100) / 100,
-			Variance:      math.Round(variance*100) / 100,
-			PercentBudget: math.Round(percentBudget*100) / 100,
-		})
+		budgetItems = append(budgetItems, p.newBudgetItem(category, average, currentMonthSpending[category], BudgetSourceAverage))
+	}
+
+	// Manual targets apply even to categories without enough history
+	for category, target := range p.currentSettings().Budgets {
+		budgetItems = append(budgetItems, p.newBudgetItem(category, target, currentMonthSpending[category], BudgetSourceManual))
 	}
 
 	// Sort by category name
@@ -583,6 +1311,104 @@ func (p *Parser) GetBudgetData() ([]BudgetItem, error) {
 	return budgetItems, nil
 }
 
+// newBudgetItem compares current month spending against a budget
+func (p *Parser) newBudgetItem(category string, budget, current float64, source string) BudgetItem {
+	// Calculate variance
+	variance := current - budget
+
+	// Calculate percent of budget
+	percentBudget := 0.0
+	if budget > 0 {
+		percentBudget = (current / budget) * 100
+	}
+
+	return BudgetItem{
+		Category:      category,
+		Average:       p.roundAmount(budget), // Round to the commodity's precision
+		CurrentMonth:  p.roundAmount(current),
+		Variance:      p.roundAmount(variance),
+		PercentBudget: roundRatio(percentBudget),
+		Source:        source,
+	}
+}
+
+// GetSpendingForecast projects each budgeted category's end-of-month total from the current run-rate
+func (p *Parser) GetSpendingForecast() ([]SpendingForecast, error) {
+	budgetItems, err := p.GetBudgetData()
+	if err != nil {
+		return nil, err
+	}
+
+	fraction := monthFractionElapsed(p.Now())
+
+	forecasts := []SpendingForecast{}
+	for _, item := range budgetItems {
+		// Guard against a zero elapsed fraction by treating current spend as the projection
+		projected := item.CurrentMonth
+		if fraction > 0 {
+			projected = item.CurrentMonth / fraction
+		}
+
+		forecasts = append(forecasts, SpendingForecast{
+			Category:         item.Category,
+			Current:          item.CurrentMonth,
+			Projected:        p.roundAmount(projected),
+			Average:          item.Average,
+			OverBudgetLikely: projected > item.Average,
+		})
+	}
+
+	return forecasts, nil
+}
+
+// alertThresholdPercent returns how far over budget, in percent, a category may go before it
+// is reported as an alert
+func (p *Parser) alertThresholdPercent() float64 {
+	threshold := p.currentSettings().GetPreferenceFloat("alertThresholdPercent", 0)
+	if threshold < 0 {
+		return 0
+	}
+	return threshold
+}
+
+// GetBudgetAlerts returns the categories whose current-month spending is over their manual or
+// average budget by more than the alertThresholdPercent preference, most over first
+func (p *Parser) GetBudgetAlerts() ([]BudgetAlert, error) {
+	budgetItems, err := p.GetBudgetData()
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := p.alertThresholdPercent()
+	alerts := []BudgetAlert{}
+	for _, item := range budgetItems {
+		if item.Average <= 0 {
+			continue
+		}
+		percentOver := (item.CurrentMonth - item.Average) / item.Average * 100
+		if percentOver <= threshold {
+			continue
+		}
+		alerts = append(alerts, BudgetAlert{
+			Category:     item.Category,
+			Budget:       item.Average,
+			CurrentMonth: item.CurrentMonth,
+			AmountOver:   p.roundAmount(item.CurrentMonth - item.Average),
+			PercentOver:  roundRatio(percentOver),
+			Source:       item.Source,
+		})
+	}
+
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].PercentOver != alerts[j].PercentOver {
+			return alerts[i].PercentOver > alerts[j].PercentOver
+		}
+		return alerts[i].Category < alerts[j].Category
+	})
+
+	return alerts, nil
+}
+
 // GetMonthlyMetrics returns income, expenses, and net worth for each month
 func (p *Parser) GetMonthlyMetrics() ([]MonthlyMetrics, error) {
 	transactions, err := p.GetTransactions()
@@ -590,6 +1416,8 @@ func (p *Parser) GetMonthlyMetrics() ([]MonthlyMetrics, error) {
 		return nil, err
 	}
 
+	settings := p.currentSettings()
+
 	// Map of month -> {income, expenses}
 	monthlyData := make(map[string]struct {
 		income   float64
@@ -597,22 +1425,30 @@ func (p *Parser) GetMonthlyMetrics() ([]MonthlyMetrics, error) {
 	})
 
 	for _, tx := range transactions {
+		if p.isTransfer(tx) {
+			continue
+		}
+
 		month := getYearMonth(tx.Date)
 
 		for _, posting := range tx.Postings {
+			if !settings.IsReportedAccount(posting.Account) {
+				continue
+			}
+
 			var amount float64
 			if len(posting.Amount) > 0 {
 				amount = convertAmount(posting.Amount[0].Quantity)
 			}
 
-			// Positive amounts for income (convert negative to positive), negative for expenses
+			// Count income and expenses as positive amounts, whatever the journal's sign convention
 			if strings.HasPrefix(posting.Account, "income:") {
 				data := monthlyData[month]
-				data.income += -amount // Income is negative in hledger, so negate it
+				data.income += p.incomeAmount(amount)
 				monthlyData[month] = data
 			} else if strings.HasPrefix(posting.Account, "expenses:") {
 				data := monthlyData[month]
-				data.expenses += amount
+				data.expenses += p.expenseAmount(amount)
 				monthlyData[month] = data
 			}
 		}
@@ -626,7 +1462,7 @@ func (p *Parser) GetMonthlyMetrics() ([]MonthlyMetrics, error) {
 	sort.Strings(months)
 
 	// Build metrics
-	var metrics []MonthlyMetrics
+	metrics := []MonthlyMetrics{}
 	for _, month := range months {
 		data := monthlyData[month]
 
@@ -643,10 +1479,10 @@ func (p *Parser) GetMonthlyMetrics() ([]MonthlyMetrics, error) {
 
 		metrics = append(metrics, MonthlyMetrics{
 			Month:       month,
-			Income:      math.Round(data.income*100) / 100,
-			Expenses:    math.Round(data.expenses*100) / 100,
+			Income:      p.roundAmount(data.income),
+			Expenses:    p.roundAmount(data.expenses),
 			NetWorth:    netWorth,
-			SavingsRate: math.Round(savingsRate*100) / 100,
+			SavingsRate: roundRatio(savingsRate),
 		})
 	}
 
@@ -685,10 +1521,7 @@ func (p *Parser) GetIncomeHistory() ([]BudgetHistoryItem, error) {
 				amount = convertAmount(posting.Amount[0].Quantity)
 			}
 
-			// Income is negative in hledger, so negate it for positive display
-			if amount < 0 {
-				amount = -amount
-			}
+			amount = p.incomeAmount(amount)
 
 			if monthlyIncome[month] == nil {
 				monthlyIncome[month] = make(map[string]float64)
@@ -704,7 +1537,7 @@ func (p *Parser) GetIncomeHistory() ([]BudgetHistoryItem, error) {
 	}
 	sort.Strings(allMonths)
 
-	currentMonth := getCurrentYearMonth()
+	currentMonth := p.currentYearMonth()
 
 	// Build category history excluding current month for averages
 	categoryHistory := make(map[string][]float64)
@@ -717,11 +1550,11 @@ func (p *Parser) GetIncomeHistory() ([]BudgetHistoryItem, error) {
 		}
 	}
 
-	var history []BudgetHistoryItem
+	history := []BudgetHistoryItem{}
 
 	for category, amounts := range categoryHistory {
-		if len(amounts) < 2 {
-			// Need at least two months to establish a reasonable average
+		if len(amounts) < p.minMonthsForAverage() {
+			// Need enough months to establish a reasonable average
 			continue
 		}
 
@@ -731,23 +1564,10 @@ func (p *Parser) GetIncomeHistory() ([]BudgetHistoryItem, error) {
 		}
 		avg := sum / float64(len(amounts))
 
-		// Calculate average excluding extremes
-		var filteredAmounts []float64
-		for _, v := range amounts {
-			if v <= avg*2 {
-				filteredAmounts = append(filteredAmounts, v)
-			}
-		}
-		avgExcludingExtremes := avg
-		if len(filteredAmounts) > 0 {
-			var filteredSum float64
-			for _, v := range filteredAmounts {
-				filteredSum += v
-			}
-			avgExcludingExtremes = filteredSum / float64(len(filteredAmounts))
-		}
+		// Calculate average excluding extremes (values > multiplier x average)
+		avgExcludingExtremes := averageExcludingExtremes(amounts, p.extremeMultiplier())
 
-		var monthData []MonthBudget
+		monthData := []MonthBudget{}
 		for _, month := range allMonths {
 			var amount float64
 			if categories, ok := monthlyIncome[month]; ok {
@@ -768,16 +1588,16 @@ func (p *Parser) GetIncomeHistory() ([]BudgetHistoryItem, error) {
 			monthData = append(monthData, MonthBudget{
 				Month:           month,
 				Year:            year,
-				Amount:          math.Round(amount*100) / 100,
-				PercentOfBudget: math.Round(percent*100) / 100,
+				Amount:          p.roundAmount(amount),
+				PercentOfBudget: roundRatio(percent),
 				OverBudget:      false, // Not applicable for income
 			})
 		}
 
 		history = append(history, BudgetHistoryItem{
 			Category:                 category,
-			Average:                  math.Round(avg*100) / 100,
-			AverageExcludingExtremes: math.Round(avgExcludingExtremes*100) / 100,
+			Average:                  p.roundAmount(avg),
+			AverageExcludingExtremes: p.roundAmount(avgExcludingExtremes),
 			Months:                   monthData,
 		})
 	}
@@ -796,17 +1616,27 @@ func (p *Parser) GetCategorySpending() ([]CategorySpending, error) {
 		return nil, err
 	}
 
+	settings := p.currentSettings()
+	usePostingDates := settings.GetPreferenceBool("usePostingDates", false)
+
 	// Map of month -> category -> amount
 	monthlyCategories := make(map[string]map[string]float64)
 
 	for _, tx := range transactions {
-		month := getYearMonth(tx.Date)
+		if p.isTransfer(tx) {
+			continue
+		}
 
 		for _, posting := range tx.Postings {
+			if !settings.IsReportedAccount(posting.Account) {
+				continue
+			}
+
 			// Only include Expenses accounts
 			if !strings.HasPrefix(posting.Account, "expenses:") {
 				continue
 			}
+			month := postingMonth(tx, posting, usePostingDates)
 
 			// Extract category
 			parts := strings.Split(posting.Account, ":")
@@ -819,12 +1649,7 @@ func (p *Parser) GetCategorySpending() ([]CategorySpending, error) {
 
 			var amount float64
 			if len(posting.Amount) > 0 {
-				amount = convertAmount(posting.Amount[0].Quantity)
-			}
-
-			// Store positive value for expenses
-			if amount < 0 {
-				amount = -amount
+				amount = p.expenseAmount(convertAmount(posting.Amount[0].Quantity))
 			}
 
 			if monthlyCategories[month] == nil {
@@ -834,14 +1659,17 @@ func (p *Parser) GetCategorySpending() ([]CategorySpending, error) {
 		}
 	}
 
+	clampMonthlyTotals(monthlyCategories)
+
 	// Build result
-	var result []CategorySpending
+	result := []CategorySpending{}
 	for month, categories := range monthlyCategories {
 		for category, amount := range categories {
 			result = append(result, CategorySpending{
 				Month:    month,
 				Category: category,
-				Amount:   math.Round(amount*100) / 100,
+				Tier:     p.tierName(category),
+				Amount:   p.roundAmount(amount),
 			})
 		}
 	}
@@ -888,22 +1716,19 @@ func (p *Parser) GetIncomeBreakdown() ([]CategorySpending, error) {
 				amount = convertAmount(posting.Amount[0].Quantity)
 			}
 
-			// Income amounts are typically negative in hledger, make them positive
-			if amount < 0 {
-				amount = -amount
-			}
+			amount = p.incomeAmount(amount)
 
 			incomeCategories[category] += amount
 		}
 	}
 
 	// Build result
-	var result []CategorySpending
+	result := []CategorySpending{}
 	for category, amount := range incomeCategories {
 		result = append(result, CategorySpending{
 			Month:    "", // Not monthly, so leave empty
 			Category: category,
-			Amount:   math.Round(amount*100) / 100,
+			Amount:   p.roundAmount(amount),
 		})
 	}
 
@@ -915,6 +1740,47 @@ func (p *Parser) GetIncomeBreakdown() ([]CategorySpending, error) {
 	return result, nil
 }
 
+// GetTopExpenses returns the n largest individual expense postings within a date range
+func (p *Parser) GetTopExpenses(n int, startDate, endDate string) ([]ExpensePosting, error) {
+	transactions, err := p.GetTransactionsFiltered(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Flatten to postings since one transaction can touch several expense accounts
+	postings := []ExpensePosting{}
+	for _, tx := range transactions {
+		for _, posting := range tx.Postings {
+			if !strings.HasPrefix(posting.Account, "expenses:") {
+				continue
+			}
+
+			var amount float64
+			if len(posting.Amount) > 0 {
+				amount = p.expenseAmount(convertAmount(posting.Amount[0].Quantity))
+			}
+
+			postings = append(postings, ExpensePosting{
+				Date:        tx.Date,
+				Description: tx.Description,
+				Account:     posting.Account,
+				Amount:      p.roundAmount(amount),
+			})
+		}
+	}
+
+	// Sort by absolute amount descending
+	sort.SliceStable(postings, func(i, j int) bool {
+		return math.Abs(postings[i].Amount) > math.Abs(postings[j].Amount)
+	})
+
+	if n >= 0 && len(postings) > n {
+		postings = postings[:n]
+	}
+
+	return postings, nil
+}
+
 // GetNetWorthOverTime calculates net worth for each day with transactions
 func (p *Parser) GetNetWorthOverTime() ([]NetWorthPoint, error) {
 	transactions, err := p.GetTransactions()
@@ -922,6 +1788,8 @@ func (p *Parser) GetNetWorthOverTime() ([]NetWorthPoint, error) {
 		return nil, err
 	}
 
+	settings := p.currentSettings()
+
 	// Track cumulative balance by account
 	accountBalances := make(map[string]float64)
 	dailyNetWorth := make(map[string]float64)
@@ -940,6 +1808,10 @@ func (p *Parser) GetNetWorthOverTime() ([]NetWorthPoint, error) {
 
 		// Accumulate balances
 		for _, posting := range tx.Postings {
+			if !settings.IsReportedAccount(posting.Account) {
+				continue
+			}
+
 			var amount float64
 			if len(posting.Amount) > 0 {
 				amount = convertAmount(posting.Amount[0].Quantity)
@@ -947,20 +1819,15 @@ func (p *Parser) GetNetWorthOverTime() ([]NetWorthPoint, error) {
 			accountBalances[posting.Account] += amount
 		}
 
-		// Calculate and store net worth for this date
-		var totalAssets float64
-		var totalLiabilities float64
-
+		// Calculate and store net worth for this date. Liabilities are negative in hledger, so
+		// summing the balances subtracts the debt.
+		var netWorth float64
 		for account, balance := range accountBalances {
-			if strings.HasPrefix(account, "assets:") {
-				totalAssets += balance
-			} else if strings.HasPrefix(account, "liabilities:") {
-				totalLiabilities += -balance
+			if settings.IsNetWorthAccount(account) {
+				netWorth += balance
 			}
 		}
-
-		netWorth := totalAssets - totalLiabilities
-		dailyNetWorth[date] = math.Round(netWorth*100) / 100
+		dailyNetWorth[date] = p.roundAmount(netWorth)
 	}
 
 	// Get all unique dates and sort
@@ -971,7 +1838,7 @@ func (p *Parser) GetNetWorthOverTime() ([]NetWorthPoint, error) {
 	sort.Strings(dates)
 
 	// Build result with dates in order
-	var result []NetWorthPoint
+	result := []NetWorthPoint{}
 	for _, date := range dates {
 		result = append(result, NetWorthPoint{
 			Date:     date,
@@ -982,6 +1849,85 @@ func (p *Parser) GetNetWorthOverTime() ([]NetWorthPoint, error) {
 	return result, nil
 }
 
+// GetNetWorthProjection fits a least-squares line to month-end net worth and extrapolates
+// monthsAhead future month-end points from it
+func (p *Parser) GetNetWorthProjection(monthsAhead int) (*NetWorthProjection, error) {
+	points, err := p.GetNetWorthOverTime()
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the last point of each month as that month's value
+	historical := []NetWorthPoint{}
+	for _, point := range points {
+		if n := len(historical); n > 0 && getYearMonth(historical[n-1].Date) == getYearMonth(point.Date) {
+			historical[n-1] = point
+			continue
+		}
+		historical = append(historical, point)
+	}
+
+	projection := &NetWorthProjection{
+		Historical: historical,
+		Projected:  []NetWorthPoint{},
+	}
+	if len(historical) == 0 {
+		return projection, nil
+	}
+
+	// Use absolute month numbers as x so months without transactions don't distort the fit
+	var xs, ys []float64
+	for _, point := range historical {
+		month, err := time.Parse("2006-01", getYearMonth(point.Date))
+		if err != nil {
+			continue
+		}
+		xs = append(xs, float64(month.Year()*12+int(month.Month())-1))
+		ys = append(ys, point.NetWorth)
+	}
+	if len(xs) == 0 {
+		return projection, nil
+	}
+
+	slope, intercept := linearFit(xs, ys)
+	projection.MonthlySlope = p.roundAmount(slope)
+
+	lastX := int(xs[len(xs)-1])
+	for i := 1; i <= monthsAhead; i++ {
+		x := lastX + i
+		// Day 0 of the following month is the last day of month x
+		monthEnd := time.Date(x/12, time.Month(x%12+2), 0, 0, 0, 0, 0, time.UTC)
+		projection.Projected = append(projection.Projected, NetWorthPoint{
+			Date:     monthEnd.Format("2006-01-02"),
+			NetWorth: p.roundAmount(intercept + slope*float64(x)),
+		})
+	}
+
+	return projection, nil
+}
+
+// linearFit returns the least-squares slope and intercept for the points (xs[i], ys[i]).
+// With fewer than two distinct x values the slope is 0 and the intercept is the mean of ys.
+func linearFit(xs, ys []float64) (slope, intercept float64) {
+	n := float64(len(xs))
+	if n == 0 {
+		return 0, 0
+	}
+
+	meanX, meanY := mean(xs), mean(ys)
+	var covariance, variance float64
+	for i := range xs {
+		covariance += (xs[i] - meanX) * (ys[i] - meanY)
+		variance += (xs[i] - meanX) * (xs[i] - meanX)
+	}
+	if variance == 0 {
+		return 0, meanY
+	}
+
+	slope = covariance / variance
+	return slope, meanY - slope*meanX
+}
+
 // GetCategoryTrends returns spending trends for each category
 func (p *Parser) GetCategoryTrends() ([]CategoryTrendData, error) {
 	categorySpending, err := p.GetCategorySpending()
@@ -993,7 +1939,7 @@ func (p *Parser) GetCategoryTrends() ([]CategoryTrendData, error) {
 	tiers := make(map[string][]MonthAmountPair)
 	for _, spending := range categorySpending {
 		// Look up which tier this category belongs to
-		tier := p.settings.GetTierForCategory(spending.Category)
+		tier := p.currentSettings().GetTierForCategory(spending.Category)
 		tierName := spending.Category // default to category name if not in any tier
 		if tier != nil {
 			tierName = tier.Name
@@ -1019,7 +1965,7 @@ func (p *Parser) GetCategoryTrends() ([]CategoryTrendData, error) {
 	}
 
 	// Build result
-	var result []CategoryTrendData
+	result := []CategoryTrendData{}
 	for tierName, data := range tiers {
 		// Sort by month
 		sort.Slice(data, func(i, j int) bool {
@@ -1040,32 +1986,35 @@ func (p *Parser) GetCategoryTrends() ([]CategoryTrendData, error) {
 	return result, nil
 }
 
-// extractSubcategory extracts subcategory from account path based on depth setting
-// Example: "expenses:groceries:meat:beef" with depth=1 -> "groceries:meat"
-// with depth=2 -> "groceries:meat:beef"
+// extractSubcategory extracts subcategory from account path based on depth setting.
+// The account-type prefix (expenses, income, ...) is dropped and depth+1 segments are kept,
+// clamped to the segments available; negative depths count as 0. Examples:
+// "expenses:groceries:meat:beef" with depth=0 -> "groceries", depth=1 -> "groceries:meat",
+// depth=2 -> "groceries:meat:beef"; "income:salary:bonus" with depth=1 -> "salary:bonus".
+// A single-segment account such as "expenses" is returned unchanged.
 func (p *Parser) extractSubcategory(accountPath string, depth int) string {
 	parts := strings.Split(accountPath, ":")
-
-	// For expenses, skip first part (expenses)
-	if strings.HasPrefix(accountPath, "expenses:") && len(parts) > 1 {
-		endIndex := min(1+depth+1, len(parts))
-		return strings.Join(parts[1:endIndex], ":")
+	if len(parts) < 2 {
+		return accountPath
+	}
+	if depth < 0 {
+		depth = 0
 	}
 
-	// For other account types, include the type prefix
-	endIndex := min(depth+1, len(parts))
-	return strings.Join(parts[:endIndex], ":")
+	endIndex := min(1+depth+1, len(parts))
+	return strings.Join(parts[1:endIndex], ":")
 }
 
-// GetCategoryDetail returns detailed data for a specific category
-func (p *Parser) GetCategoryDetail(category string) (*CategoryDetailData, error) {
+// GetCategoryDetail returns detailed data for a specific category. A non-empty commentFilter
+// keeps only postings whose comment contains it (see postingCommentMatches).
+func (p *Parser) GetCategoryDetail(category, commentFilter string) (*CategoryDetailData, error) {
 	transactions, err := p.GetTransactions()
 	if err != nil {
 		return nil, err
 	}
 
 	// Filter transactions for this category
-	var filteredTxs []Transaction
+	filteredTxs := []Transaction{}
 	subcategoryTotals := make(map[string]float64)
 
 	for _, tx := range transactions {
@@ -1081,19 +2030,17 @@ func (p *Parser) GetCategoryDetail(category string) (*CategoryDetailData, error)
 				postingCategory = parts[1]
 			}
 
-			if postingCategory == category {
+			if postingCategory == category && postingCommentMatches(posting, commentFilter) {
 				hasCategory = true
 
 				// Extract subcategory based on depth
-				subcategory := p.extractSubcategory(posting.Account, p.settings.SubcategoryDepth)
+				subcategory := p.extractSubcategory(posting.Account, p.currentSettings().SubcategoryDepth)
 
 				var amount float64
 				if len(posting.Amount) > 0 {
 					amount = convertAmount(posting.Amount[0].Quantity)
 				}
-				if amount < 0 {
-					amount = -amount
-				}
+				amount = p.expenseAmount(amount)
 
 				subcategoryTotals[subcategory] += amount
 			}
@@ -1105,7 +2052,7 @@ func (p *Parser) GetCategoryDetail(category string) (*CategoryDetailData, error)
 	}
 
 	// Build breakdown
-	var breakdown []SubcategoryBreakdown
+	breakdown := []SubcategoryBreakdown{}
 	for name, amount := range subcategoryTotals {
 		breakdown = append(breakdown, SubcategoryBreakdown{
 			Name:   name,
@@ -1124,7 +2071,7 @@ func (p *Parser) GetCategoryDetail(category string) (*CategoryDetailData, error)
 		return nil, err
 	}
 
-	var categoryBudgetHistory []BudgetHistoryItem
+	categoryBudgetHistory := []BudgetHistoryItem{}
 	for _, item := range budgetHistory {
 		if item.Category == category {
 			categoryBudgetHistory = append(categoryBudgetHistory, item)
@@ -1143,9 +2090,10 @@ func (p *Parser) GetCategoryDetail(category string) (*CategoryDetailData, error)
 func (p *Parser) GetTierDetail(tierName string) (*TierDetailData, error) {
 	// Find the tier
 	var tier *config.Tier
-	for i := range p.settings.Tiers {
-		if p.settings.Tiers[i].Name == tierName {
-			tier = &p.settings.Tiers[i]
+	settings := p.currentSettings()
+	for i := range settings.Tiers {
+		if settings.Tiers[i].Name == tierName {
+			tier = &settings.Tiers[i]
 			break
 		}
 	}
@@ -1160,7 +2108,7 @@ func (p *Parser) GetTierDetail(tierName string) (*TierDetailData, error) {
 	}
 
 	// Filter transactions for categories in this tier
-	var filteredTxs []Transaction
+	filteredTxs := []Transaction{}
 	categoryTotals := make(map[string]float64)
 
 	for _, tx := range transactions {
@@ -1176,21 +2124,17 @@ func (p *Parser) GetTierDetail(tierName string) (*TierDetailData, error) {
 				category = parts[1]
 			}
 
-			// Check if category is in this tier
-			for _, tierCat := range tier.Categories {
-				if category == tierCat {
-					hasTierCategory = true
-
-					var amount float64
-					if len(posting.Amount) > 0 {
-						amount = convertAmount(posting.Amount[0].Quantity)
-					}
-					if amount < 0 {
-						amount = -amount
-					}
+			// Check if category is in this tier, by listing or pattern
+			if p.inTier(category, tierName) {
+				hasTierCategory = true
 
-					categoryTotals[category] += amount
+				var amount float64
+				if len(posting.Amount) > 0 {
+					amount = convertAmount(posting.Amount[0].Quantity)
 				}
+				amount = p.expenseAmount(amount)
+
+				categoryTotals[category] += amount
 			}
 		}
 
@@ -1200,7 +2144,7 @@ func (p *Parser) GetTierDetail(tierName string) (*TierDetailData, error) {
 	}
 
 	// Build breakdown by category (not subcategory for tiers)
-	var breakdown []SubcategoryBreakdown
+	breakdown := []SubcategoryBreakdown{}
 	for name, amount := range categoryTotals {
 		breakdown = append(breakdown, SubcategoryBreakdown{
 			Name:   name,
@@ -1219,13 +2163,10 @@ func (p *Parser) GetTierDetail(tierName string) (*TierDetailData, error) {
 		return nil, err
 	}
 
-	var tierBudgetHistory []BudgetHistoryItem
+	tierBudgetHistory := []BudgetHistoryItem{}
 	for _, item := range budgetHistory {
-		for _, tierCat := range tier.Categories {
-			if item.Category == tierCat {
-				tierBudgetHistory = append(tierBudgetHistory, item)
-				break
-			}
+		if p.inTier(item.Category, tierName) {
+			tierBudgetHistory = append(tierBudgetHistory, item)
 		}
 	}
 
@@ -1245,7 +2186,7 @@ func (p *Parser) GetAccountDetail(accountName string) (*AccountDetailData, error
 	}
 
 	// Filter transactions for this account
-	var filteredTxs []Transaction
+	filteredTxs := []Transaction{}
 	balanceMap := make(map[string]float64)
 
 	runningBalance := 0.0
@@ -1274,7 +2215,7 @@ func (p *Parser) GetAccountDetail(accountName string) (*AccountDetailData, error
 	}
 
 	// Build balance history
-	var balanceHistory []BalanceHistoryPoint
+	balanceHistory := []BalanceHistoryPoint{}
 	for date, balance := range balanceMap {
 		balanceHistory = append(balanceHistory, BalanceHistoryPoint{
 			Date:    date,
@@ -1302,7 +2243,7 @@ func (p *Parser) GetIncomeDetail(incomeName string) (*CategoryDetailData, error)
 	}
 
 	// Filter transactions for this income category
-	var filteredTxs []Transaction
+	filteredTxs := []Transaction{}
 	subcategoryTotals := make(map[string]float64)
 
 	for _, tx := range transactions {
@@ -1322,16 +2263,13 @@ func (p *Parser) GetIncomeDetail(incomeName string) (*CategoryDetailData, error)
 				hasIncome = true
 
 				// Extract subcategory based on depth
-				subcategory := p.extractSubcategory(posting.Account, p.settings.SubcategoryDepth)
+				subcategory := p.extractSubcategory(posting.Account, p.currentSettings().SubcategoryDepth)
 
 				var amount float64
 				if len(posting.Amount) > 0 {
 					amount = convertAmount(posting.Amount[0].Quantity)
 				}
-				// For income, amounts are positive
-				if amount < 0 {
-					amount = -amount
-				}
+				amount = p.incomeAmount(amount)
 
 				subcategoryTotals[subcategory] += amount
 			}
@@ -1343,7 +2281,7 @@ func (p *Parser) GetIncomeDetail(incomeName string) (*CategoryDetailData, error)
 	}
 
 	// Build breakdown
-	var breakdown []SubcategoryBreakdown
+	breakdown := []SubcategoryBreakdown{}
 	for name, amount := range subcategoryTotals {
 		breakdown = append(breakdown, SubcategoryBreakdown{
 			Name:   name,
@@ -1374,11 +2312,18 @@ func (p *Parser) GetYearOverYearComparison() ([]YearOverYearData, error) {
 	// Group by month (MM) and year
 	// Map of "MM" -> year -> total spending
 	monthComparison := make(map[string]map[string]float64)
+	fiscalStart := p.fiscalYearStart()
 
 	for _, spending := range categorySpending {
-		// Extract month (MM) from YYYY-MM
+		// Extract month (MM) from YYYY-MM, skipping malformed months
+		if len(spending.Month) < 7 {
+			continue
+		}
 		month := spending.Month[5:7] // Get "MM" part
-		year := spending.Month[:4]   // Get "YYYY" part
+		year := fiscalYear(spending.Month, fiscalStart)
+		if year == "" {
+			continue
+		}
 
 		if monthComparison[month] == nil {
 			monthComparison[month] = make(map[string]float64)
@@ -1388,7 +2333,7 @@ func (p *Parser) GetYearOverYearComparison() ([]YearOverYearData, error) {
 	}
 
 	// Build result sorted by month
-	var result []YearOverYearData
+	result := []YearOverYearData{}
 	for month := range monthComparison {
 		result = append(result, YearOverYearData{
 			Month: month,
@@ -1396,9 +2341,9 @@ func (p *Parser) GetYearOverYearComparison() ([]YearOverYearData, error) {
 		})
 	}
 
-	// Sort by month
+	// Sort by month, starting at the first month of the fiscal year
 	sort.Slice(result, func(i, j int) bool {
-		return result[i].Month < result[j].Month
+		return fiscalMonthIndex(result[i].Month, fiscalStart) < fiscalMonthIndex(result[j].Month, fiscalStart)
 	})
 
 	return result, nil
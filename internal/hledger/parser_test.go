@@ -0,0 +1,684 @@
+package hledger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cwj5/minted/internal/config"
+)
+
+// postingDateJournal has a card payment entered on April 28th whose rent posting clears on May 2nd
+func postingDateJournal() []Transaction {
+	rent := posting("expenses:rent", 1000)
+	rent.Date = "2024-05-02"
+	return []Transaction{
+		expense("2024-03-05", "expenses:rent", 1000),
+		txn("2024-04-28", "rent", rent, posting("liabilities:card", -1000)),
+		expense("2024-04-10", "expenses:food", 50),
+	}
+}
+
+func TestPostingDatesBucketing(t *testing.T) {
+	for _, tt := range []struct {
+		usePostingDates bool
+		wantMonth       string
+	}{
+		{false, "2024-04"},
+		{true, "2024-05"},
+	} {
+		settings := config.DefaultSettings()
+		settings.Preferences["usePostingDates"] = tt.usePostingDates
+		p := newTestParser(t, settings, postingDateJournal()...)
+
+		monthly, err := p.GetMonthlySpending()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := monthly[tt.wantMonth]["rent"]; got != 1000 {
+			t.Errorf("usePostingDates=%v: GetMonthlySpending %s rent = %v, want 1000", tt.usePostingDates, tt.wantMonth, got)
+		}
+
+		spending, err := p.GetCategorySpending()
+		if err != nil {
+			t.Fatal(err)
+		}
+		filtered, err := p.GetCategorySpendingFiltered("2024-03-01", "2024-06-01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, items := range map[string][]CategorySpending{"GetCategorySpending": spending, "GetCategorySpendingFiltered": filtered} {
+			if got := categoryMonthAmount(items, tt.wantMonth, "rent"); got != 1000 {
+				t.Errorf("usePostingDates=%v: %s %s rent = %v, want 1000", tt.usePostingDates, name, tt.wantMonth, got)
+			}
+		}
+
+		history, err := p.GetBudgetHistoryFiltered("2024-03-01", "2024-06-01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := false
+		for _, item := range history {
+			if item.Category != "rent" {
+				continue
+			}
+			for _, month := range item.Months {
+				if month.Month == tt.wantMonth && month.Amount == 1000 {
+					found = true
+				}
+			}
+		}
+		if !found {
+			t.Errorf("usePostingDates=%v: GetBudgetHistoryFiltered has no rent of 1000 in %s: %+v", tt.usePostingDates, tt.wantMonth, history)
+		}
+	}
+}
+
+func TestPostingDatesFilteredRange(t *testing.T) {
+	journal := postingDateJournal()
+	// The stub selects by transaction date as hledger print -b/-e does
+	byTxDate := func(startDate, endDate string) []Transaction {
+		var selected []Transaction
+		for _, tx := range journal {
+			if tx.Date >= startDate && tx.Date < endDate {
+				selected = append(selected, tx)
+			}
+		}
+		return selected
+	}
+
+	tests := []struct {
+		name            string
+		usePostingDates bool
+		startDate       string
+		endDate         string
+		want            map[string]float64 // month/category -> amount
+	}{
+		{"transaction dates in April", false, "2024-04-01", "2024-05-01", map[string]float64{"2024-04/rent": 1000, "2024-04/food": 50}},
+		{"posting dates in April", true, "2024-04-01", "2024-05-01", map[string]float64{"2024-04/food": 50}},
+		{"posting dates in May", true, "2024-05-01", "2024-06-01", map[string]float64{"2024-05/rent": 1000}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := fakeHledger(t, map[string]string{"print": printJSON(t, journal...)})
+			ranged := printJSON(t, byTxDate(tt.startDate, tt.endDate)...)
+			if err := os.WriteFile(filepath.Join(dir, "ranged.out"), []byte(ranged), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			script := `#!/bin/sh
+dir=$(dirname "$0")
+case " $* " in
+*" -b "*) cat "$dir/ranged.out" ;;
+*) cat "$dir/print.out" ;;
+esac
+`
+			if err := os.WriteFile(filepath.Join(dir, "hledger"), []byte(script), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			settings := config.DefaultSettings()
+			settings.Preferences["usePostingDates"] = tt.usePostingDates
+			settings.Preferences["minMonthsForAverage"] = 1 // each category has one month in range
+			p := NewParser("test.journal", settings)
+
+			spending, err := p.GetCategorySpendingFiltered(tt.startDate, tt.endDate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]float64{}
+			for _, item := range spending {
+				if item.Amount != 0 {
+					got[item.Month+"/"+item.Category] = item.Amount
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetCategorySpendingFiltered %v, want %v", got, tt.want)
+			}
+
+			history, err := p.GetBudgetHistoryFiltered(tt.startDate, tt.endDate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = map[string]float64{}
+			for _, item := range history {
+				for _, month := range item.Months {
+					if month.Amount != 0 {
+						got[month.Month+"/"+item.Category] = month.Amount
+					}
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetBudgetHistoryFiltered %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// categoryMonthAmount returns a category's amount in one month of a spending report
+func categoryMonthAmount(items []CategorySpending, month, category string) float64 {
+	for _, item := range items {
+		if item.Month == month && item.Category == category {
+			return item.Amount
+		}
+	}
+	return 0
+}
+
+func TestTransferAccountsExcludeSpending(t *testing.T) {
+	journal := func() []Transaction {
+		return []Transaction{
+			expense("2024-05-03", "expenses:food", 40),
+			// A payment to a family member routed through a clearing account
+			expense("2024-05-10", "expenses:transfers:family", 200),
+			txn("2024-05-12", "to savings", posting("assets:savings", 500), posting("assets:checking", -500)),
+		}
+	}
+	tests := []struct {
+		name          string
+		transfers     []string
+		wantTransfers int
+		wantClearing  float64
+	}{
+		{"defaults", nil, 1, 200},
+		{"clearing account listed", []string{"assets:", "liabilities:", "expenses:transfers:"}, 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := config.DefaultSettings()
+			if tt.transfers != nil {
+				settings.TransferAccounts = tt.transfers
+			}
+			p := newTestParser(t, settings, journal()...)
+
+			monthly, err := p.GetMonthlySpending()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := monthly["2024-05"]["transfers"]; got != tt.wantClearing {
+				t.Errorf("GetMonthlySpending transfers = %v, want %v", got, tt.wantClearing)
+			}
+			if got := monthly["2024-05"]["food"]; got != 40 {
+				t.Errorf("GetMonthlySpending food = %v, want 40", got)
+			}
+
+			spending, err := p.GetCategorySpending()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := categoryMonthAmount(spending, "2024-05", "transfers"); got != tt.wantClearing {
+				t.Errorf("GetCategorySpending transfers = %v, want %v", got, tt.wantClearing)
+			}
+
+			transfers, err := p.GetTransfers("", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(transfers) != tt.wantTransfers {
+				t.Errorf("GetTransfers returned %d transactions, want %d: %+v", len(transfers), tt.wantTransfers, transfers)
+			}
+		})
+	}
+}
+
+func TestRefundsNetAgainstSpending(t *testing.T) {
+	tests := []struct {
+		name    string
+		journal []Transaction
+		want    float64
+	}{
+		{"refund nets against purchase", []Transaction{
+			expense("2024-05-03", "expenses:groceries", 100),
+			expense("2024-05-20", "expenses:groceries", -30),
+		}, 70},
+		{"refund without purchase clamps at zero", []Transaction{
+			expense("2024-04-03", "expenses:groceries", 100),
+			expense("2024-05-20", "expenses:groceries", -30),
+		}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestParser(t, nil, tt.journal...)
+
+			monthly, err := p.GetMonthlySpending()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := monthly["2024-05"]["groceries"]; got != tt.want {
+				t.Errorf("GetMonthlySpending groceries = %v, want %v", got, tt.want)
+			}
+
+			spending, err := p.GetCategorySpending()
+			if err != nil {
+				t.Fatal(err)
+			}
+			filtered, err := p.GetCategorySpendingFiltered("2024-04-01", "2024-06-01")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, items := range map[string][]CategorySpending{"GetCategorySpending": spending, "GetCategorySpendingFiltered": filtered} {
+				if got := categoryMonthAmount(items, "2024-05", "groceries"); got != tt.want {
+					t.Errorf("%s groceries = %v, want %v", name, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSignConventions(t *testing.T) {
+	// Each journal records a $100 purchase, a $30 refund, $2000 of pay and a $200 reversal in May
+	tests := []struct {
+		name               string
+		expenseSign        string
+		incomeSign         string
+		purchase, earnings float64 // the sign the journal records each with
+	}{
+		{"hledger defaults", "", "", 1, -1},
+		{"expenses negative", "negative", "", -1, -1},
+		{"income positive", "", "positive", 1, 1},
+		{"both flipped", "negative", "positive", -1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := config.DefaultSettings()
+			if tt.expenseSign != "" {
+				settings.Preferences["expenseSign"] = tt.expenseSign
+			}
+			if tt.incomeSign != "" {
+				settings.Preferences["incomeSign"] = tt.incomeSign
+			}
+			p := newTestParser(t, settings,
+				expense("2024-05-03", "expenses:groceries", 100*tt.purchase),
+				expense("2024-05-20", "expenses:groceries", -30*tt.purchase),
+				income("2024-05-01", "income:salary", -2000*tt.earnings),
+				income("2024-05-25", "income:salary", 200*tt.earnings),
+			)
+
+			monthly, err := p.GetMonthlySpending()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := monthly["2024-05"]["groceries"]; got != 70 {
+				t.Errorf("GetMonthlySpending groceries = %v, want the refund netted to 70", got)
+			}
+
+			metrics, err := p.GetMonthlyMetrics()
+			if err != nil {
+				t.Fatal(err)
+			}
+			filtered, err := p.GetMonthlyMetricsFiltered("2024-05-01", "2024-06-01")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, items := range map[string][]MonthlyMetrics{"GetMonthlyMetrics": metrics, "GetMonthlyMetricsFiltered": filtered} {
+				var may *MonthlyMetrics
+				for i := range items {
+					if items[i].Month == "2024-05" {
+						may = &items[i]
+					}
+				}
+				if may == nil {
+					t.Fatalf("%s has no 2024-05 entry: %+v", name, items)
+				}
+				if may.Expenses != 70 || may.Income != 1800 {
+					t.Errorf("%s May expenses %v, income %v, want 70 and 1800", name, may.Expenses, may.Income)
+				}
+			}
+		})
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	data := []byte(`{
+		"tdate": "2024-05-03", "tdescription": "flight", "tcomment": "trip: lisbon, project: talk\n",
+		"ttags": [["trip", "lisbon"], ["project", "talk"]],
+		"tpostings": [
+			{"paccount": "expenses:travel", "pcomment": "reimburse:\n", "ptags": [["reimburse", ""]], "pamount": []},
+			{"paccount": "assets:checking", "ptags": [], "pamount": []}
+		]
+	}`)
+	var tx Transaction
+	if err := json.Unmarshal(data, &tx); err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.Tags) != 2 || tx.Tags["trip"] != "lisbon" || tx.Tags["project"] != "talk" {
+		t.Errorf("transaction tags = %v, want trip:lisbon and project:talk", tx.Tags)
+	}
+
+	tests := []struct {
+		name, value string
+		want        bool
+	}{
+		{"trip", "", true},
+		{"trip", "lisbon", true},
+		{"trip", "porto", false},
+		{"project", "talk", true},
+		{"reimburse", "", true}, // on a posting
+		{"client", "", false},
+	}
+	for _, tt := range tests {
+		if got := tx.HasTag(tt.name, tt.value); got != tt.want {
+			t.Errorf("HasTag(%q, %q) = %v, want %v", tt.name, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestAccountsDepth(t *testing.T) {
+	leaves := []balanceRow{{"assets:bank:checking", 1000}, {"assets:bank:savings", 5000}, {"liabilities:card", -300}}
+	rolledUp := []balanceRow{{"assets:bank", 6000}, {"liabilities:card", -300}}
+	topLevel := []balanceRow{{"assets", 6000}, {"liabilities", -300}}
+	tests := []struct {
+		name      string
+		depth     int
+		outputs   map[string]string // the stub answers the first argument with an output
+		wantArgs  string
+		wantNames []string
+	}{
+		{"no rollup", 0, map[string]string{"balance": balanceJSON(t, leaves...)}, "", []string{"assets:bank:checking", "assets:bank:savings", "liabilities:card"}},
+		{"depth 2", 2, map[string]string{"2": balanceJSON(t, rolledUp...)}, "--depth 2", []string{"assets:bank", "liabilities:card"}},
+		{"depth 1", 1, map[string]string{"1": balanceJSON(t, topLevel...)}, "--depth 1", []string{"assets", "liabilities"}},
+	}
+	for _, tt := range tests {
+		for _, fetch := range []string{"GetAccountsAtDepth", "GetAccountsFiltered"} {
+			t.Run(tt.name+"/"+fetch, func(t *testing.T) {
+				dir := fakeHledger(t, tt.outputs)
+				p := NewParser("test.journal", config.DefaultSettings())
+
+				var accounts []Account
+				var err error
+				if fetch == "GetAccountsAtDepth" {
+					accounts, err = p.GetAccountsAtDepth(tt.depth)
+				} else {
+					accounts, err = p.GetAccountsFiltered("2024-01-01", "2024-07-01", tt.depth)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				args := hledgerArgs(t, dir)[0]
+				if tt.wantArgs == "" && strings.Contains(args, "--depth") {
+					t.Errorf("hledger args %q, want no --depth", args)
+				}
+				if tt.wantArgs != "" && !strings.Contains(args, tt.wantArgs) {
+					t.Errorf("hledger args %q, want %s", args, tt.wantArgs)
+				}
+
+				names := []string{}
+				for _, account := range accounts {
+					names = append(names, account.Name)
+				}
+				if !reflect.DeepEqual(names, tt.wantNames) {
+					t.Errorf("accounts %v, want %v", names, tt.wantNames)
+				}
+				if tt.depth > 0 && len(accounts) > 0 && accounts[0].Balance != 6000 {
+					t.Errorf("%s balance %v, want checking and savings merged into 6000", accounts[0].Name, accounts[0].Balance)
+				}
+			})
+		}
+	}
+}
+
+func TestAccountsKeepEveryCommodity(t *testing.T) {
+	// A brokerage account holding cash and shares, and a checking account holding only dollars
+	brokerage := []Amount{
+		{Commodity: "$", Quantity: quantity(250.75, 2)},
+		{Commodity: "AAPL", Quantity: quantity(10, 0)},
+	}
+	output, err := json.Marshal([]any{
+		[]any{
+			[]any{"assets:brokerage", "assets:brokerage", 2, brokerage},
+			[]any{"assets:checking", "assets:checking", 2, usd(1000)},
+		},
+		usd(1250.75),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Account{
+		{Name: "assets:brokerage", Balance: 250.75, Currency: "$", Balances: []CommodityBalance{
+			{Commodity: "$", Amount: 250.75},
+			{Commodity: "AAPL", Amount: 10},
+		}},
+		{Name: "assets:checking", Balance: 1000, Currency: "$", Balances: []CommodityBalance{
+			{Commodity: "$", Amount: 1000},
+		}},
+	}
+	tests := map[string]func(*Parser) ([]Account, error){
+		"GetAccounts":         func(p *Parser) ([]Account, error) { return p.GetAccounts() },
+		"GetAccountsFiltered": func(p *Parser) ([]Account, error) { return p.GetAccountsFiltered("2024-01-01", "2024-07-01", 0) },
+		"GetAccountsUpToDate": func(p *Parser) ([]Account, error) { return p.GetAccountsUpToDate("2024-07-01") },
+	}
+	for name, fetch := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeHledger(t, map[string]string{"balance": string(output)})
+			accounts, err := fetch(NewParser("test.journal", config.DefaultSettings()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(accounts, want) {
+				t.Errorf("accounts %+v, want %+v", accounts, want)
+			}
+		})
+	}
+}
+
+func TestEmptyJournalReturnsEmptySlices(t *testing.T) {
+	fakeHledger(t, map[string]string{"print": "[]", "balance": "[[],[]]", "register": "[]"})
+	p := NewParser("test.journal", config.DefaultSettings())
+	p.SetNow(func() time.Time { return testNow })
+
+	tests := map[string]func() (any, error){
+		"GetTransactions":           func() (any, error) { return p.GetTransactions() },
+		"GetAccounts":               func() (any, error) { return p.GetAccounts() },
+		"GetBudgetData":             func() (any, error) { return p.GetBudgetData() },
+		"GetBudgetHistory":          func() (any, error) { return p.GetBudgetHistory() },
+		"GetMonthlyMetrics":         func() (any, error) { return p.GetMonthlyMetrics() },
+		"GetCategorySpending":       func() (any, error) { return p.GetCategorySpending() },
+		"GetNetWorthOverTime":       func() (any, error) { return p.GetNetWorthOverTime() },
+		"GetCategoryTrends":         func() (any, error) { return p.GetCategoryTrends() },
+		"GetYearOverYearComparison": func() (any, error) { return p.GetYearOverYearComparison() },
+		"GetIncomeHistory":          func() (any, error) { return p.GetIncomeHistory() },
+	}
+	for name, run := range tests {
+		got, err := run()
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if v := reflect.ValueOf(got); v.Kind() != reflect.Slice || v.IsNil() || v.Len() != 0 {
+			t.Errorf("%s = %#v, want an empty non-nil slice", name, got)
+		}
+	}
+}
+
+func TestTransactionStatuses(t *testing.T) {
+	dir := fakeHledger(t, map[string]string{
+		"print": `[
+			{"tindex": 1, "tdate": "2024-05-01", "tdescription": "rent", "tstatus": "Cleared", "tpostings": []},
+			{"tindex": 2, "tdate": "2024-05-02", "tdescription": "card", "tstatus": "Pending", "tpostings": []},
+			{"tindex": 3, "tdate": "2024-05-03", "tdescription": "cash", "tstatus": "Unmarked", "tpostings": []}
+		]`,
+		"balance": "[[],[]]",
+	})
+
+	p := NewParser("test.journal", config.DefaultSettings())
+	transactions, err := p.GetTransactions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := []string{}
+	for _, tx := range transactions {
+		statuses = append(statuses, tx.Status)
+	}
+	if want := []string{StatusCleared, StatusPending, StatusUnmarked}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("statuses %v, want %v", statuses, want)
+	}
+
+	tests := []struct {
+		clearedOnly bool
+		wantFlag    bool
+	}{
+		{false, false},
+		{true, true},
+	}
+	for _, tt := range tests {
+		settings := config.DefaultSettings()
+		settings.Preferences["clearedOnly"] = tt.clearedOnly
+		p := NewParser("test.journal", settings)
+		os.Remove(filepath.Join(dir, "args.log"))
+		if _, err := p.GetAccounts(); err != nil {
+			t.Fatal(err)
+		}
+		args := hledgerArgs(t, dir)[0]
+		if got := strings.Contains(args, "--cleared"); got != tt.wantFlag {
+			t.Errorf("clearedOnly=%v: balance args %q, want --cleared %v", tt.clearedOnly, args, tt.wantFlag)
+		}
+	}
+}
+
+func TestExtractSubcategory(t *testing.T) {
+	tests := []struct {
+		account string
+		depth   int
+		want    string
+	}{
+		{"expenses:groceries:meat:beef", 0, "groceries"},
+		{"expenses:groceries:meat:beef", 1, "groceries:meat"},
+		{"expenses:groceries:meat:beef", 2, "groceries:meat:beef"},
+		{"expenses:groceries:meat:beef", 3, "groceries:meat:beef"},
+		{"expenses:groceries", 0, "groceries"},
+		{"expenses:groceries", 3, "groceries"},
+		{"expenses", 0, "expenses"},
+		{"expenses", 2, "expenses"},
+		{"expenses:groceries:meat", -1, "groceries"},
+		{"income:salary:bonus:q4", 0, "salary"},
+		{"income:salary:bonus:q4", 1, "salary:bonus"},
+		{"income:salary:bonus:q4", 2, "salary:bonus:q4"},
+		{"income:salary:bonus:q4", 3, "salary:bonus:q4"},
+		{"income:salary", 1, "salary"},
+		{"income", 3, "income"},
+	}
+	p := NewParser("test.journal", config.DefaultSettings())
+	for _, tt := range tests {
+		if got := p.extractSubcategory(tt.account, tt.depth); got != tt.want {
+			t.Errorf("extractSubcategory(%q, %d) = %q, want %q", tt.account, tt.depth, got, tt.want)
+		}
+	}
+}
+
+func TestCostBasisFlag(t *testing.T) {
+	tests := []struct {
+		costBasis any // nil leaves the default
+		want      bool
+	}{
+		{nil, false},
+		{false, false},
+		{true, true},
+	}
+	for _, tt := range tests {
+		settings := config.DefaultSettings()
+		if tt.costBasis != nil {
+			settings.Preferences["costBasis"] = tt.costBasis
+		}
+		dir := fakeHledger(t, map[string]string{"print": "[]"})
+		p := NewParser("test.journal", settings)
+
+		for _, args := range [][]string{p.printArgs("", ""), p.printArgs("2024-01-01", "2024-02-01")} {
+			if got := containsArg(args, "-B"); got != tt.want {
+				t.Errorf("costBasis=%v: printArgs %v, want -B %v", tt.costBasis, args, tt.want)
+			}
+		}
+
+		// Both print paths go through printArgs
+		if _, err := p.GetTransactions(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.GetTransactionsFiltered("2024-01-01", "2024-02-01"); err != nil {
+			t.Fatal(err)
+		}
+		for _, args := range hledgerArgs(t, dir) {
+			if got := containsArg(strings.Fields(args), "-B"); got != tt.want {
+				t.Errorf("costBasis=%v: hledger ran with %q, want -B %v", tt.costBasis, args, tt.want)
+			}
+		}
+	}
+}
+
+// containsArg reports whether args holds arg
+func containsArg(args []string, arg string) bool {
+	for _, a := range args {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPostingVirtual(t *testing.T) {
+	tests := []struct {
+		ptype string
+		want  bool
+	}{
+		{PostingRegular, false},
+		{PostingVirtual, true},
+		{PostingBalancedVirtual, true},
+	}
+	for _, tt := range tests {
+		var posting Posting
+		if err := json.Unmarshal([]byte(`{"paccount": "expenses:food", "ptype": "`+tt.ptype+`"}`), &posting); err != nil {
+			t.Fatal(err)
+		}
+		if posting.Virtual != tt.want {
+			t.Errorf("ptype %s: Virtual = %v, want %v", tt.ptype, posting.Virtual, tt.want)
+		}
+	}
+}
+
+func TestBalancedVirtualPostingsExcludedFromSpending(t *testing.T) {
+	groceries := expense("2024-05-03", "expenses:food", 80)
+	// Envelope budgeting mirrors the purchase with balanced virtual postings
+	envelope := groceries
+	envelope.Postings = append(append([]Posting(nil), groceries.Postings...),
+		Posting{Account: "expenses:food", Amount: usd(80), Type: PostingBalancedVirtual, Virtual: true},
+		Posting{Account: "assets:budget:food", Amount: usd(-80), Type: PostingBalancedVirtual, Virtual: true})
+
+	tests := []struct {
+		includeVirtual bool
+		want           float64
+	}{
+		{false, 80},
+		{true, 160},
+	}
+	for _, tt := range tests {
+		// The stub drops virtual postings when passed -R, as hledger does
+		dir := fakeHledger(t, nil)
+		for name, content := range map[string]string{"real.json": printJSON(t, groceries), "all.json": printJSON(t, envelope)} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		stub := "#!/bin/sh\ndir=$(dirname \"$0\")\ncase \" $* \" in *\" -R \"*) cat \"$dir/real.json\" ;; *) cat \"$dir/all.json\" ;; esac\n"
+		if err := os.WriteFile(filepath.Join(dir, "hledger"), []byte(stub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		settings := config.DefaultSettings()
+		settings.Preferences["includeVirtual"] = tt.includeVirtual
+		p := NewParser("test.journal", settings)
+
+		spending, err := p.GetCategorySpending()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := categoryMonthAmount(spending, "2024-05", "food"); got != tt.want {
+			t.Errorf("includeVirtual=%v: food spending %v, want %v", tt.includeVirtual, got, tt.want)
+		}
+		for _, args := range [][]string{p.printArgs("", ""), p.printArgs("2024-05-01", "2024-06-01")} {
+			if got := containsArg(args, "-R"); got == tt.includeVirtual {
+				t.Errorf("includeVirtual=%v: print args %v, want -R %v", tt.includeVirtual, args, !tt.includeVirtual)
+			}
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package hledger
+
+import (
+	"strings"
+	"time"
+)
+
+// WeekdaySpending represents expense totals for one day of the week
+type WeekdaySpending struct {
+	Weekday string  `json:"weekday"`
+	Total   float64 `json:"total"`
+	Average float64 `json:"average"` // total divided by the number of such weekdays in the range
+}
+
+// weekStart returns the first day of the week from the weekStart preference (default Monday).
+// It is the only place the preference is read; week-based reports pass the result down.
+func (p *Parser) weekStart() time.Weekday {
+	if strings.EqualFold(p.currentSettings().GetPreferenceString("weekStart", "monday"), "sunday") {
+		return time.Sunday
+	}
+	return time.Monday
+}
+
+// GetSpendingByWeekday buckets expense postings by day of the week. Averages divide by how many
+// times each weekday occurs between the first and last expense date. Transactions with
+// malformed dates are skipped.
+func (p *Parser) GetSpendingByWeekday(startDate, endDate string) ([]WeekdaySpending, error) {
+	transactions, err := p.GetTransactionsFiltered(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var totals [7]float64
+	var first, last time.Time
+
+	for _, tx := range transactions {
+		if p.isTransfer(tx) {
+			continue
+		}
+
+		date, err := time.Parse("2006-01-02", tx.Date)
+		if err != nil {
+			continue
+		}
+
+		hasExpense := false
+		for _, posting := range tx.Postings {
+			if !strings.HasPrefix(posting.Account, "expenses:") || len(posting.Amount) == 0 {
+				continue
+			}
+			totals[date.Weekday()] += p.expenseAmount(convertAmount(posting.Amount[0].Quantity))
+			hasExpense = true
+		}
+
+		if hasExpense {
+			if first.IsZero() || date.Before(first) {
+				first = date
+			}
+			if last.IsZero() || date.After(last) {
+				last = date
+			}
+		}
+	}
+
+	// Count occurrences of each weekday across the spanned days
+	var occurrences [7]int
+	if !first.IsZero() {
+		for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+			occurrences[day.Weekday()]++
+		}
+	}
+
+	start := p.weekStart()
+	result := make([]WeekdaySpending, 0, 7)
+	for i := 0; i < 7; i++ {
+		weekday := (start + time.Weekday(i)) % 7
+
+		average := 0.0
+		if occurrences[weekday] > 0 {
+			average = totals[weekday] / float64(occurrences[weekday])
+		}
+
+		result = append(result, WeekdaySpending{
+			Weekday: weekday.String(),
+			Total:   p.roundAmount(totals[weekday]),
+			Average: p.roundAmount(average),
+		})
+	}
+
+	return result, nil
+}
+
+// DayOfMonthSpending represents expense totals for one day of the month
+type DayOfMonthSpending struct {
+	Day     int     `json:"day"` // 1-31
+	Total   float64 `json:"total"`
+	Average float64 `json:"average"` // total divided by the number of months in the range having that day
+}
+
+// GetSpendingByDayOfMonth buckets expense postings by day of the month, e.g. to spot rent on
+// the 1st or payday spikes. Averages divide by how many times each day occurs between the
+// first and last expense date, so the 31st only counts months that have one. Transactions with
+// malformed dates are skipped.
+func (p *Parser) GetSpendingByDayOfMonth(startDate, endDate string) ([]DayOfMonthSpending, error) {
+	transactions, err := p.GetTransactionsFiltered(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var totals [32]float64 // indexed by day; 0 is unused
+	var first, last time.Time
+
+	for _, tx := range transactions {
+		if p.isTransfer(tx) {
+			continue
+		}
+
+		date, err := time.Parse("2006-01-02", tx.Date)
+		if err != nil {
+			continue
+		}
+
+		hasExpense := false
+		for _, posting := range tx.Postings {
+			if !strings.HasPrefix(posting.Account, "expenses:") || len(posting.Amount) == 0 {
+				continue
+			}
+			totals[date.Day()] += p.expenseAmount(convertAmount(posting.Amount[0].Quantity))
+			hasExpense = true
+		}
+
+		if hasExpense {
+			if first.IsZero() || date.Before(first) {
+				first = date
+			}
+			if last.IsZero() || date.After(last) {
+				last = date
+			}
+		}
+	}
+
+	// Count occurrences of each day of the month across the spanned days
+	var occurrences [32]int
+	if !first.IsZero() {
+		for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+			occurrences[day.Day()]++
+		}
+	}
+
+	result := make([]DayOfMonthSpending, 0, 31)
+	for day := 1; day <= 31; day++ {
+		average := 0.0
+		if occurrences[day] > 0 {
+			average = totals[day] / float64(occurrences[day])
+		}
+
+		result = append(result, DayOfMonthSpending{
+			Day:     day,
+			Total:   p.roundAmount(totals[day]),
+			Average: p.roundAmount(average),
+		})
+	}
+
+	return result, nil
+}
+
+// DailySpending represents total expenses on one calendar day
+type DailySpending struct {
+	Date   string  `json:"date"`
+	Amount float64 `json:"amount"`
+}
+
+// GetDailySpending sums expense postings per day with every day in the range present, zero
+// when nothing was spent, so heatmaps have no gaps. With a date range the series covers it
+// fully (the end date is exclusive, as in hledger); otherwise it runs from the first to the
+// last expense.
+func (p *Parser) GetDailySpending(startDate, endDate string) ([]DailySpending, error) {
+	transactions, err := p.GetTransactionsFiltered(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]float64)
+	var first, last time.Time
+	for _, tx := range transactions {
+		if p.isTransfer(tx) {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", tx.Date)
+		if err != nil {
+			continue
+		}
+
+		for _, posting := range tx.Postings {
+			if !strings.HasPrefix(posting.Account, "expenses:") || len(posting.Amount) == 0 {
+				continue
+			}
+			totals[tx.Date] += p.expenseAmount(convertAmount(posting.Amount[0].Quantity))
+			if first.IsZero() || date.Before(first) {
+				first = date
+			}
+			if last.IsZero() || date.After(last) {
+				last = date
+			}
+		}
+	}
+
+	if start, err := time.Parse("2006-01-02", startDate); err == nil {
+		if end, err := time.Parse("2006-01-02", endDate); err == nil {
+			first, last = start, end.AddDate(0, 0, -1)
+		}
+	}
+
+	result := []DailySpending{}
+	if first.IsZero() {
+		return result, nil
+	}
+	// AddDate handles month and year boundaries, so ranges spanning New Year are continuous
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		result = append(result, DailySpending{
+			Date:   date,
+			Amount: p.roundAmount(totals[date]),
+		})
+	}
+
+	return result, nil
+}
+
+// AverageDailySpend represents a month's expenses spread over its days
+type AverageDailySpend struct {
+	Month        string  `json:"month"`
+	Expenses     float64 `json:"expenses"`
+	Days         int     `json:"days"`
+	DailyAverage float64 `json:"dailyAverage"`
+}
+
+// GetAverageDailySpend divides each month's expenses by its number of days. Month lengths come
+// from the calendar, so February has 29 days in leap years; the current month only counts the
+// days elapsed so far, including today.
+func (p *Parser) GetAverageDailySpend(startDate, endDate string) ([]AverageDailySpend, error) {
+	metrics, err := p.GetMonthlyMetricsFiltered(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	now := p.Now()
+	currentMonth := now.Format("2006-01")
+
+	result := []AverageDailySpend{}
+	for _, m := range metrics {
+		monthStart, err := time.Parse("2006-01", m.Month)
+		if err != nil {
+			continue
+		}
+
+		// The day before the first of next month is the last day of this one
+		days := monthStart.AddDate(0, 1, -1).Day()
+		if m.Month == currentMonth {
+			days = now.Day()
+		}
+		if days < 1 {
+			days = 1
+		}
+
+		result = append(result, AverageDailySpend{
+			Month:        m.Month,
+			Expenses:     m.Expenses,
+			Days:         days,
+			DailyAverage: p.roundAmount(m.Expenses / float64(days)),
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,63 @@
+package hledger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cwj5/minted/internal/config"
+)
+
+func TestWeekStart(t *testing.T) {
+	// 2024-05-05 is a Sunday and 2024-05-06 the Monday after it
+	journal := []Transaction{
+		expense("2024-05-05", "expenses:food", 30),
+		expense("2024-05-06", "expenses:food", 20),
+	}
+	tests := []struct {
+		weekStart    any // nil leaves the default
+		want         time.Weekday
+		wantFirstDay string
+		wantWeeks    map[string]float64
+	}{
+		{nil, time.Monday, "Monday", map[string]float64{"2024-W18": 30, "2024-W19": 20}},
+		{"monday", time.Monday, "Monday", map[string]float64{"2024-W18": 30, "2024-W19": 20}},
+		{"sunday", time.Sunday, "Sunday", map[string]float64{"2024-W19": 50}},
+		{"Sunday", time.Sunday, "Sunday", map[string]float64{"2024-W19": 50}},
+	}
+	for _, tt := range tests {
+		settings := config.DefaultSettings()
+		if tt.weekStart != nil {
+			settings.Preferences["weekStart"] = tt.weekStart
+		}
+		p := newTestParser(t, settings, journal...)
+
+		if got := p.weekStart(); got != tt.want {
+			t.Errorf("weekStart=%v: weekStart() = %v, want %v", tt.weekStart, got, tt.want)
+		}
+
+		weekdays, err := p.GetSpendingByWeekday("2024-05-01", "2024-06-01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(weekdays) != 7 || weekdays[0].Weekday != tt.wantFirstDay {
+			t.Errorf("weekStart=%v: weekdays %+v, want 7 starting on %s", tt.weekStart, weekdays, tt.wantFirstDay)
+		}
+
+		weeks, err := p.GetWeeklyMetrics("2024-05-01", "2024-06-01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string]float64)
+		for _, week := range weeks {
+			got[week.Week] = week.Expenses
+		}
+		if len(got) != len(tt.wantWeeks) {
+			t.Errorf("weekStart=%v: weeks %v, want %v", tt.weekStart, got, tt.wantWeeks)
+		}
+		for week, amount := range tt.wantWeeks {
+			if got[week] != amount {
+				t.Errorf("weekStart=%v: week %s expenses %v, want %v", tt.weekStart, week, got[week], amount)
+			}
+		}
+	}
+}
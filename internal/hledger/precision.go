@@ -0,0 +1,87 @@
+package hledger
+
+import "math"
+
+// defaultDecimalPlaces is used until a commodity's precision has been seen in the journal
+const defaultDecimalPlaces = 2
+
+// observePrecision records the decimal places and usage of each commodity in transactions,
+// keeping the largest precision seen so that e.g. BTC amounts aren't cut to cents
+func (p *Parser) observePrecision(transactions []Transaction) {
+	p.precisionMu.Lock()
+	defer p.precisionMu.Unlock()
+
+	if p.precision == nil {
+		p.precision = make(map[string]int)
+		p.commodityUse = make(map[string]int)
+	}
+	for _, tx := range transactions {
+		for _, posting := range tx.Postings {
+			for _, amount := range posting.Amount {
+				if places, ok := p.precision[amount.Commodity]; !ok || amount.Quantity.DecimalPlaces > places {
+					p.precision[amount.Commodity] = amount.Quantity.DecimalPlaces
+				}
+				p.commodityUse[amount.Commodity]++
+			}
+		}
+	}
+}
+
+// decimalsFor returns the decimal places for a commodity: the commodityDecimals setting when
+// present, otherwise the precision seen in the journal, otherwise 2
+func (p *Parser) decimalsFor(commodity string) int {
+	if places, ok := p.currentSettings().CommodityDecimals[commodity]; ok && places >= 0 {
+		return places
+	}
+
+	p.precisionMu.RLock()
+	defer p.precisionMu.RUnlock()
+	if places, ok := p.precision[commodity]; ok {
+		return places
+	}
+	return defaultDecimalPlaces
+}
+
+// primaryCommodity returns the commodity aggregated amounts are reported in: the configured
+// currencySymbol when the journal uses it, otherwise the most frequently used commodity
+func (p *Parser) primaryCommodity() string {
+	symbol := p.currentSettings().CurrencySymbol()
+
+	p.precisionMu.RLock()
+	defer p.precisionMu.RUnlock()
+	if _, ok := p.commodityUse[symbol]; ok || len(p.commodityUse) == 0 {
+		return symbol
+	}
+
+	primary, most := "", -1
+	for commodity, uses := range p.commodityUse {
+		if uses > most || (uses == most && commodity < primary) {
+			primary, most = commodity, uses
+		}
+	}
+	return primary
+}
+
+// roundAmount rounds an aggregated amount to the roundingDecimals preference when it is set,
+// otherwise to the precision of the primary commodity
+func (p *Parser) roundAmount(amount float64) float64 {
+	if places := p.currentSettings().GetPreferenceInt("roundingDecimals", -1); places >= 0 {
+		return roundTo(amount, places)
+	}
+	return roundTo(amount, p.decimalsFor(p.primaryCommodity()))
+}
+
+// roundTo rounds a value to the given number of decimal places
+func roundTo(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
+
+// ratioDecimals is the precision of percentages and other ratios, which don't follow the
+// precision of any commodity
+const ratioDecimals = 2
+
+// roundRatio rounds a percentage or other ratio, such as months of runway, to ratioDecimals places
+func roundRatio(value float64) float64 {
+	return roundTo(value, ratioDecimals)
+}
@@ -0,0 +1,141 @@
+package hledger
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cwj5/minted/internal/config"
+)
+
+func TestRoundingDecimals(t *testing.T) {
+	tests := []struct {
+		name     string
+		decimals any // nil leaves the preference unset
+		want     float64
+	}{
+		{"unset follows the commodity", nil, 1234.57},
+		{"zero decimals", 0, 1235},
+		{"four decimals", 4, 1234.5678},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := config.DefaultSettings()
+			if tt.decimals != nil {
+				settings.Preferences["roundingDecimals"] = tt.decimals
+			}
+			p := NewParser("test.journal", settings)
+			if got := p.roundAmount(1234.56784); got != tt.want {
+				t.Errorf("roundAmount(1234.56784) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundingDecimalsInReports(t *testing.T) {
+	settings := config.DefaultSettings()
+	settings.Preferences["roundingDecimals"] = 0
+	p := newTestParser(t, settings, expense("2024-05-03", "expenses:food", 12.34), expense("2024-05-04", "expenses:food", 0.40))
+
+	spending, err := p.GetCategorySpending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := categoryMonthAmount(spending, "2024-05", "food"); got != 13 {
+		t.Errorf("food spending %v, want 13 with roundingDecimals 0", got)
+	}
+}
+
+func TestCommodityPrecision(t *testing.T) {
+	amount := func(date, commodity string, value float64, places int) Transaction {
+		post := func(account string, v float64) Posting {
+			return Posting{Account: account, Amount: []Amount{{Commodity: commodity, Quantity: quantity(v, places)}}, Type: PostingRegular}
+		}
+		return txn(date, "purchase", post("expenses:food", value), post("assets:wallet", -value))
+	}
+	tests := []struct {
+		name         string
+		commodity    string
+		places       int
+		first, other float64
+		want         float64
+	}{
+		{"zero decimals", "JPY", 0, 1500, 2499, 3999},
+		{"eight decimals", "BTC", 8, 0.12345678, 0.00000001, 0.12345679},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestParser(t, nil,
+				amount("2024-05-03", tt.commodity, tt.first, tt.places),
+				amount("2024-05-04", tt.commodity, tt.other, tt.places))
+
+			spending, err := p.GetCategorySpending()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := categoryMonthAmount(spending, "2024-05", "food"); got != tt.want {
+				t.Errorf("food spending %v, want %v", got, tt.want)
+			}
+			if got := p.decimalsFor(tt.commodity); got != tt.places {
+				t.Errorf("decimalsFor(%s) = %d, want %d", tt.commodity, got, tt.places)
+			}
+		})
+	}
+}
+
+func TestAccountTreeRoundsTotalsOnce(t *testing.T) {
+	settings := config.DefaultSettings()
+	settings.CommodityDecimals = map[string]int{"$": 0}
+	p := NewParser("test.journal", settings)
+
+	// Each child rounds to 0 on its own, but together they are worth 1
+	tree := p.buildAccountTree([]Account{
+		{Name: "expenses:food:coffee", Balance: 0.4, Currency: "$"},
+		{Name: "expenses:food:snacks", Balance: 0.4, Currency: "$"},
+	})
+	if len(tree) != 1 || len(tree[0].Children) != 1 {
+		t.Fatalf("unexpected tree %+v", tree)
+	}
+	if got := tree[0].Balance; got != 1 {
+		t.Errorf("expenses balance %v, want 1 from the unrounded 0.8", got)
+	}
+	food := tree[0].Children[0]
+	if food.Balance != 1 || food.Currency != "$" {
+		t.Errorf("expenses:food = %v %s, want 1 $", food.Balance, food.Currency)
+	}
+	for _, leaf := range food.Children {
+		if leaf.Balance != 0 {
+			t.Errorf("%s balance %v, want 0", leaf.FullName, leaf.Balance)
+		}
+	}
+}
+
+// TestAmountsRoundedThroughHelper fails when a file rounds with math.Round directly instead of
+// going through roundAmount or roundRatio, which would ignore the precision settings
+func TestAmountsRoundedThroughHelper(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") || file == "precision.go" {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(parsed, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "math" && sel.Sel.Name == "Round" {
+					t.Errorf("%s: math.Round outside precision.go; use roundAmount or roundRatio", fset.Position(sel.Pos()))
+				}
+			}
+			return true
+		})
+	}
+}
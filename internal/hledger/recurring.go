@@ -0,0 +1,185 @@
+package hledger
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// recurringAmountTolerance is how far a charge may stray from the typical amount
+	recurringAmountTolerance = 0.05
+	// recurringMinOccurrences is the fewest matching charges that count as recurring
+	recurringMinOccurrences = 3
+	// recurringMinMonthCoverage is the share of months between the first and last
+	// charge that must contain one for a monthly series
+	recurringMinMonthCoverage = 0.75
+)
+
+// RecurringTransaction represents a charge that repeats at a steady amount
+type RecurringTransaction struct {
+	Description   string   `json:"description"`
+	Cadence       string   `json:"cadence"` // "monthly" or "yearly"
+	TypicalAmount float64  `json:"typicalAmount"`
+	Occurrences   int      `json:"occurrences"`
+	FirstDate     string   `json:"firstDate"`
+	LastDate      string   `json:"lastDate"`
+	Accounts      []string `json:"accounts"`
+}
+
+// recurringCharge is one expense transaction considered for recurrence
+type recurringCharge struct {
+	date        time.Time
+	description string
+	amount      float64
+	accounts    []string
+}
+
+// normalizeDescription reduces a payee description to its letters so that reference
+// numbers and dates embedded by the bank don't split one subscription into many
+func normalizeDescription(description string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, description)
+	return strings.Join(strings.Fields(cleaned), " ")
+}
+
+// GetRecurringTransactions finds expenses that repeat monthly or yearly at a roughly constant
+// amount. Charges are grouped by normalized description; within a group only charges within
+// the tolerance of the median amount are kept, and at least three must remain. Monthly series
+// must also appear in most months of their span so occasional purchases aren't flagged.
+func (p *Parser) GetRecurringTransactions() ([]RecurringTransaction, error) {
+	transactions, err := p.GetTransactions()
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]recurringCharge)
+	for _, tx := range transactions {
+		if p.isTransfer(tx) {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", tx.Date)
+		if err != nil {
+			continue
+		}
+
+		charge := recurringCharge{date: date, description: tx.Description}
+		for _, posting := range tx.Postings {
+			if !strings.HasPrefix(posting.Account, "expenses:") || len(posting.Amount) == 0 {
+				continue
+			}
+			charge.amount += p.expenseAmount(convertAmount(posting.Amount[0].Quantity))
+			charge.accounts = append(charge.accounts, posting.Account)
+		}
+		if charge.amount <= 0 {
+			continue
+		}
+
+		key := normalizeDescription(tx.Description)
+		if key == "" {
+			continue
+		}
+		groups[key] = append(groups[key], charge)
+	}
+
+	result := []RecurringTransaction{}
+	for _, charges := range groups {
+		if item, ok := p.detectRecurrence(charges); ok {
+			result = append(result, item)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TypicalAmount != result[j].TypicalAmount {
+			return result[i].TypicalAmount > result[j].TypicalAmount
+		}
+		return result[i].Description < result[j].Description
+	})
+
+	return result, nil
+}
+
+// detectRecurrence decides whether a group of similar charges forms a monthly or yearly series
+func (p *Parser) detectRecurrence(charges []recurringCharge) (RecurringTransaction, bool) {
+	if len(charges) < recurringMinOccurrences {
+		return RecurringTransaction{}, false
+	}
+
+	amounts := make([]float64, len(charges))
+	for i, charge := range charges {
+		amounts[i] = charge.amount
+	}
+	typical := median(amounts)
+
+	var steady []recurringCharge
+	for _, charge := range charges {
+		if math.Abs(charge.amount-typical) <= typical*recurringAmountTolerance {
+			steady = append(steady, charge)
+		}
+	}
+	if len(steady) < recurringMinOccurrences {
+		return RecurringTransaction{}, false
+	}
+
+	// Report the typical amount of the steady charges alone, without the outliers
+	steadyAmounts := make([]float64, len(steady))
+	for i, charge := range steady {
+		steadyAmounts[i] = charge.amount
+	}
+	typical = median(steadyAmounts)
+
+	sort.Slice(steady, func(i, j int) bool { return steady[i].date.Before(steady[j].date) })
+
+	gaps := make([]float64, 0, len(steady)-1)
+	for i := 1; i < len(steady); i++ {
+		gaps = append(gaps, steady[i].date.Sub(steady[i-1].date).Hours()/24)
+	}
+	gap := median(gaps)
+
+	first, last := steady[0].date, steady[len(steady)-1].date
+	var cadence string
+	switch {
+	case gap >= 26 && gap <= 35:
+		months := make(map[string]bool)
+		for _, charge := range steady {
+			months[charge.date.Format("2006-01")] = true
+		}
+		span := (last.Year()-first.Year())*12 + int(last.Month()) - int(first.Month()) + 1
+		if float64(len(months)) < float64(span)*recurringMinMonthCoverage {
+			return RecurringTransaction{}, false
+		}
+		cadence = "monthly"
+	case gap >= 350 && gap <= 380:
+		cadence = "yearly"
+	default:
+		return RecurringTransaction{}, false
+	}
+
+	accountSet := make(map[string]bool)
+	for _, charge := range steady {
+		for _, account := range charge.accounts {
+			accountSet[account] = true
+		}
+	}
+	accounts := make([]string, 0, len(accountSet))
+	for account := range accountSet {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+
+	return RecurringTransaction{
+		Description:   steady[len(steady)-1].description,
+		Cadence:       cadence,
+		TypicalAmount: p.roundAmount(typical),
+		Occurrences:   len(steady),
+		FirstDate:     first.Format("2006-01-02"),
+		LastDate:      last.Format("2006-01-02"),
+		Accounts:      accounts,
+	}, true
+}
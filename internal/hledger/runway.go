@@ -0,0 +1,78 @@
+package hledger
+
+import "time"
+
+// Runway represents how long liquid assets would cover recent spending
+type Runway struct {
+	LiquidAssets       float64  `json:"liquidAssets"`
+	AvgMonthlyExpenses float64  `json:"avgMonthlyExpenses"`
+	RunwayMonths       *float64 `json:"runwayMonths"` // nil when there were no expenses to divide by
+	MonthsAveraged     int      `json:"monthsAveraged"`
+}
+
+// GetRunway divides the balance of the liquid accounts by the average monthly expenses over
+// the last n complete calendar months, or as many as the journal covers. The current month is
+// left out because its partial total would understate the burn rate.
+func (p *Parser) GetRunway(n int) (*Runway, error) {
+	if n < 1 {
+		n = 1
+	}
+
+	accounts, err := p.GetAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	runway := &Runway{}
+	for _, account := range accounts {
+		if p.currentSettings().IsLiquidAccount(account.Name) {
+			runway.LiquidAssets += account.Balance
+		}
+	}
+	runway.LiquidAssets = p.roundAmount(runway.LiquidAssets)
+
+	metrics, err := p.GetMonthlyMetrics()
+	if err != nil {
+		return nil, err
+	}
+
+	// Metrics leave out months without transactions, so walk calendar months back from the
+	// current one and count missing months as no spending, stopping at the journal's first month
+	currentMonth := p.currentYearMonth()
+	expenses := make(map[string]float64)
+	earliest := ""
+	for _, metric := range metrics {
+		if metric.Month >= currentMonth {
+			continue
+		}
+		expenses[metric.Month] = metric.Expenses
+		if earliest == "" || metric.Month < earliest {
+			earliest = metric.Month
+		}
+	}
+
+	var total float64
+	if earliest != "" {
+		now := p.Now()
+		month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		for runway.MonthsAveraged < n {
+			month = month.AddDate(0, -1, 0)
+			key := month.Format("2006-01")
+			if key < earliest {
+				break
+			}
+			total += expenses[key]
+			runway.MonthsAveraged++
+		}
+	}
+
+	if runway.MonthsAveraged > 0 {
+		runway.AvgMonthlyExpenses = p.roundAmount(total / float64(runway.MonthsAveraged))
+	}
+	if runway.AvgMonthlyExpenses > 0 {
+		months := roundRatio(runway.LiquidAssets / runway.AvgMonthlyExpenses)
+		runway.RunwayMonths = &months
+	}
+
+	return runway, nil
+}
@@ -0,0 +1,48 @@
+package hledger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cwj5/minted/internal/config"
+)
+
+func TestRunwayCountsGapMonthsAsZero(t *testing.T) {
+	// Nothing at all happened in April, so the metrics have no row for it
+	journal := []Transaction{
+		expense("2024-02-10", "expenses:food", 600),
+		expense("2024-03-10", "expenses:food", 600),
+		expense("2024-05-10", "expenses:food", 600),
+		expense("2024-06-05", "expenses:food", 900), // the current month is left out
+	}
+	tests := []struct {
+		n          int
+		wantMonths int
+		wantAvg    float64
+	}{
+		{1, 1, 600},
+		{2, 2, 300}, // May and the empty April
+		{3, 3, 400},
+		{12, 4, 450}, // stops at February, the journal's first month
+	}
+	for _, tt := range tests {
+		fakeHledger(t, map[string]string{
+			"print":   printJSON(t, journal...),
+			"balance": balanceJSON(t, balanceRow{"assets:checking", 3600}),
+		})
+		p := NewParser("test.journal", config.DefaultSettings())
+		p.SetNow(func() time.Time { return testNow })
+
+		runway, err := p.GetRunway(tt.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if runway.MonthsAveraged != tt.wantMonths || runway.AvgMonthlyExpenses != tt.wantAvg {
+			t.Errorf("GetRunway(%d) averaged %d months to %v, want %d months to %v",
+				tt.n, runway.MonthsAveraged, runway.AvgMonthlyExpenses, tt.wantMonths, tt.wantAvg)
+		}
+		if want := 3600 / tt.wantAvg; runway.RunwayMonths == nil || *runway.RunwayMonths != want {
+			t.Errorf("GetRunway(%d) runway %v months, want %v", tt.n, runway.RunwayMonths, want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package hledger
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// TransactionSearch holds the criteria for SearchTransactions.
+// Empty strings and nil bounds are ignored.
+type TransactionSearch struct {
+	Query     string
+	Account   string
+	MinAmount *float64
+	MaxAmount *float64
+	StartDate string
+	EndDate   string
+}
+
+// SearchTransactions returns transactions matching the search criteria, newest first
+func (p *Parser) SearchTransactions(search TransactionSearch) ([]Transaction, error) {
+	transactions, err := p.GetTransactionsFiltered(search.StartDate, search.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	query := strings.ToLower(strings.TrimSpace(search.Query))
+	results := []Transaction{}
+
+	for _, tx := range transactions {
+		if query != "" && !matchesText(tx, query) {
+			continue
+		}
+
+		// Largest posting magnitude, restricted to the account when one is given
+		touchesAccount := search.Account == ""
+		largest := 0.0
+		for _, posting := range tx.Postings {
+			if search.Account != "" && !isAccountOrChild(posting.Account, search.Account) {
+				continue
+			}
+			touchesAccount = true
+
+			if len(posting.Amount) > 0 {
+				amount := math.Abs(convertAmount(posting.Amount[0].Quantity))
+				if amount > largest {
+					largest = amount
+				}
+			}
+		}
+
+		if !touchesAccount {
+			continue
+		}
+		if search.MinAmount != nil && largest < *search.MinAmount {
+			continue
+		}
+		if search.MaxAmount != nil && largest > *search.MaxAmount {
+			continue
+		}
+
+		results = append(results, tx)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Date > results[j].Date
+	})
+
+	return results, nil
+}
+
+// matchesText reports whether the lowercased query appears in the description or any posting account
+func matchesText(tx Transaction, query string) bool {
+	if strings.Contains(strings.ToLower(tx.Description), query) {
+		return true
+	}
+	for _, posting := range tx.Postings {
+		if strings.Contains(strings.ToLower(posting.Account), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAccountOrChild reports whether account is parent itself or one of its subaccounts
+func isAccountOrChild(account, parent string) bool {
+	return account == parent || strings.HasPrefix(account, parent+":")
+}
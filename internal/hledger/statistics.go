@@ -0,0 +1,189 @@
+package hledger
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// Statistics summarizes the size and span of the journal data
+type Statistics struct {
+	TransactionCount  int    `json:"transactionCount"`
+	PostingCount      int    `json:"postingCount"`
+	AccountCount      int    `json:"accountCount"`
+	EarliestDate      string `json:"earliestDate"` // empty when there are no transactions
+	LatestDate        string `json:"latestDate"`
+	ExpenseCategories int    `json:"expenseCategories"`
+	IncomeCategories  int    `json:"incomeCategories"`
+}
+
+// topCategory returns the first segment below the account type, e.g. "food" for
+// "expenses:food:groceries", or the account itself when it has no subaccount
+func topCategory(account string) string {
+	parts := strings.Split(account, ":")
+	if len(parts) >= 2 {
+		return parts[1]
+	}
+	return account
+}
+
+// GetStatistics counts transactions, postings, accounts and categories in a single pass over
+// the transactions in the range. Categories are counted at the level used elsewhere in the
+// dashboard, i.e. the first segment after expenses: or income:.
+func (p *Parser) GetStatistics(startDate, endDate string) (*Statistics, error) {
+	transactions, err := p.GetTransactionsFiltered(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &Statistics{TransactionCount: len(transactions)}
+	accounts := make(map[string]bool)
+	expenseCategories := make(map[string]bool)
+	incomeCategories := make(map[string]bool)
+
+	for _, tx := range transactions {
+		if tx.Date != "" {
+			if stats.EarliestDate == "" || tx.Date < stats.EarliestDate {
+				stats.EarliestDate = tx.Date
+			}
+			if tx.Date > stats.LatestDate {
+				stats.LatestDate = tx.Date
+			}
+		}
+
+		stats.PostingCount += len(tx.Postings)
+		for _, posting := range tx.Postings {
+			accounts[posting.Account] = true
+			if strings.HasPrefix(posting.Account, "expenses:") {
+				expenseCategories[topCategory(posting.Account)] = true
+			} else if strings.HasPrefix(posting.Account, "income:") {
+				incomeCategories[topCategory(posting.Account)] = true
+			}
+		}
+	}
+
+	stats.AccountCount = len(accounts)
+	stats.ExpenseCategories = len(expenseCategories)
+	stats.IncomeCategories = len(incomeCategories)
+
+	return stats, nil
+}
+
+// CategoryCount is a category found in the journal with the number of postings to it
+type CategoryCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// GetAllCategories returns every distinct expense category across all transactions, sorted by name
+func (p *Parser) GetAllCategories() ([]CategoryCount, error) {
+	return p.distinctCategories("expenses:")
+}
+
+// GetAllIncomeSources returns every distinct income source across all transactions, sorted by name
+func (p *Parser) GetAllIncomeSources() ([]CategoryCount, error) {
+	return p.distinctCategories("income:")
+}
+
+// distinctCategories counts postings per category below the account type prefix, using the
+// same first-segment categories as the rest of the dashboard
+func (p *Parser) distinctCategories(prefix string) ([]CategoryCount, error) {
+	transactions, err := p.GetTransactions()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, tx := range transactions {
+		for _, posting := range tx.Postings {
+			if strings.HasPrefix(posting.Account, prefix) {
+				counts[topCategory(posting.Account)]++
+			}
+		}
+	}
+
+	categories := make([]CategoryCount, 0, len(counts))
+	for name, count := range counts {
+		categories = append(categories, CategoryCount{Name: name, Count: count})
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Name < categories[j].Name
+	})
+
+	return categories, nil
+}
+
+// HistogramBucket counts the postings whose amount falls in [Min, Max). The last bucket also
+// includes Max.
+type HistogramBucket struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int     `json:"count"`
+}
+
+// GetAccountHistogram divides the range of posting amounts to an account and its subaccounts into
+// equal-width buckets and counts the postings in each, in one pass over the transactions.
+// Amounts keep their sign. When every posting has the same amount there is a single bucket, and
+// an account without postings has none.
+func (p *Parser) GetAccountHistogram(account string, buckets int) ([]HistogramBucket, error) {
+	if buckets < 1 {
+		buckets = 1
+	}
+
+	transactions, err := p.GetTransactions()
+	if err != nil {
+		return nil, err
+	}
+
+	var amounts []float64
+	for _, tx := range transactions {
+		for _, posting := range tx.Postings {
+			if len(posting.Amount) == 0 {
+				continue
+			}
+			if posting.Account != account && !strings.HasPrefix(posting.Account, account+":") {
+				continue
+			}
+			amounts = append(amounts, convertAmount(posting.Amount[0].Quantity))
+		}
+	}
+
+	histogram := []HistogramBucket{}
+	if len(amounts) == 0 {
+		return histogram, nil
+	}
+
+	low, high := amounts[0], amounts[0]
+	for _, amount := range amounts {
+		low = math.Min(low, amount)
+		high = math.Max(high, amount)
+	}
+	if low == high {
+		return append(histogram, HistogramBucket{Min: p.roundAmount(low), Max: p.roundAmount(high), Count: len(amounts)}), nil
+	}
+
+	width := (high - low) / float64(buckets)
+	counts := make([]int, buckets)
+	for _, amount := range amounts {
+		// The maximum lands one past the end, so it joins the last bucket
+		index := int((amount - low) / width)
+		if index >= buckets {
+			index = buckets - 1
+		}
+		counts[index]++
+	}
+
+	for i, count := range counts {
+		upper := low + width*float64(i+1)
+		if i == buckets-1 {
+			upper = high
+		}
+		histogram = append(histogram, HistogramBucket{
+			Min:   p.roundAmount(low + width*float64(i)),
+			Max:   p.roundAmount(upper),
+			Count: count,
+		})
+	}
+
+	return histogram, nil
+}
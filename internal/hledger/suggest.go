@@ -0,0 +1,127 @@
+package hledger
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cwj5/minted/internal/config"
+)
+
+// TierSuggestion is a proposed tier for a category that isn't in any tier yet
+type TierSuggestion struct {
+	Category      string  `json:"category"`
+	SuggestedTier string  `json:"suggestedTier"`
+	Confidence    float64 `json:"confidence"` // 0-1
+	Reason        string  `json:"reason"`
+}
+
+// Confidence levels for the two suggestion heuristics
+const (
+	similarCategoryConfidence = 0.8
+	keywordConfidence         = 0.6
+)
+
+// defaultTierKeywords maps words commonly found in category names to the default tier names.
+// Settings.TierKeywords adds to and overrides it.
+var defaultTierKeywords = map[string]string{
+	"dining":        "Discretionary",
+	"restaurant":    "Discretionary",
+	"coffee":        "Discretionary",
+	"travel":        "Discretionary",
+	"entertainment": "Discretionary",
+	"gift":          "Discretionary",
+	"grocer":        "Essential",
+	"utilit":        "Essential",
+	"insurance":     "Essential",
+	"medical":       "Essential",
+	"fuel":          "Essential",
+	"transport":     "Essential",
+	"rent":          "Fixed",
+	"mortgage":      "Fixed",
+	"subscription":  "Fixed",
+	"internet":      "Fixed",
+}
+
+// SuggestTierAssignments proposes a tier for each untiered expense category in the date range.
+// A category whose name contains, or is contained in, a category already in a tier is suggested
+// for that tier; otherwise the keyword table is consulted, preferring the longest keyword.
+// Suggestions only name tiers that exist, and categories with no match are left out. Nothing
+// is assigned; applying a suggestion is up to the caller.
+func (p *Parser) SuggestTierAssignments(startDate, endDate string) ([]TierSuggestion, error) {
+	untiered, err := p.GetUntieredCategories(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := p.currentSettings()
+	tierExists := make(map[string]bool)
+	for _, tier := range settings.Tiers {
+		tierExists[tier.Name] = true
+	}
+
+	keywords := make(map[string]string)
+	for keyword, tier := range defaultTierKeywords {
+		keywords[keyword] = tier
+	}
+	for keyword, tier := range settings.TierKeywords {
+		keywords[strings.ToLower(keyword)] = tier
+	}
+
+	suggestions := []TierSuggestion{}
+	for _, item := range untiered {
+		name := strings.ToLower(item.Category)
+		if suggestion, ok := similarTierCategory(name, settings.Tiers); ok {
+			suggestion.Category = item.Category
+			suggestions = append(suggestions, suggestion)
+			continue
+		}
+
+		best := ""
+		for keyword, tier := range keywords {
+			if !tierExists[tier] || !strings.Contains(name, keyword) {
+				continue
+			}
+			if len(keyword) > len(best) || (len(keyword) == len(best) && keyword < best) {
+				best = keyword
+			}
+		}
+		if best != "" {
+			suggestions = append(suggestions, TierSuggestion{
+				Category:      item.Category,
+				SuggestedTier: keywords[best],
+				Confidence:    keywordConfidence,
+				Reason:        "name contains \"" + best + "\"",
+			})
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Confidence != suggestions[j].Confidence {
+			return suggestions[i].Confidence > suggestions[j].Confidence
+		}
+		return suggestions[i].Category < suggestions[j].Category
+	})
+
+	return suggestions, nil
+}
+
+// similarTierCategory looks for a tier category that contains the lowercase name or is
+// contained in it, such as "groceries" for "groceries-costco"
+func similarTierCategory(name string, tiers []config.Tier) (TierSuggestion, bool) {
+	for _, tier := range tiers {
+		for _, cat := range tier.Categories {
+			member := strings.ToLower(cat)
+			if len(member) < 3 || len(name) < 3 {
+				continue
+			}
+			if strings.Contains(name, member) || strings.Contains(member, name) {
+				return TierSuggestion{
+					SuggestedTier: tier.Name,
+					Confidence:    similarCategoryConfidence,
+					Reason:        "similar to \"" + cat + "\"",
+				}, true
+			}
+		}
+	}
+	return TierSuggestion{}, false
+}
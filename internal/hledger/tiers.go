@@ -0,0 +1,102 @@
+package hledger
+
+import (
+	"sort"
+	"time"
+)
+
+// TierBudgetStatus compares a tier's spending against its monthly budget
+type TierBudgetStatus struct {
+	Tier       string  `json:"tier"`
+	Spent      float64 `json:"spent"`
+	Budget     float64 `json:"budget"`
+	Remaining  float64 `json:"remaining"`
+	Percent    float64 `json:"percent"`
+	OverBudget bool    `json:"overBudget"`
+}
+
+// UntieredCategory represents an expense category not assigned to any tier
+type UntieredCategory struct {
+	Category string  `json:"category"`
+	Total    float64 `json:"total"`
+}
+
+// GetTierBudgetStatus sums spending for each tier's categories within a date range and compares
+// it against the tier budget. Without a range the current month is used.
+func (p *Parser) GetTierBudgetStatus(startDate, endDate string) ([]TierBudgetStatus, error) {
+	if startDate == "" || endDate == "" {
+		now := p.Now()
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		startDate = monthStart.Format("2006-01-02")
+		endDate = monthStart.AddDate(0, 1, 0).Format("2006-01-02")
+	}
+
+	spending, err := p.GetCategorySpendingFiltered(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map of tier name -> spent amount
+	tierSpent := make(map[string]float64)
+	for _, item := range spending {
+		if tier := p.currentSettings().GetTierForCategory(item.Category); tier != nil {
+			tierSpent[tier.Name] += item.Amount
+		}
+	}
+
+	statuses := []TierBudgetStatus{}
+	for _, tier := range p.currentSettings().Tiers {
+		spent := tierSpent[tier.Name]
+
+		percent := 0.0
+		if tier.Budget > 0 {
+			percent = (spent / tier.Budget) * 100
+		}
+
+		statuses = append(statuses, TierBudgetStatus{
+			Tier:       tier.Name,
+			Spent:      p.roundAmount(spent),
+			Budget:     tier.Budget,
+			Remaining:  p.roundAmount(tier.Budget - spent),
+			Percent:    roundRatio(percent),
+			OverBudget: spent > tier.Budget,
+		})
+	}
+
+	return statuses, nil
+}
+
+// GetUntieredCategories returns expense categories within a date range that aren't assigned to
+// any tier, with their total spend. Tier membership is matched case-insensitively.
+func (p *Parser) GetUntieredCategories(startDate, endDate string) ([]UntieredCategory, error) {
+	spending, err := p.GetCategorySpendingFiltered(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map of category -> total spend
+	totals := make(map[string]float64)
+	for _, item := range spending {
+		if p.currentSettings().GetTierForCategory(item.Category) == nil {
+			totals[item.Category] += item.Amount
+		}
+	}
+
+	untiered := []UntieredCategory{}
+	for category, total := range totals {
+		untiered = append(untiered, UntieredCategory{
+			Category: category,
+			Total:    p.roundAmount(total),
+		})
+	}
+
+	// Sort by total descending so the largest unallocated spend comes first
+	sort.Slice(untiered, func(i, j int) bool {
+		if untiered[i].Total != untiered[j].Total {
+			return untiered[i].Total > untiered[j].Total
+		}
+		return untiered[i].Category < untiered[j].Category
+	})
+
+	return untiered, nil
+}
@@ -0,0 +1,62 @@
+package hledger
+
+import "testing"
+
+// tierJournal posts to lowercase categories that the default tiers list capitalized
+func tierJournal() []Transaction {
+	return []Transaction{
+		expense("2024-05-03", "expenses:groceries:produce", 50),
+		expense("2024-05-04", "expenses:groceries", 30),
+		expense("2024-05-05", "expenses:dining", 20),
+		expense("2024-05-06", "expenses:travel", 200),
+	}
+}
+
+func TestTierMatchingIgnoresCase(t *testing.T) {
+	p := newTestParser(t, nil, tierJournal()...)
+
+	trends, err := p.GetCategoryTrends()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]float64)
+	for _, trend := range trends {
+		for _, pair := range trend.Data {
+			got[trend.Category] += pair.Amount
+		}
+	}
+	want := map[string]float64{"Essential": 80, "Discretionary": 20, "travel": 200}
+	if len(got) != len(want) {
+		t.Errorf("GetCategoryTrends groups %v, want %v", got, want)
+	}
+	for name, amount := range want {
+		if got[name] != amount {
+			t.Errorf("GetCategoryTrends %s = %v, want %v", name, got[name], amount)
+		}
+	}
+
+	tests := []struct {
+		fetch string
+		run   func() (*TierDetailData, error)
+	}{
+		{"GetTierDetail", func() (*TierDetailData, error) { return p.GetTierDetail("Essential") }},
+		{"GetTierDetailFiltered", func() (*TierDetailData, error) {
+			return p.GetTierDetailFiltered("Essential", "2024-05-01", "2024-06-01")
+		}},
+	}
+	for _, tt := range tests {
+		detail, err := tt.run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if detail == nil {
+			t.Fatalf("%s: no Essential tier", tt.fetch)
+		}
+		if len(detail.Transactions) != 2 {
+			t.Errorf("%s: %d transactions, want the 2 grocery ones", tt.fetch, len(detail.Transactions))
+		}
+		if len(detail.Breakdown) != 1 || detail.Breakdown[0].Name != "groceries" || detail.Breakdown[0].Amount != 80 {
+			t.Errorf("%s: breakdown %+v, want groceries 80", tt.fetch, detail.Breakdown)
+		}
+	}
+}
@@ -0,0 +1,871 @@
+package hledger
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SavingsRatePoint represents one month of the savings rate trend.
+// SavingsRate is nil for months without income, where the rate is undefined.
+type SavingsRatePoint struct {
+	Month         string   `json:"month"`
+	Income        float64  `json:"income"`
+	Expenses      float64  `json:"expenses"`
+	SavingsRate   *float64 `json:"savingsRate"`
+	MovingAverage *float64 `json:"movingAverage"`
+}
+
+// GetSavingsRateTrend returns the monthly savings rate with a trailing moving average over
+// window months (including the current one). Months with zero income are left out of the
+// average instead of counting as 0%, since a missing paycheck would otherwise drag the trend
+// down; the average is nil when no month in the window has income.
+func (p *Parser) GetSavingsRateTrend(window int, startDate, endDate string) ([]SavingsRatePoint, error) {
+	if window < 1 {
+		window = 1
+	}
+
+	metrics, err := p.GetMonthlyMetricsFiltered(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []SavingsRatePoint{}
+	for i, m := range metrics {
+		point := SavingsRatePoint{
+			Month:    m.Month,
+			Income:   m.Income,
+			Expenses: m.Expenses,
+		}
+		if m.Income > 0 {
+			rate := m.SavingsRate
+			point.SavingsRate = &rate
+		}
+
+		sum, count := 0.0, 0
+		for j := i - window + 1; j <= i; j++ {
+			if j < 0 || metrics[j].Income <= 0 {
+				continue
+			}
+			sum += metrics[j].SavingsRate
+			count++
+		}
+		if count > 0 {
+			avg := roundRatio(sum / float64(count))
+			point.MovingAverage = &avg
+		}
+
+		result = append(result, point)
+	}
+
+	return result, nil
+}
+
+// IncomeVsExpense represents income and expenses side by side for one month
+type IncomeVsExpense struct {
+	Month    string  `json:"month"`
+	Income   float64 `json:"income"`
+	Expenses float64 `json:"expenses"`
+	Net      float64 `json:"net"`
+}
+
+// GetIncomeVsExpense returns monthly income, expenses and their difference from a single pass
+// over the transactions. Any month with income or expense postings is included, even when the
+// other side is zero. Transfers between own accounts are skipped.
+func (p *Parser) GetIncomeVsExpense(startDate, endDate string) ([]IncomeVsExpense, error) {
+	transactions, err := p.GetTransactionsFiltered(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	monthly := make(map[string]*IncomeVsExpense)
+	for _, tx := range transactions {
+		if p.isTransfer(tx) {
+			continue
+		}
+
+		month := getYearMonth(tx.Date)
+		for _, posting := range tx.Postings {
+			isIncome := strings.HasPrefix(posting.Account, "income:")
+			if !isIncome && !strings.HasPrefix(posting.Account, "expenses:") {
+				continue
+			}
+
+			var amount float64
+			if len(posting.Amount) > 0 {
+				amount = convertAmount(posting.Amount[0].Quantity)
+			}
+
+			entry := monthly[month]
+			if entry == nil {
+				entry = &IncomeVsExpense{Month: month}
+				monthly[month] = entry
+			}
+			if isIncome {
+				entry.Income += p.incomeAmount(amount)
+			} else {
+				entry.Expenses += p.expenseAmount(amount)
+			}
+		}
+	}
+
+	result := []IncomeVsExpense{}
+	for _, entry := range monthly {
+		result = append(result, IncomeVsExpense{
+			Month:    entry.Month,
+			Income:   p.roundAmount(entry.Income),
+			Expenses: p.roundAmount(entry.Expenses),
+			Net:      p.roundAmount(entry.Income - entry.Expenses),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Month < result[j].Month
+	})
+
+	return result, nil
+}
+
+// IncomePoint represents total income for one month
+type IncomePoint struct {
+	Month  string  `json:"month"`
+	Income float64 `json:"income"`
+}
+
+// GetIncomeOverTime sums income postings per month with every month in the range present, zero
+// when nothing came in. With a date range the series covers it fully (the end date is
+// exclusive); otherwise it runs from the first to the last month with income. Transfers
+// between own accounts are skipped.
+func (p *Parser) GetIncomeOverTime(startDate, endDate string) ([]IncomePoint, error) {
+	transactions, err := p.GetTransactionsFiltered(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]float64)
+	first, last := "", ""
+	for _, tx := range transactions {
+		if p.isTransfer(tx) {
+			continue
+		}
+
+		month := getYearMonth(tx.Date)
+		for _, posting := range tx.Postings {
+			if !strings.HasPrefix(posting.Account, "income:") || len(posting.Amount) == 0 {
+				continue
+			}
+			totals[month] += p.incomeAmount(convertAmount(posting.Amount[0].Quantity))
+			if first == "" || month < first {
+				first = month
+			}
+			if month > last {
+				last = month
+			}
+		}
+	}
+
+	if start, err := time.Parse("2006-01-02", startDate); err == nil {
+		if end, err := time.Parse("2006-01-02", endDate); err == nil {
+			first, last = start.Format("2006-01"), end.AddDate(0, 0, -1).Format("2006-01")
+		}
+	}
+
+	result := []IncomePoint{}
+	from, err := time.Parse("2006-01", first)
+	if err != nil {
+		return result, nil
+	}
+	to, err := time.Parse("2006-01", last)
+	if err != nil {
+		return result, nil
+	}
+	for month := from; !month.After(to); month = month.AddDate(0, 1, 0) {
+		key := month.Format("2006-01")
+		result = append(result, IncomePoint{
+			Month:  key,
+			Income: p.roundAmount(totals[key]),
+		})
+	}
+
+	return result, nil
+}
+
+// Periods accepted by RollupCategorySpending
+const (
+	PeriodMonth   = "month"
+	PeriodQuarter = "quarter"
+	PeriodYear    = "year"
+)
+
+// fiscalYearStart returns the fiscalYearStartMonth preference (1-12), falling back to January
+func (p *Parser) fiscalYearStart() int {
+	start := p.currentSettings().GetPreferenceInt("fiscalYearStartMonth", 1)
+	if start < 1 || start > 12 {
+		return 1
+	}
+	return start
+}
+
+// fiscalYear returns the label of the fiscal year a YYYY-MM or YYYY-MM-DD date falls in. Fiscal
+// years are named after the calendar year they start in, so with startMonth 4 (April) 2024-02
+// belongs to 2023 and 2024-04 to 2024. It returns "" for malformed dates.
+func fiscalYear(date string, startMonth int) string {
+	if len(date) < 7 {
+		return ""
+	}
+	year, err := strconv.Atoi(date[:4])
+	if err != nil {
+		return ""
+	}
+	month, err := strconv.Atoi(date[5:7])
+	if err != nil || month < 1 || month > 12 {
+		return ""
+	}
+	if month < startMonth {
+		year--
+	}
+	return fmt.Sprintf("%04d", year)
+}
+
+// fiscalMonthIndex orders an MM month by its position in a fiscal year starting at startMonth,
+// so with startMonth 4 April is 0 and March is 11
+func fiscalMonthIndex(month string, startMonth int) int {
+	m, err := strconv.Atoi(month)
+	if err != nil {
+		return 12
+	}
+	return (m - startMonth + 12) % 12
+}
+
+// periodKey maps a YYYY-MM month to its bucket: YYYY-MM, YYYY-Qn or YYYY.
+// Quarters come from the month number, so 01-03 is Q1 and 10-12 is Q4. Years are fiscal years
+// starting in fiscalStart.
+func periodKey(month, period string, fiscalStart int) (string, bool) {
+	if len(month) < 7 {
+		return "", false
+	}
+	switch period {
+	case PeriodQuarter:
+		m, err := strconv.Atoi(month[5:7])
+		if err != nil || m < 1 || m > 12 {
+			return "", false
+		}
+		return fmt.Sprintf("%s-Q%d", month[:4], (m-1)/3+1), true
+	case PeriodYear:
+		year := fiscalYear(month, fiscalStart)
+		return year, year != ""
+	default:
+		return month[:7], true
+	}
+}
+
+// RollupCategorySpending aggregates monthly category spending into coarser periods, with the
+// bucket (YYYY-Qn or YYYY) in the Month field. Buckets only sum the months present in the data,
+// so a range starting mid-quarter yields a partial quarter rather than an estimate.
+func (p *Parser) RollupCategorySpending(rows []CategorySpending, period string) ([]CategorySpending, error) {
+	if period == "" || period == PeriodMonth {
+		return rows, nil
+	}
+	if period != PeriodQuarter && period != PeriodYear {
+		return nil, fmt.Errorf("unknown period %q", period)
+	}
+
+	type bucketKey struct {
+		period   string
+		category string
+	}
+	fiscalStart := p.fiscalYearStart()
+	totals := make(map[bucketKey]float64)
+	tiers := make(map[string]string)
+	for _, row := range rows {
+		key, ok := periodKey(row.Month, period, fiscalStart)
+		if !ok {
+			continue
+		}
+		totals[bucketKey{key, row.Category}] += row.Amount
+		tiers[row.Category] = row.Tier
+	}
+
+	result := []CategorySpending{}
+	for key, amount := range totals {
+		result = append(result, CategorySpending{
+			Month:    key.period,
+			Category: key.category,
+			Tier:     tiers[key.category],
+			Amount:   p.roundAmount(amount),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Month != result[j].Month {
+			return result[i].Month < result[j].Month
+		}
+		return result[i].Category < result[j].Category
+	})
+
+	return result, nil
+}
+
+// CategoryShare is a category's part of the total expenses or income in a date range
+type CategoryShare struct {
+	Category string  `json:"category"`
+	Tier     string  `json:"tier"` // empty when the category is not in any tier, and for income
+	Amount   float64 `json:"amount"`
+	Percent  float64 `json:"percent"`
+}
+
+// Breakdowns accepted by GetCategoryBreakdownGrouped
+const (
+	BreakdownExpenses = "expenses"
+	BreakdownIncome   = "income"
+)
+
+// OtherCategory is the category GetCategoryBreakdownGrouped folds small categories into
+const OtherCategory = "Other"
+
+// GetCategoryShares totals each expense category over the date range and divides it by the
+// grand total across all months, largest first. Monthly totals are clamped at zero as in
+// GetCategorySpending, so shares are never negative and sum to 100 within rounding; when
+// nothing was spent every share is 0.
+func (p *Parser) GetCategoryShares(startDate, endDate string) ([]CategoryShare, error) {
+	spending, err := p.GetCategorySpendingFiltered(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]float64)
+	for _, item := range spending {
+		totals[item.Category] += item.Amount
+	}
+	return p.categoryShares(totals, true), nil
+}
+
+// categoryShares turns category totals into shares of their sum, largest first. Tiers are
+// looked up only when tiered is set, since they only hold expense categories.
+func (p *Parser) categoryShares(totals map[string]float64, tiered bool) []CategoryShare {
+	var grandTotal float64
+	for _, amount := range totals {
+		grandTotal += amount
+	}
+
+	shares := make([]CategoryShare, 0, len(totals))
+	for category, amount := range totals {
+		percent := 0.0
+		if grandTotal > 0 {
+			percent = amount / grandTotal * 100
+		}
+		share := CategoryShare{
+			Category: category,
+			Amount:   p.roundAmount(amount),
+			Percent:  roundRatio(percent),
+		}
+		if tiered {
+			share.Tier = p.tierName(category)
+		}
+		shares = append(shares, share)
+	}
+
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Amount != shares[j].Amount {
+			return shares[i].Amount > shares[j].Amount
+		}
+		return shares[i].Category < shares[j].Category
+	})
+
+	return shares
+}
+
+// GetCategoryBreakdownGrouped returns the expense or income shares for the date range with
+// every category below minPercent folded into a single OtherCategory entry at the end, so
+// pie charts aren't cluttered by a long tail of slivers. A lone small category keeps its own
+// name since grouping it would only hide it. minPercent 0 leaves the breakdown ungrouped.
+func (p *Parser) GetCategoryBreakdownGrouped(kind, startDate, endDate string, minPercent float64) ([]CategoryShare, error) {
+	var shares []CategoryShare
+	switch kind {
+	case BreakdownExpenses:
+		expenses, err := p.GetCategoryShares(startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		shares = expenses
+	case BreakdownIncome:
+		income, err := p.GetIncomeBreakdownFiltered(startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		totals := make(map[string]float64)
+		for _, item := range income {
+			totals[item.Category] += item.Amount
+		}
+		shares = p.categoryShares(totals, false)
+	default:
+		return nil, fmt.Errorf("unknown breakdown %q", kind)
+	}
+
+	var small []CategoryShare
+	grouped := make([]CategoryShare, 0, len(shares))
+	for _, share := range shares {
+		if share.Percent < minPercent {
+			small = append(small, share)
+			continue
+		}
+		grouped = append(grouped, share)
+	}
+	if len(small) < 2 {
+		return shares, nil
+	}
+
+	other := CategoryShare{Category: OtherCategory}
+	for _, share := range small {
+		other.Amount += share.Amount
+		other.Percent += share.Percent
+	}
+	other.Amount = p.roundAmount(other.Amount)
+	other.Percent = roundRatio(other.Percent)
+	return append(grouped, other), nil
+}
+
+// NetWorthMilestone is the first date net worth reached a multiple of the milestone step
+type NetWorthMilestone struct {
+	Milestone float64 `json:"milestone"`
+	Date      string  `json:"date"`
+}
+
+// maxNetWorthMilestones bounds the result so a tiny step can't produce millions of entries
+const maxNetWorthMilestones = 1000
+
+// ErrTooManyMilestones is returned by GetNetWorthMilestones when step is too small for the
+// net worth reached
+var ErrTooManyMilestones = errors.New("step too small")
+
+// GetNetWorthMilestones walks the daily net worth series and records the first date each
+// positive multiple of step was reached. A milestone lost in a dip and regained later keeps
+// its first date. Milestones already exceeded at the start of the series get its first date.
+func (p *Parser) GetNetWorthMilestones(step float64) ([]NetWorthMilestone, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive")
+	}
+
+	series, err := p.GetNetWorthOverTime()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, point := range series {
+		if point.NetWorth/step > maxNetWorthMilestones {
+			return nil, fmt.Errorf("%w: %g gives more than %d milestones", ErrTooManyMilestones, step, maxNetWorthMilestones)
+		}
+	}
+
+	milestones := []NetWorthMilestone{}
+	reached := 0 // highest multiple of step recorded so far
+	for _, point := range series {
+		for next := reached + 1; float64(next)*step <= point.NetWorth; next++ {
+			milestones = append(milestones, NetWorthMilestone{
+				Milestone: p.roundAmount(float64(next) * step),
+				Date:      point.Date,
+			})
+			reached = next
+		}
+	}
+
+	return milestones, nil
+}
+
+// WeeklyMetrics represents income, expenses and savings rate for one ISO week
+type WeeklyMetrics struct {
+	Week        string  `json:"week"` // YYYY-Www
+	Income      float64 `json:"income"`
+	Expenses    float64 `json:"expenses"`
+	SavingsRate float64 `json:"savingsRate"`
+}
+
+// isoWeekKey returns the ISO week of a YYYY-MM-DD date as YYYY-Www. The year is the ISO year,
+// so 2024-12-30 is 2025-W01, and zero-padded weeks keep the keys in order as strings. With
+// weeks starting on Sunday, a Sunday is counted in the ISO week of the Monday after it.
+func isoWeekKey(date string, weekStart time.Weekday) (string, bool) {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return "", false
+	}
+	if weekStart == time.Sunday {
+		t = t.AddDate(0, 0, 1)
+	}
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%04d-W%02d", year, week), true
+}
+
+// GetWeeklyMetrics buckets income and expenses by ISO week, like GetMonthlyMetricsFiltered
+// does by month, with weeks starting on the day set by the weekStart preference. Transfers and
+// transactions with malformed dates are skipped.
+func (p *Parser) GetWeeklyMetrics(startDate, endDate string) ([]WeeklyMetrics, error) {
+	transactions, err := p.GetTransactionsFiltered(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	weekStart := p.weekStart()
+	weekly := make(map[string]*WeeklyMetrics)
+	for _, tx := range transactions {
+		if p.isTransfer(tx) {
+			continue
+		}
+		week, ok := isoWeekKey(tx.Date, weekStart)
+		if !ok {
+			continue
+		}
+
+		for _, posting := range tx.Postings {
+			isIncome := strings.HasPrefix(posting.Account, "income:")
+			if !isIncome && !strings.HasPrefix(posting.Account, "expenses:") {
+				continue
+			}
+
+			var amount float64
+			if len(posting.Amount) > 0 {
+				amount = convertAmount(posting.Amount[0].Quantity)
+			}
+
+			entry := weekly[week]
+			if entry == nil {
+				entry = &WeeklyMetrics{Week: week}
+				weekly[week] = entry
+			}
+			if isIncome {
+				entry.Income += p.incomeAmount(amount)
+			} else {
+				entry.Expenses += p.expenseAmount(amount)
+			}
+		}
+	}
+
+	result := []WeeklyMetrics{}
+	for _, entry := range weekly {
+		savingsRate := 0.0
+		if entry.Income > 0 {
+			savingsRate = ((entry.Income - entry.Expenses) / entry.Income) * 100
+		}
+		result = append(result, WeeklyMetrics{
+			Week:        entry.Week,
+			Income:      p.roundAmount(entry.Income),
+			Expenses:    p.roundAmount(entry.Expenses),
+			SavingsRate: roundRatio(savingsRate),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Week < result[j].Week
+	})
+
+	return result, nil
+}
+
+// YearOverYearChange compares one month's spending between two consecutive years
+type YearOverYearChange struct {
+	Month         string   `json:"month"` // MM
+	FromYear      string   `json:"fromYear"`
+	ToYear        string   `json:"toYear"`
+	FromAmount    float64  `json:"fromAmount"`
+	ToAmount      float64  `json:"toAmount"`
+	Delta         float64  `json:"delta"`
+	PercentChange *float64 `json:"percentChange"` // nil when FromAmount is zero
+}
+
+// GetYearOverYearChange derives, from the year-over-year comparison, the change in each month's
+// spending from one year to the next. Only consecutive years both having data for the month are
+// paired, so a month missing from a year is not treated as zero spending.
+func (p *Parser) GetYearOverYearChange(startDate, endDate string) ([]YearOverYearChange, error) {
+	comparison, err := p.GetYearOverYearComparisonFiltered(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// The comparison is already in fiscal month order
+	changes := []YearOverYearChange{}
+	for _, data := range comparison {
+		years := make([]string, 0, len(data.Years))
+		for year := range data.Years {
+			years = append(years, year)
+		}
+		sort.Strings(years)
+
+		for _, year := range years {
+			y, err := strconv.Atoi(year)
+			if err != nil {
+				continue
+			}
+			previous := fmt.Sprintf("%04d", y-1)
+			from, ok := data.Years[previous]
+			if !ok {
+				continue
+			}
+			to := data.Years[year]
+
+			change := YearOverYearChange{
+				Month:      data.Month,
+				FromYear:   previous,
+				ToYear:     year,
+				FromAmount: p.roundAmount(from),
+				ToAmount:   p.roundAmount(to),
+				Delta:      p.roundAmount(to - from),
+			}
+			if from != 0 {
+				percent := roundRatio((to - from) / math.Abs(from) * 100)
+				change.PercentChange = &percent
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, nil
+}
+
+// SmoothCategoryTrends returns a copy of trends with each point's SmoothedAmount set to the
+// trailing average of its amount and up to window-1 preceding points in the series. Points
+// near the start average over the points available, and a window of 1 repeats the raw amounts.
+// The input is not modified, so cached series can be passed in.
+func (p *Parser) SmoothCategoryTrends(trends []CategoryTrendData, window int) []CategoryTrendData {
+	if window < 1 {
+		window = 1
+	}
+
+	result := make([]CategoryTrendData, 0, len(trends))
+	for _, trend := range trends {
+		data := make([]MonthAmountPair, len(trend.Data))
+		sum := 0.0
+		for i, point := range trend.Data {
+			sum += point.Amount
+			if i >= window {
+				sum -= trend.Data[i-window].Amount
+			}
+			count := i + 1
+			if count > window {
+				count = window
+			}
+
+			smoothed := p.roundAmount(sum / float64(count))
+			data[i] = MonthAmountPair{
+				Month:          point.Month,
+				Amount:         point.Amount,
+				SmoothedAmount: &smoothed,
+			}
+		}
+		result = append(result, CategoryTrendData{Category: trend.Category, Data: data})
+	}
+
+	return result
+}
+
+// CategoryGrowth describes how fast a category's monthly spending is changing
+type CategoryGrowth struct {
+	Category      string   `json:"category"`
+	Months        int      `json:"months"`
+	MonthlySlope  float64  `json:"monthlySlope"` // fitted change in monthly spend per month
+	OlderAverage  float64  `json:"olderAverage"`
+	RecentAverage float64  `json:"recentAverage"`
+	PercentChange *float64 `json:"percentChange"` // recent vs older average; nil when OlderAverage is zero
+}
+
+// GetCategoryGrowthRates ranks expense categories by the slope of a line fitted to their
+// monthly spending, fastest growing first. Each category's series runs from its first month
+// to the latest complete month, with months without spending counted as zero; the current
+// month is left out because its partial total would read as a drop. Categories with fewer
+// than minMonthsForAverage months (and never fewer than two) are skipped. The series is also
+// split in half to compare the recent average against the older one.
+func (p *Parser) GetCategoryGrowthRates() ([]CategoryGrowth, error) {
+	monthly, err := p.GetMonthlySpending()
+	if err != nil {
+		return nil, err
+	}
+
+	currentMonth := p.currentYearMonth()
+	months := []string{}
+	for month := range monthly {
+		if month < currentMonth {
+			months = append(months, month)
+		}
+	}
+	sort.Strings(months)
+
+	growth := []CategoryGrowth{}
+	if len(months) == 0 {
+		return growth, nil
+	}
+	lastMonth, err := time.Parse("2006-01", months[len(months)-1])
+	if err != nil {
+		return growth, nil
+	}
+
+	firstSeen := make(map[string]string)
+	for _, month := range months {
+		for category := range monthly[month] {
+			if _, ok := firstSeen[category]; !ok {
+				firstSeen[category] = month
+			}
+		}
+	}
+
+	minMonths := p.minMonthsForAverage()
+	if minMonths < 2 {
+		minMonths = 2
+	}
+
+	for category, first := range firstSeen {
+		start, err := time.Parse("2006-01", first)
+		if err != nil {
+			continue
+		}
+
+		var xs, ys []float64
+		for month := start; !month.After(lastMonth); month = month.AddDate(0, 1, 0) {
+			xs = append(xs, float64(len(xs)))
+			ys = append(ys, monthly[month.Format("2006-01")][category])
+		}
+		if len(ys) < minMonths {
+			continue
+		}
+
+		slope, _ := linearFit(xs, ys)
+		split := len(ys) - len(ys)/2
+		older, recent := mean(ys[:split]), mean(ys[split:])
+
+		entry := CategoryGrowth{
+			Category:      category,
+			Months:        len(ys),
+			MonthlySlope:  p.roundAmount(slope),
+			OlderAverage:  p.roundAmount(older),
+			RecentAverage: p.roundAmount(recent),
+		}
+		if older != 0 {
+			percent := roundRatio((recent - older) / math.Abs(older) * 100)
+			entry.PercentChange = &percent
+		}
+		growth = append(growth, entry)
+	}
+
+	sort.Slice(growth, func(i, j int) bool {
+		if growth[i].MonthlySlope != growth[j].MonthlySlope {
+			return growth[i].MonthlySlope > growth[j].MonthlySlope
+		}
+		return growth[i].Category < growth[j].Category
+	})
+
+	return growth, nil
+}
+
+// anomalyMinSamples is the fewest prior months a category needs before its latest month is
+// judged against them
+const anomalyMinSamples = 3
+
+// SpendingAnomaly is a category whose latest complete month is far from its usual spending
+type SpendingAnomaly struct {
+	Category string  `json:"category"`
+	Month    string  `json:"month"`
+	Current  float64 `json:"current"`
+	Average  float64 `json:"average"` // mean of the prior months
+	StdDev   float64 `json:"stdDev"`  // sample standard deviation of the prior months
+	ZScore   float64 `json:"zScore"`
+}
+
+// GetSpendingAnomalies flags the expense categories whose spending in the latest complete month
+// lies more than the anomalyZScore preference (default 2) standard deviations from the mean of
+// their earlier months, largest deviation first. Like GetCategoryGrowthRates, each category's
+// history starts at its first month and counts months without spending as zero. Categories with
+// fewer than anomalyMinSamples (or minMonthsForAverage) prior months, or with perfectly steady
+// spending, are skipped.
+func (p *Parser) GetSpendingAnomalies() ([]SpendingAnomaly, error) {
+	monthly, err := p.GetMonthlySpending()
+	if err != nil {
+		return nil, err
+	}
+
+	currentMonth := p.currentYearMonth()
+	months := []string{}
+	for month := range monthly {
+		if month < currentMonth {
+			months = append(months, month)
+		}
+	}
+	sort.Strings(months)
+
+	anomalies := []SpendingAnomaly{}
+	if len(months) < 2 {
+		return anomalies, nil
+	}
+	target := months[len(months)-1]
+	targetMonth, err := time.Parse("2006-01", target)
+	if err != nil {
+		return anomalies, nil
+	}
+
+	firstSeen := make(map[string]string)
+	for _, month := range months[:len(months)-1] {
+		for category := range monthly[month] {
+			if _, ok := firstSeen[category]; !ok {
+				firstSeen[category] = month
+			}
+		}
+	}
+
+	threshold := p.currentSettings().GetPreferenceFloat("anomalyZScore", 2.0)
+	if threshold <= 0 {
+		threshold = 2.0
+	}
+	minSamples := p.minMonthsForAverage()
+	if minSamples < anomalyMinSamples {
+		minSamples = anomalyMinSamples
+	}
+
+	for category, first := range firstSeen {
+		start, err := time.Parse("2006-01", first)
+		if err != nil {
+			continue
+		}
+
+		var prior []float64
+		for month := start; month.Before(targetMonth); month = month.AddDate(0, 1, 0) {
+			prior = append(prior, monthly[month.Format("2006-01")][category])
+		}
+		if len(prior) < minSamples {
+			continue
+		}
+
+		average := mean(prior)
+		var squares float64
+		for _, amount := range prior {
+			squares += (amount - average) * (amount - average)
+		}
+		stdDev := math.Sqrt(squares / float64(len(prior)-1))
+		if stdDev == 0 {
+			continue
+		}
+
+		current := monthly[target][category]
+		z := (current - average) / stdDev
+		if math.Abs(z) <= threshold {
+			continue
+		}
+
+		anomalies = append(anomalies, SpendingAnomaly{
+			Category: category,
+			Month:    target,
+			Current:  p.roundAmount(current),
+			Average:  p.roundAmount(average),
+			StdDev:   p.roundAmount(stdDev),
+			ZScore:   roundRatio(z),
+		})
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		if a, b := math.Abs(anomalies[i].ZScore), math.Abs(anomalies[j].ZScore); a != b {
+			return a > b
+		}
+		return anomalies[i].Category < anomalies[j].Category
+	})
+
+	return anomalies, nil
+}
@@ -0,0 +1,246 @@
+package hledger
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cwj5/minted/internal/config"
+)
+
+func TestISOWeekKey(t *testing.T) {
+	tests := []struct {
+		date      string
+		weekStart time.Weekday
+		want      string
+	}{
+		{"2024-12-29", time.Monday, "2024-W52"},
+		{"2024-12-30", time.Monday, "2025-W01"}, // ISO year, not the calendar year
+		{"2025-01-01", time.Monday, "2025-W01"},
+		{"2021-01-03", time.Monday, "2020-W53"},
+		{"2021-01-04", time.Monday, "2021-W01"},
+		{"2024-12-29", time.Sunday, "2025-W01"}, // a Sunday starts the next week
+		{"2024-12-28", time.Sunday, "2024-W52"},
+	}
+	for _, tt := range tests {
+		got, ok := isoWeekKey(tt.date, tt.weekStart)
+		if !ok || got != tt.want {
+			t.Errorf("isoWeekKey(%s, %v) = %q, %v; want %q", tt.date, tt.weekStart, got, ok, tt.want)
+		}
+	}
+	if _, ok := isoWeekKey("2024-13-01", time.Monday); ok {
+		t.Error("isoWeekKey accepted a malformed date")
+	}
+}
+
+func TestWeeklyMetricsAcrossYearBoundary(t *testing.T) {
+	p := newTestParser(t, nil,
+		expense("2024-12-27", "expenses:food", 40),
+		expense("2024-12-30", "expenses:food", 25),
+		income("2024-12-31", "income:salary", 1000),
+		expense("2025-01-02", "expenses:food", 35),
+		expense("2025-01-06", "expenses:food", 10),
+	)
+
+	weeks, err := p.GetWeeklyMetrics("2024-12-23", "2025-01-13")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []WeeklyMetrics{
+		{Week: "2024-W52", Expenses: 40},
+		{Week: "2025-W01", Income: 1000, Expenses: 60, SavingsRate: 94},
+		{Week: "2025-W02", Expenses: 10},
+	}
+	if len(weeks) != len(want) {
+		t.Fatalf("weeks %+v, want %+v", weeks, want)
+	}
+	for i := range want {
+		if weeks[i] != want[i] {
+			t.Errorf("week %d = %+v, want %+v", i, weeks[i], want[i])
+		}
+	}
+}
+
+func TestFiscalYear(t *testing.T) {
+	tests := []struct {
+		date       string
+		startMonth int
+		want       string
+	}{
+		{"2024-02-15", 1, "2024"},
+		{"2024-12-31", 1, "2024"},
+		{"2024-02-15", 4, "2023"},
+		{"2024-03-31", 4, "2023"}, // last day before the boundary
+		{"2024-04-01", 4, "2024"}, // first day after it
+		{"2024-04", 4, "2024"},
+		{"2025-03", 4, "2024"},
+		{"2024-09-30", 10, "2023"},
+		{"2024-10-01", 10, "2024"},
+		{"2024", 4, ""},
+		{"2024-13-01", 4, ""},
+	}
+	for _, tt := range tests {
+		if got := fiscalYear(tt.date, tt.startMonth); got != tt.want {
+			t.Errorf("fiscalYear(%q, %d) = %q, want %q", tt.date, tt.startMonth, got, tt.want)
+		}
+	}
+}
+
+func TestFiscalYearStartPreference(t *testing.T) {
+	tests := []struct {
+		value any // nil leaves the default
+		want  int
+	}{
+		{nil, 1},
+		{4, 4},
+		{float64(10), 10}, // as decoded from JSON
+		{0, 1},
+		{13, 1},
+	}
+	for _, tt := range tests {
+		settings := config.DefaultSettings()
+		if tt.value != nil {
+			settings.Preferences["fiscalYearStartMonth"] = tt.value
+		}
+		if got := NewParser("test.journal", settings).fiscalYearStart(); got != tt.want {
+			t.Errorf("fiscalYearStartMonth=%v: fiscalYearStart() = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestYearOverYearByFiscalYear(t *testing.T) {
+	settings := config.DefaultSettings()
+	settings.Preferences["fiscalYearStartMonth"] = 4
+	p := newTestParser(t, settings,
+		expense("2023-04-10", "expenses:food", 100),
+		expense("2024-02-10", "expenses:food", 200),
+		expense("2024-03-10", "expenses:food", 300),
+		expense("2024-04-10", "expenses:food", 400),
+	)
+
+	unfiltered, err := p.GetYearOverYearComparison()
+	if err != nil {
+		t.Fatal(err)
+	}
+	filtered, err := p.GetYearOverYearComparisonFiltered("2023-04-01", "2024-05-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		month string
+		years map[string]float64
+	}{
+		{"04", map[string]float64{"2023": 100, "2024": 400}},
+		{"02", map[string]float64{"2023": 200}},
+		{"03", map[string]float64{"2023": 300}},
+	}
+	for name, got := range map[string][]YearOverYearData{"GetYearOverYearComparison": unfiltered, "GetYearOverYearComparisonFiltered": filtered} {
+		if len(got) != len(want) {
+			t.Errorf("%s = %+v, want %d months starting in April", name, got, len(want))
+			continue
+		}
+		for i, w := range want {
+			if got[i].Month != w.month || !reflect.DeepEqual(got[i].Years, w.years) {
+				t.Errorf("%s[%d] = %+v, want month %s with %v", name, i, got[i], w.month, w.years)
+			}
+		}
+	}
+}
+
+func TestSmoothCategoryTrends(t *testing.T) {
+	series := []float64{100, 200, 300, 400, 500}
+	trend := CategoryTrendData{Category: "food"}
+	for i, amount := range series {
+		trend.Data = append(trend.Data, MonthAmountPair{Month: fmt.Sprintf("2024-%02d", i+1), Amount: amount})
+	}
+	tests := []struct {
+		window int
+		want   []float64
+	}{
+		{1, series},
+		{0, series}, // treated as 1
+		{2, []float64{100, 150, 250, 350, 450}},
+		{3, []float64{100, 150, 200, 300, 400}},
+		{10, []float64{100, 150, 200, 250, 300}},
+	}
+	p := NewParser("test.journal", config.DefaultSettings())
+	for _, tt := range tests {
+		smoothed := p.SmoothCategoryTrends([]CategoryTrendData{trend}, tt.window)
+		if len(smoothed) != 1 || len(smoothed[0].Data) != len(series) {
+			t.Fatalf("window %d: %+v", tt.window, smoothed)
+		}
+		for i, point := range smoothed[0].Data {
+			if point.Amount != series[i] {
+				t.Errorf("window %d: point %d raw amount %v, want %v", tt.window, i, point.Amount, series[i])
+			}
+			if point.SmoothedAmount == nil || *point.SmoothedAmount != tt.want[i] {
+				t.Errorf("window %d: point %d smoothed %v, want %v", tt.window, i, point.SmoothedAmount, tt.want[i])
+			}
+		}
+	}
+	for _, point := range trend.Data {
+		if point.SmoothedAmount != nil {
+			t.Fatal("SmoothCategoryTrends modified its input")
+		}
+	}
+}
+
+func TestCategoryBreakdownGrouped(t *testing.T) {
+	p := newTestParser(t, nil,
+		expense("2024-05-01", "expenses:rent", 1200),
+		expense("2024-05-02", "expenses:food", 600),
+		expense("2024-05-03", "expenses:coffee", 100),
+		expense("2024-05-04", "expenses:books", 60),
+		expense("2024-05-05", "expenses:gifts", 40),
+		income("2024-05-25", "income:salary", 3900),
+		income("2024-05-26", "income:interest", 60),
+		income("2024-05-27", "income:dividends", 40),
+	)
+	type slice struct {
+		category        string
+		amount, percent float64
+	}
+
+	tests := []struct {
+		name       string
+		kind       string
+		minPercent float64
+		want       []slice
+	}{
+		{"no grouping", BreakdownExpenses, 0, []slice{
+			{"rent", 1200, 60}, {"food", 600, 30}, {"coffee", 100, 5}, {"books", 60, 3}, {"gifts", 40, 2},
+		}},
+		{"tail below 10%", BreakdownExpenses, 10, []slice{
+			{"rent", 1200, 60}, {"food", 600, 30}, {OtherCategory, 200, 10},
+		}},
+		{"two slices below 4%", BreakdownExpenses, 4, []slice{
+			{"rent", 1200, 60}, {"food", 600, 30}, {"coffee", 100, 5}, {OtherCategory, 100, 5},
+		}},
+		{"a lone small slice keeps its name", BreakdownExpenses, 2.5, []slice{
+			{"rent", 1200, 60}, {"food", 600, 30}, {"coffee", 100, 5}, {"books", 60, 3}, {"gifts", 40, 2},
+		}},
+		{"income tail", BreakdownIncome, 5, []slice{
+			{"salary", 3900, 97.5}, {OtherCategory, 100, 2.5},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares, err := p.GetCategoryBreakdownGrouped(tt.kind, "2024-05-01", "2024-06-01", tt.minPercent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []slice{}
+			for _, share := range shares {
+				got = append(got, slice{share.Category, share.Amount, share.Percent})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("breakdown %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := p.GetCategoryBreakdownGrouped("assets", "", "", 0); err == nil {
+		t.Error("GetCategoryBreakdownGrouped accepted an unknown breakdown")
+	}
+}
@@ -0,0 +1,47 @@
+// Package logging provides the leveled, structured logger shared by the parser and the dashboard
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// level is the minimum level of loggers created by New, set from the logLevel preference
+var level = new(slog.LevelVar)
+
+// defaultLogger writes to stderr and is used wherever no other logger was injected
+var defaultLogger = New(os.Stderr)
+
+// New returns a logger writing key=value lines to w, filtered by the level set with SetLevel
+func New(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// Default returns the stderr logger
+func Default() *slog.Logger {
+	return defaultLogger
+}
+
+// ParseLevel converts a logLevel preference value (debug, info, warn or error, in any case) to
+// a slog level. An empty value means info.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unknown log level %q (use debug, info, warn or error)", name)
+}
+
+// SetLevel sets the minimum level of every logger created by New, including the default one
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
@@ -290,6 +290,14 @@ function setRefreshStatus(message) {
     }
 }
 
+// Cached endpoints flag outdated data with an X-Cache-Stale header rather than a body field, since
+// most of them return bare arrays; point the user at the Refresh button while it is set
+function noteCacheStaleness(response) {
+    if (response.headers.get('X-Cache-Stale') === 'true') {
+        setRefreshStatus('Showing cached data; refresh for the latest');
+    }
+}
+
 // Manually refresh cached data and reload dashboard
 async function refreshDashboardData() {
     const btn = document.getElementById('refreshButton');
@@ -342,6 +350,7 @@ async function loadSummary() {
         const filterParams = getFilterQueryString();
         const url = filterParams ? `/api/summary?${filterParams}` : '/api/summary';
         const response = await fetch(url);
+        noteCacheStaleness(response);
         const data = await response.json();
 
         document.getElementById('netWorth').textContent = formatCurrency(data.netWorth || 0);
@@ -358,6 +367,7 @@ async function loadAccounts() {
         const filterParams = getFilterQueryString();
         const url = filterParams ? `/api/accounts?${filterParams}` : '/api/accounts';
         const response = await fetch(url);
+        noteCacheStaleness(response);
         const accounts = await response.json();
 
         const container = document.getElementById('accounts');
@@ -429,6 +439,7 @@ async function loadBudgetHistory() {
         const filterParams = getFilterQueryString();
         const url = filterParams ? `/api/budget/history?${filterParams}` : '/api/budget/history';
         const response = await fetch(url);
+        noteCacheStaleness(response);
         const items = await response.json();
 
         const container = document.getElementById('budget-charts');
@@ -637,6 +648,7 @@ async function loadIncomeCategories() {
         const filterParams = getFilterQueryString();
         const url = filterParams ? `/api/income-breakdown?${filterParams}` : '/api/income-breakdown';
         const response = await fetch(url);
+        noteCacheStaleness(response);
         const income = await response.json();
 
         const container = document.getElementById('income-categories');
@@ -705,6 +717,7 @@ async function loadTransactions() {
         const filterParams = getFilterQueryString();
         const url = filterParams ? `/api/transactions?${filterParams}` : '/api/transactions';
         const response = await fetch(url);
+        noteCacheStaleness(response);
         allTransactions = await response.json();
         currentPage = 1;
         renderTransactionsPage();
@@ -885,6 +898,7 @@ async function loadSavingsRateChart() {
         const filterParams = getFilterQueryString();
         const url = filterParams ? `/api/monthly-metrics?${filterParams}` : '/api/monthly-metrics';
         const response = await fetch(url);
+        noteCacheStaleness(response);
         const metrics = await response.json();
 
         if (!metrics || metrics.length === 0) {
@@ -1053,6 +1067,7 @@ async function loadIncomeExpensesChart() {
         const filterParams = getFilterQueryString();
         const url = filterParams ? `/api/monthly-metrics?${filterParams}` : '/api/monthly-metrics';
         const response = await fetch(url);
+        noteCacheStaleness(response);
         const metrics = await response.json();
 
         if (!metrics || metrics.length === 0) {
@@ -1140,6 +1155,7 @@ async function loadIncomeExpensesChart() {
                             // Fetch income breakdown to find main income category
                             try {
                                 const response = await fetch('/api/income-breakdown');
+                                noteCacheStaleness(response);
                                 const breakdown = await response.json();
                                 if (breakdown && breakdown.length > 0) {
                                     // Navigate to the first/largest income category
@@ -1164,6 +1180,7 @@ async function loadCategorySpendingChart() {
         const filterParams = getFilterQueryString();
         const url = filterParams ? `/api/category-spending?${filterParams}` : '/api/category-spending';
         const response = await fetch(url);
+        noteCacheStaleness(response);
         const spending = await response.json();
 
         if (!spending || spending.length === 0) {
@@ -1392,6 +1409,7 @@ async function loadIncomeBreakdownChart() {
         const filterParams = getFilterQueryString();
         const url = filterParams ? `/api/income-breakdown?${filterParams}` : '/api/income-breakdown';
         const response = await fetch(url);
+        noteCacheStaleness(response);
         const income = await response.json();
 
         if (!income || income.length === 0) {
@@ -1459,6 +1477,7 @@ async function loadNetWorthChart() {
         const filterParams = getFilterQueryString();
         const url = filterParams ? `/api/net-worth-over-time?${filterParams}` : '/api/net-worth-over-time';
         const response = await fetch(url);
+        noteCacheStaleness(response);
         const data = await response.json();
 
         if (!data || data.length === 0) {
@@ -1530,6 +1549,7 @@ async function loadCategoryTrendsChart() {
         const filterParams = getFilterQueryString();
         const url = filterParams ? `/api/category-trends?${filterParams}` : '/api/category-trends';
         const response = await fetch(url);
+        noteCacheStaleness(response);
         const data = await response.json();
 
         if (!data || data.length === 0) {
@@ -1617,6 +1637,7 @@ async function loadYearOverYearChart() {
         const filterParams = getFilterQueryString();
         const url = filterParams ? `/api/year-over-year-comparison?${filterParams}` : '/api/year-over-year-comparison';
         const response = await fetch(url);
+        noteCacheStaleness(response);
         const data = await response.json();
 
         if (!data || data.length === 0) {
@@ -1911,6 +1932,7 @@ async function loadCategoryDetail(category) {
         const separator = filterParams ? '&' : '';
         const url = `/api/detail/category?category=${encodeURIComponent(category)}${separator}${filterParams}`;
         const response = await fetch(url);
+        noteCacheStaleness(response);
         const data = await response.json();
 
         // Update page title and breadcrumb
@@ -1944,6 +1966,7 @@ async function loadTierDetail(tier) {
         const separator = filterParams ? '&' : '';
         const url = `/api/detail/tier?tier=${encodeURIComponent(tier)}${separator}${filterParams}`;
         const response = await fetch(url);
+        noteCacheStaleness(response);
         const data = await response.json();
 
         // Update page title and breadcrumb
@@ -1977,6 +2000,7 @@ async function loadAccountDetail(account) {
         const separator = filterParams ? '&' : '';
         const url = `/api/detail/account?account=${encodeURIComponent(account)}${separator}${filterParams}`;
         const response = await fetch(url);
+        noteCacheStaleness(response);
         const data = await response.json();
 
         // Clean up account name for display
@@ -2020,6 +2044,7 @@ async function loadIncomeDetail(incomeName) {
         const separator = filterParams ? '&' : '';
         const url = `/api/detail/income?income=${encodeURIComponent(incomeName)}${separator}${filterParams}`;
         const response = await fetch(url);
+        noteCacheStaleness(response);
         const data = await response.json();
 
         // Update page title and breadcrumb
@@ -2057,6 +2082,7 @@ async function loadAllIncomeDetail() {
         const filterParams = getFilterQueryString();
         const url = filterParams ? `/api/income-breakdown?${filterParams}` : '/api/income-breakdown';
         const response = await fetch(url);
+        noteCacheStaleness(response);
         const breakdown = await response.json();
 
         // Update page title and breadcrumb
@@ -2069,6 +2095,7 @@ async function loadAllIncomeDetail() {
         const txFilterParams = getFilterQueryString();
         const txUrl = txFilterParams ? `/api/transactions?${txFilterParams}` : '/api/transactions';
         const txResponse = await fetch(txUrl);
+        noteCacheStaleness(txResponse);
         const allTransactions = await txResponse.json();
 
         // Filter for income transactions
@@ -2110,6 +2137,7 @@ async function loadIncomeHistoryCharts() {
         const filterParams = getFilterQueryString();
         const url = filterParams ? `/api/income-history?${filterParams}` : '/api/income-history';
         const response = await fetch(url);
+        noteCacheStaleness(response);
         const items = await response.json();
 
         const container = document.getElementById('income-history-charts');